@@ -7,8 +7,8 @@ import (
 
 // Admin represents a Player with elevated privileges.
 type Admin struct {
-	Player
-	Role string
+	Player `yaml:",inline"`
+	Role   string `yaml:"role" json:"role"`
 }
 
 var unknownAdmin = Admin{
@@ -23,7 +23,7 @@ var unknownAdmin = Admin{
 func (c *Conn) Admins() ([]Admin, error) {
 	result, err := c.send("get", "adminids")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get information for all admins: %v", err)
+		return nil, fmt.Errorf("failed to get information for all admins: %w", err)
 	}
 
 	args := strings.Split(result, "\t")
@@ -44,7 +44,7 @@ func (c *Conn) Admins() ([]Admin, error) {
 func (c *Conn) AdminAdd(a Admin) error {
 	_, err := c.send("adminadd", q(a.ID64), q(a.Role), q(a.Name))
 	if err != nil {
-		return fmt.Errorf("failed to add admin %s: %v", a.String(), err)
+		return fmt.Errorf("failed to add admin %s: %w", a.String(), err)
 	}
 
 	return nil
@@ -54,7 +54,7 @@ func (c *Conn) AdminAdd(a Admin) error {
 func (c *Conn) AdminRemove(a Admin) error {
 	_, err := c.send("admindel", a.ID64)
 	if err != nil {
-		return fmt.Errorf("failed to remove admin %s: %v", a.String(), err)
+		return fmt.Errorf("failed to remove admin %s: %w", a.String(), err)
 	}
 
 	return nil
@@ -64,7 +64,7 @@ func (c *Conn) AdminRemove(a Admin) error {
 func (c *Conn) AdminGroups() ([]string, error) {
 	result, err := c.send("get", "admingroups")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get information for all admin groups: %v", err)
+		return nil, fmt.Errorf("failed to get information for all admin groups: %w", err)
 	}
 
 	args := strings.Split(result, "\t")
@@ -0,0 +1,229 @@
+// Package audit records every moderation and configuration command sent by a Conn.
+//
+// An Auditor is installed as Conn middleware, so commands are recorded whichever part of a program
+// sends them. Each Record holds the acting operator, the action, its target and arguments, and
+// whether the server accepted it. Operators send commands through the handle returned by
+// rcon.Conn.As, e.g. as rcon.Scoped does, so commands sharing a Conn are recorded in the name of
+// whoever sent them. Records are written to a Sink, a JSONL File by default, which can be queried
+// by operator, target, action and time range.
+package audit
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Results of a Record.
+const (
+	ResultOK     = "ok"
+	ResultFailed = "failed"
+)
+
+// Record represents a single audited command.
+type Record struct {
+	Time     time.Time         `json:"time"`
+	Operator string            `json:"operator"`
+	Action   string            `json:"action"`
+	Target   string            `json:"target,omitempty"`
+	Args     map[string]string `json:"args,omitempty"`
+	Result   string            `json:"result"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// Query represents a filter over Records. Empty fields match every Record.
+type Query struct {
+	Operator string
+	Target   string
+	Action   string
+	Since    time.Time // Inclusive.
+	Until    time.Time // Exclusive.
+	Limit    int       // Maximum number of Records, keeping the newest.
+}
+
+// Sink represents the destination of Records.
+type Sink interface {
+	Write(r Record) error
+}
+
+// Querier represents a Sink that can be searched.
+type Querier interface {
+	Query(q Query) ([]Record, error)
+}
+
+// Auditor represents the middleware recording the commands of a Conn.
+type Auditor struct {
+	Sink Sink
+
+	// Operator is recorded as the acting operator of commands sent without one, e.g. through the Conn
+	// itself rather than a handle returned by rcon.Conn.As, unless the command names its own admin,
+	// as bans do.
+	Operator string
+
+	// OnError is called when a Record cannot be written. Commands are never blocked by the Sink.
+	OnError func(error)
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// action describes how to record a command: its action name, and the names of its arguments.
+// The first argument named "target" becomes the Target of the Record.
+type action struct {
+	name string
+	args []string
+}
+
+// actions maps every mutating command to its action.
+var actions = map[string]action{
+	"kick":                    {"kick", []string{"target", "reason"}},
+	"punish":                  {"punish", []string{"target", "reason"}},
+	"tempban":                 {"ban_temporary", []string{"target", "hours", "reason", "admin"}},
+	"permaban":                {"ban_permanent", []string{"target", "reason", "admin"}},
+	"pardontempban":           {"ban_remove_temporary", []string{"target"}},
+	"pardonpermaban":          {"ban_remove_permanent", []string{"target"}},
+	"adminadd":                {"admin_add", []string{"target", "role", "name"}},
+	"admindel":                {"admin_remove", []string{"target"}},
+	"vipadd":                  {"vip_add", []string{"target", "name"}},
+	"vipdel":                  {"vip_remove", []string{"target"}},
+	"message":                 {"message", []string{"target", "message"}},
+	"switchteamnow":           {"switch_team", []string{"target"}},
+	"switchteamondeath":       {"switch_team_on_death", []string{"target"}},
+	"map":                     {"map_set", []string{"target"}},
+	"rotadd":                  {"rotation_add", []string{"target"}},
+	"rotdel":                  {"rotation_remove", []string{"target"}},
+	"broadcast":               {"broadcast", []string{"message"}},
+	"banprofanity":            {"profanity_add", []string{"words"}},
+	"unbanprofanity":          {"profanity_remove", []string{"words"}},
+	"setkickidletime":         {"setting", []string{"idle_time"}},
+	"sethighping":             {"setting", []string{"max_ping"}},
+	"setautobalanceenabled":   {"setting", []string{"auto_balance"}},
+	"setautobalancethreshold": {"setting", []string{"auto_balance_threshold"}},
+	"setteamswitchcooldown":   {"setting", []string{"switch_team_cooldown"}},
+	"setmaxqueuedplayers":     {"setting", []string{"queue_length"}},
+	"setnumvipslots":          {"setting", []string{"vip_slots"}},
+	"setvotekickenabled":      {"setting", []string{"vote_kick"}},
+	"setvotekickthreshold":    {"setting", []string{"vote_kick_threshold"}},
+	"resetvotekickthreshold":  {"setting", []string{"vote_kick_threshold"}},
+}
+
+// New returns an Auditor writing to a Sink, recording commands in the name of operator.
+func New(s Sink, operator string) *Auditor {
+	return &Auditor{
+		Sink:     s,
+		Operator: operator,
+		now:      time.Now,
+	}
+}
+
+// Wrap is the rcon.Middleware of the Auditor, e.g. conn.Use(a.Wrap).
+func (a *Auditor) Wrap(next rcon.Handler) rcon.Handler {
+	return func(req rcon.Request) (string, error) {
+		result, err := next(req)
+
+		r, ok := a.record(req, err)
+		if ok {
+			a.write(r)
+		}
+
+		return result, err
+	}
+}
+
+// Query will search the Sink, if it is a Querier.
+func (a *Auditor) Query(q Query) ([]Record, error) {
+	querier, ok := a.Sink.(Querier)
+	if !ok {
+		return nil, errUnsupported
+	}
+
+	return querier.Query(q)
+}
+
+// record will describe a command as a Record, reporting false for commands that change nothing.
+func (a *Auditor) record(req rcon.Request, err error) (Record, bool) {
+	act, ok := actions[rcon.CommandName(req.Cmds)]
+	if !ok {
+		return Record{}, false
+	}
+
+	r := Record{
+		Time:     a.now().UTC(),
+		Operator: a.Operator,
+		Action:   act.name,
+		Args:     map[string]string{},
+		Result:   ResultOK,
+	}
+
+	values := rcon.Arguments(req.Cmds)
+
+	for i, name := range act.args {
+		if i >= len(values) {
+			break
+		}
+
+		switch {
+		case name == "target":
+			r.Target = values[i]
+		case name == "admin":
+			if values[i] != "" {
+				r.Operator = values[i]
+			}
+		case act.name == "setting":
+			r.Target = name
+			r.Args["value"] = values[i]
+		default:
+			r.Args[name] = values[i]
+		}
+	}
+
+	if req.Operator != "" {
+		r.Operator = req.Operator
+	}
+
+	if act.name == "setting" && r.Target == "" {
+		r.Target = act.args[0]
+		r.Args["value"] = "default"
+	}
+
+	if len(r.Args) == 0 {
+		r.Args = nil
+	}
+
+	if err != nil {
+		r.Result = ResultFailed
+		r.Error = err.Error()
+	}
+
+	return r, true
+}
+
+func (a *Auditor) write(r Record) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	err := a.Sink.Write(r)
+	if err != nil && a.OnError != nil {
+		a.OnError(err)
+	}
+}
+
+// Match reports whether a Record passes the Query, ignoring Limit.
+func (q Query) Match(r Record) bool {
+	switch {
+	case q.Operator != "" && !strings.EqualFold(q.Operator, r.Operator):
+		return false
+	case q.Target != "" && !strings.EqualFold(q.Target, r.Target):
+		return false
+	case q.Action != "" && q.Action != r.Action:
+		return false
+	case !q.Since.IsZero() && r.Time.Before(q.Since):
+		return false
+	case !q.Until.IsZero() && !r.Time.Before(q.Until):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,100 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var errUnsupported = errors.New("audit sink does not support queries")
+
+// File represents a Sink appending Records to a file as JSON lines.
+type File struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+}
+
+// OpenFile will open a JSONL file for appending, creating it when missing.
+func OpenFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", path, err)
+	}
+
+	return &File{path: path, f: f}, nil
+}
+
+// Write will append a Record as a single line.
+func (f *File) Write(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, err = f.f.Write(append(b, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+
+	return nil
+}
+
+// Query will scan the file for Records matching q, oldest first.
+func (f *File) Query(q Query) ([]Record, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r, err := os.Open(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %w", f.path, err)
+	}
+	defer r.Close()
+
+	records := []Record{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		rec := Record{}
+
+		err := json.Unmarshal(scanner.Bytes(), &rec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode audit log %s line %d: %w", f.path, line, err)
+		}
+
+		if q.Match(rec) {
+			records = append(records, rec)
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", f.path, err)
+	}
+
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[len(records)-q.Limit:]
+	}
+
+	return records, nil
+}
+
+// Close will close the file.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.f.Close()
+}
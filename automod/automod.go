@@ -0,0 +1,346 @@
+// Package automod moderates team killing automatically with escalating actions.
+//
+// An Automod consumes team kill Events from a LogStream subscription and counts each player's team
+// kills against a list of Rules, e.g. three team kills in ten minutes. When rules match, the most
+// severe of them is applied: the offender is messaged, then punished, kicked or temporarily
+// banned. Every action is written to an audit Sink.
+package automod
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/audit"
+	"github.com/verocity-gaming/rcon/internal/consume"
+	"github.com/verocity-gaming/rcon/internal/humanize"
+)
+
+// Action represents the consequence of a Rule, in increasing order of severity.
+type Action string
+
+// Actions.
+const (
+	ActionWarn   Action = "warn"   // Message the offender.
+	ActionPunish Action = "punish" // Kill the offender.
+	ActionKick   Action = "kick"
+	ActionBan    Action = "ban" // Ban the offender for the BanHours of the Rule.
+)
+
+// DefaultMessages are the messages sent to offenders before each Action, supporting {name},
+// {victim}, {weapon}, {count}, {window} and {ban}.
+var DefaultMessages = map[Action]string{
+	ActionWarn:   "You team killed {victim}, {name}. Check your targets, team killing is not allowed.",
+	ActionPunish: "You have been punished for team killing {count} times in {window}.",
+	ActionKick:   "You are being kicked for team killing {count} times in {window}.",
+	ActionBan:    "You are being banned for {ban} for team killing {count} times in {window}.",
+}
+
+// DefaultReason is the reason given to punishes, kicks and bans, supporting the placeholders of
+// DefaultMessages.
+const DefaultReason = "Team killing ({count} in {window})"
+
+// DefaultRules warn on a first team kill, then punish, kick and ban repeat offenders. Team kills
+// in the first minute of a match, when spawns are crowded, only count towards a ban.
+var DefaultRules = []Rule{
+	{Name: "warn", Count: 1, Window: 10 * time.Minute, Grace: time.Minute, Action: ActionWarn},
+	{Name: "punish", Count: 2, Window: 10 * time.Minute, Grace: time.Minute, Action: ActionPunish},
+	{Name: "kick", Count: 3, Window: 10 * time.Minute, Grace: time.Minute, Action: ActionKick},
+	{Name: "vehicle", Count: 2, Window: 10 * time.Minute, Classes: []WeaponClass{WeaponVehicle}, Action: ActionKick},
+	{Name: "ban", Count: 5, Window: time.Hour, Action: ActionBan, BanHours: 2},
+}
+
+// Rule represents a number of team kills within a window and the Action it leads to.
+type Rule struct {
+	Name string
+
+	// Count is the number of team kills within Window that match the Rule.
+	Count  int
+	Window time.Duration
+
+	// Classes are the weapon classes counted by the Rule, or every class when empty.
+	Classes []WeaponClass
+
+	// Grace is the time from the start of a match during which team kills are not counted.
+	Grace time.Duration
+
+	Action   Action
+	BanHours int
+
+	// Message and Reason are templates for the message sent to the offender and the reason given to
+	// the server, DefaultMessages and DefaultReason when empty.
+	Message string
+	Reason  string
+}
+
+// Result represents an Action applied to an offender.
+type Result struct {
+	Player rcon.Player
+	Rule   Rule
+	Count  int        // Team kills matching the Rule.
+	Event  rcon.Event // The team kill that matched the Rule.
+	Err    error
+}
+
+// Automod represents the team kill moderation of a single server.
+type Automod struct {
+	Conn  *rcon.Conn
+	Rules []Rule
+
+	// Audit, when set, records every Action. Leave it unset when the Conn already has an
+	// audit.Auditor, or Actions are recorded twice.
+	Audit audit.Sink
+
+	// Operator is the name commands are sent in, recorded as the admin of bans and the operator of
+	// audit Records.
+	Operator string
+
+	// OnAction is called after every Action.
+	OnAction func(Result)
+
+	// OnError is called when a Record cannot be written to the Audit sink.
+	OnError func(error)
+
+	mu      sync.Mutex
+	history map[string][]teamkill // Recent team kills keyed by the offender's ID64.
+	match   time.Time             // Start of the current match, if known.
+	now     func() time.Time
+}
+
+type teamkill struct {
+	time  time.Time
+	class WeaponClass
+	match time.Time // Start of the match the team kill happened in, if known.
+}
+
+// New returns an Automod for a Conn with a copy of the DefaultRules.
+func New(c *rcon.Conn) *Automod {
+	return &Automod{
+		Conn:     c,
+		Rules:    append([]Rule{}, DefaultRules...),
+		Operator: "automod",
+		history:  map[string][]teamkill{},
+		now:      time.Now,
+	}
+}
+
+// Consume will record the Events of a channel, e.g. a LogStream subscription, until it is closed
+// or the context is cancelled.
+func (a *Automod) Consume(ctx context.Context, events <-chan rcon.Event) error {
+	return consume.Events(ctx, events, a.Record)
+}
+
+// Record will count a team kill Event and apply the most severe Rule it matches. Match start
+// Events begin the Grace of the Rules, and other Events are ignored.
+func (a *Automod) Record(e rcon.Event) {
+	if e.Time.IsZero() {
+		e.Time = a.now()
+	}
+
+	switch e.Kind {
+	case rcon.EventMatchStart:
+		a.mu.Lock()
+		a.match = e.Time
+		a.mu.Unlock()
+	case rcon.EventTeamKill:
+		if e.Player.ID64 == "" || e.Player.ID64 == e.Victim.ID64 {
+			return
+		}
+
+		rule, count, ok := a.count(e)
+		if !ok {
+			return
+		}
+
+		r := Result{Player: e.Player, Rule: rule, Count: count, Event: e}
+		r.Err = a.apply(r)
+
+		if a.OnAction != nil {
+			a.OnAction(r)
+		}
+	}
+}
+
+// count will add a team kill to the offender's history, returning the most severe Rule matched.
+func (a *Automod) count(e rcon.Event) (Rule, int, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := e.Player.ID64
+
+	tk := teamkill{time: e.Time, class: Classify(e.Weapon), match: a.match}
+	history := append(a.prune(a.history[id], e.Time), tk)
+	a.history[id] = history
+
+	best, count, found := Rule{}, 0, false
+
+	for _, rule := range a.Rules {
+		n := 0
+
+		for _, tk := range history {
+			if rule.counts(tk, e.Time) {
+				n++
+			}
+		}
+
+		if rule.Count < 1 || n < rule.Count {
+			continue
+		}
+
+		if !found || !best.outranks(rule) {
+			best, count, found = rule, n, true
+		}
+	}
+
+	return best, count, found
+}
+
+// prune returns the team kills still within the longest Window of the Rules.
+func (a *Automod) prune(history []teamkill, now time.Time) []teamkill {
+	longest := time.Duration(0)
+	for _, rule := range a.Rules {
+		if rule.Window > longest {
+			longest = rule.Window
+		}
+	}
+
+	kept := []teamkill{}
+	for _, tk := range history {
+		if now.Sub(tk.time) < longest {
+			kept = append(kept, tk)
+		}
+	}
+
+	return kept
+}
+
+// apply will message the offender and carry out the Action of a Result.
+func (a *Automod) apply(r Result) error {
+	message := r.Rule.Message
+	if message == "" {
+		message = DefaultMessages[r.Rule.Action]
+	}
+
+	reason := r.Rule.Reason
+	if reason == "" {
+		reason = DefaultReason
+	}
+
+	replacer := r.replacer()
+	message, reason = replacer.Replace(message), replacer.Replace(reason)
+
+	// Commands are sent in the name of the Operator, so an audit.Auditor of the Conn records it.
+	c := a.Conn.As(a.Operator)
+
+	// The message is sent first, as kicked and banned players cannot receive it afterwards.
+	err := c.Message(r.Player, message)
+
+	args := map[string]string{
+		"rule":      r.Rule.Name,
+		"teamkills": strconv.Itoa(r.Count),
+		"victim":    r.Event.Victim.ID64,
+		"weapon":    r.Event.Weapon,
+	}
+
+	action := "message"
+
+	switch r.Rule.Action {
+	case ActionWarn:
+		args["message"] = message
+	case ActionPunish:
+		action, args["reason"] = "punish", reason
+		err = c.Punish(r.Player, reason)
+	case ActionKick:
+		action, args["reason"] = "kick", reason
+		err = c.Kick(r.Player, reason)
+	case ActionBan:
+		if r.Rule.BanHours < 1 {
+			err = fmt.Errorf("rule %s bans without BanHours", r.Rule.Name)
+			break
+		}
+
+		action, args["reason"], args["hours"] = "ban_temporary", reason, strconv.Itoa(r.Rule.BanHours)
+		err = c.BanTemporarily(r.Player, r.Rule.BanHours, reason, a.Operator)
+	default:
+		err = fmt.Errorf("unknown action %q of rule %s", r.Rule.Action, r.Rule.Name)
+	}
+
+	a.audit(action, r.Player, args, err)
+
+	return err
+}
+
+// audit will write a Record of an Action to the Audit sink, if any.
+func (a *Automod) audit(action string, p rcon.Player, args map[string]string, err error) {
+	if a.Audit == nil {
+		return
+	}
+
+	rec := audit.Record{
+		Time:     a.now().UTC(),
+		Operator: a.Operator,
+		Action:   action,
+		Target:   p.ID64,
+		Args:     args,
+		Result:   audit.ResultOK,
+	}
+
+	if err != nil {
+		rec.Result, rec.Error = audit.ResultFailed, err.Error()
+	}
+
+	werr := a.Audit.Write(rec)
+	if werr != nil && a.OnError != nil {
+		a.OnError(fmt.Errorf("failed to audit %s of %s: %w", action, p, werr))
+	}
+}
+
+// counts reports whether a team kill counts towards the Rule at a time.
+func (rule Rule) counts(tk teamkill, now time.Time) bool {
+	if now.Sub(tk.time) >= rule.Window {
+		return false
+	}
+
+	if rule.Grace > 0 && !tk.match.IsZero() && tk.time.Sub(tk.match) < rule.Grace {
+		return false
+	}
+
+	if len(rule.Classes) == 0 {
+		return true
+	}
+
+	for _, c := range rule.Classes {
+		if c == tk.class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// severity orders Actions.
+var severity = map[Action]int{ActionWarn: 0, ActionPunish: 1, ActionKick: 2, ActionBan: 3}
+
+// outranks reports whether the Rule has a more severe Action than another, or a longer ban.
+func (rule Rule) outranks(other Rule) bool {
+	if severity[rule.Action] != severity[other.Action] {
+		return severity[rule.Action] > severity[other.Action]
+	}
+
+	return rule.BanHours > other.BanHours
+}
+
+func (r Result) replacer() *strings.Replacer {
+	return strings.NewReplacer(
+		"{name}", r.Player.Name,
+		"{victim}", r.Event.Victim.Name,
+		"{weapon}", r.Event.Weapon,
+		"{count}", strconv.Itoa(r.Count),
+		"{window}", humanize.Duration(r.Rule.Window),
+		"{ban}", humanize.Plural(r.Rule.BanHours, "hour"),
+	)
+}
@@ -0,0 +1,196 @@
+package automod
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+)
+
+var (
+	bob = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+	eve = rcon.Player{Name: "Eve", ID64: "76561198000000002"}
+)
+
+// event represents a log Event, relative to the first.
+type event struct {
+	at     time.Duration
+	kind   rcon.EventKind // rcon.EventTeamKill by default.
+	player rcon.Player    // bob by default.
+	weapon string
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		events   []event
+		want     []string // Names of the Rules applied.
+		wantSent []string
+	}{
+		{
+			name: "escalated",
+			events: []event{
+				{at: 0},
+				{at: time.Minute},
+				{at: 2 * time.Minute},
+				{at: 3 * time.Minute},
+				{at: 4 * time.Minute},
+			},
+			want: []string{"warn", "punish", "kick", "kick", "ban"},
+			wantSent: []string{
+				"message",
+				"message", "punish",
+				"message", "kick",
+				"message", "kick",
+				"message", "tempban",
+			},
+		},
+		{
+			name: "decayed",
+			events: []event{
+				{at: 0},
+				{at: 9 * time.Minute},
+				{at: 18 * time.Minute},
+				{at: 30 * time.Minute},
+			},
+			want:     []string{"warn", "punish", "punish", "warn"},
+			wantSent: []string{"message", "message", "punish", "message", "punish", "message"},
+		},
+		{
+			name: "banned within the hour",
+			events: []event{
+				{at: 0},
+				{at: 15 * time.Minute},
+				{at: 30 * time.Minute},
+				{at: 45 * time.Minute},
+				{at: 59 * time.Minute},
+			},
+			want:     []string{"warn", "warn", "warn", "warn", "ban"},
+			wantSent: []string{"message", "message", "message", "message", "message", "tempban"},
+		},
+		{
+			name: "ban decayed",
+			events: []event{
+				{at: 0},
+				{at: 15 * time.Minute},
+				{at: 30 * time.Minute},
+				{at: 45 * time.Minute},
+				{at: 60 * time.Minute},
+			},
+			want:     []string{"warn", "warn", "warn", "warn", "warn"},
+			wantSent: []string{"message", "message", "message", "message", "message"},
+		},
+		{
+			name: "grace",
+			events: []event{
+				{at: 0, kind: rcon.EventMatchStart},
+				{at: 10 * time.Second},
+				{at: 20 * time.Second},
+				{at: 2 * time.Minute},
+			},
+			want:     []string{"warn"},
+			wantSent: []string{"message"},
+		},
+		{
+			name: "vehicle",
+			events: []event{
+				{at: 0, weapon: "75MM CANNON [Sherman M4A3(75)W]"},
+				{at: time.Minute, weapon: "75MM CANNON [Sherman M4A3(75)W]"},
+			},
+			want:     []string{"warn", "vehicle"},
+			wantSent: []string{"message", "message", "kick"},
+		},
+		{
+			name: "per player",
+			events: []event{
+				{at: 0},
+				{at: time.Minute, player: eve},
+				{at: 2 * time.Minute},
+			},
+			want:     []string{"warn", "warn", "punish"},
+			wantSent: []string{"message", "message", "message", "punish"},
+		},
+		{
+			name: "ignored",
+			events: []event{
+				{at: 0, player: rcon.Player{Name: "Unknown"}},
+				{at: time.Minute, kind: rcon.EventKill},
+			},
+			want:     []string{},
+			wantSent: []string{},
+		},
+	}
+
+	start := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := rcontest.NewServer(t)
+
+			a := New(srv.Conn(t))
+			a.now = func() time.Time { return start }
+
+			applied := []string{}
+			a.OnAction = func(r Result) {
+				if r.Err != nil {
+					t.Errorf("rule %s failed: %v", r.Rule.Name, r.Err)
+				}
+
+				applied = append(applied, r.Rule.Name)
+			}
+
+			for _, e := range tt.events {
+				if e.kind == "" {
+					e.kind = rcon.EventTeamKill
+				}
+
+				if e.player == (rcon.Player{}) {
+					e.player = bob
+				}
+
+				a.Record(rcon.Event{
+					Kind:   e.kind,
+					Time:   start.Add(e.at),
+					Player: e.player,
+					Victim: rcon.Player{Name: "Victim", ID64: "76561198000000009"},
+					Weapon: e.weapon,
+				})
+			}
+
+			if strings.Join(applied, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("applied %q, want %q", applied, tt.want)
+			}
+
+			sent := []string{}
+			for _, cmd := range srv.Sent() {
+				sent = append(sent, rcon.CommandName([]string{cmd}))
+			}
+
+			if strings.Join(sent, " ") != strings.Join(tt.wantSent, " ") {
+				t.Errorf("sent %q, want %q", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		weapon string
+		want   WeaponClass
+	}{
+		{weapon: "M1 GARAND", want: WeaponSmallArms},
+		{weapon: "75MM CANNON [Sherman M4A3(75)W]", want: WeaponVehicle},
+		{weapon: "COAXIAL MG34 [Panzer IV]", want: WeaponVehicle},
+		{weapon: "155MM HOWITZER [M114]", want: WeaponArtillery},
+		{weapon: "M2 AP MINE", want: WeaponExplosive},
+		{weapon: "bazooka", want: WeaponExplosive},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.weapon); got != tt.want {
+			t.Errorf("Classify(%q) = %s, want %s", tt.weapon, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package automod
+
+import "strings"
+
+// WeaponClass represents a category of weapons, so rules can treat a team kill from a tank
+// differently from one with a rifle.
+type WeaponClass string
+
+// Weapon classes.
+const (
+	WeaponSmallArms WeaponClass = "small_arms" // Rifles, machine guns, pistols and melee.
+	WeaponVehicle   WeaponClass = "vehicle"    // Cannons and machine guns mounted on tanks and other vehicles.
+	WeaponArtillery WeaponClass = "artillery"  // Howitzers.
+	WeaponExplosive WeaponClass = "explosive"  // Mines, grenades, satchels and rocket launchers.
+)
+
+// explosives are the fragments of explosive weapon names in the server log.
+var explosives = []string{"MINE", "GRENADE", "SATCHEL", "BAZOOKA", "PANZERSCHRECK", "PIAT", "RPG"}
+
+// Classify returns the WeaponClass of a weapon as named in the server log. Mounted weapons are
+// named after their vehicle in brackets, e.g. "75MM CANNON [Sherman M4A3(75)W]".
+func Classify(weapon string) WeaponClass {
+	w := strings.ToUpper(weapon)
+
+	switch {
+	case strings.Contains(w, "HOWITZER"):
+		return WeaponArtillery
+	case strings.Contains(w, "["):
+		return WeaponVehicle
+	}
+
+	for _, e := range explosives {
+		if strings.Contains(w, e) {
+			return WeaponExplosive
+		}
+	}
+
+	return WeaponSmallArms
+}
@@ -0,0 +1,407 @@
+// Package bansync propagates bans and pardons between the servers of a Fleet.
+//
+// Each round, the bans of every reachable server are compared with a local Ledger. Bans missing
+// from a server are applied with their remaining duration, and bans that disappear from a server
+// before expiring are treated as pardons and removed everywhere. Pardoned bans stay in the Ledger,
+// so a server that still lists one will have it removed rather than re-synced to the others.
+//
+// Ban times are reported by the server without a timezone and are assumed to be UTC.
+package bansync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Action kinds used in a Report.
+const (
+	ActionBan    = "ban"
+	ActionPardon = "pardon"
+)
+
+// expirySlack is the time before expiry at which a missing temporary ban is assumed expired, and
+// is no longer applied to other servers.
+const expirySlack = 5 * time.Minute
+
+// Action represents a ban or pardon applied to a single server.
+type Action struct {
+	Server string
+	Kind   string // One of ActionBan or ActionPardon.
+	Entry  Entry
+	Err    error
+}
+
+// Report represents the outcome of a single synchronization round.
+type Report struct {
+	Actions     []Action
+	Unreachable map[string]error // Servers whose bans could not be listed.
+}
+
+// Syncer represents a ban synchronization engine over a tagged subset of a Fleet.
+type Syncer struct {
+	Fleet  *rcon.Fleet
+	Tag    string // Servers to synchronize, or every server when empty.
+	Ledger *Ledger
+
+	// OnReport is called after every round started by Run.
+	OnReport func(Report, error)
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// New returns a Syncer for the servers of f with a tag, recording bans in l.
+func New(f *rcon.Fleet, tag string, l *Ledger) *Syncer {
+	return &Syncer{
+		Fleet:  f,
+		Tag:    tag,
+		Ledger: l,
+		now:    time.Now,
+	}
+}
+
+// Run will synchronize bans every interval until ctx is done.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		r, err := s.Sync()
+		if s.OnReport != nil {
+			s.OnReport(r, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Sync will run a single synchronization round and save the Ledger.
+func (s *Syncer) Sync() (Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	observed, r := s.fetch()
+
+	s.Ledger.mu.Lock()
+	plan := s.plan(observed, s.now())
+	s.Ledger.mu.Unlock()
+
+	r.Actions = s.apply(plan)
+
+	err := s.Ledger.Save()
+	if err != nil {
+		return r, err
+	}
+
+	for _, a := range r.Actions {
+		if a.Err != nil {
+			return r, fmt.Errorf("failed to %s %s on %s: %w", a.Kind, a.Entry.Player, a.Server, a.Err)
+		}
+	}
+
+	return r, nil
+}
+
+// Pardon will mark every ban of a player as pardoned and remove them from all servers. Servers
+// answering that the player is not banned there are left out of the Report.
+func (s *Syncer) Pardon(p rcon.Player) (Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	s.Ledger.mu.Lock()
+	for _, permanent := range []bool{true, false} {
+		e, ok := s.Ledger.Entries[key(p.ID64, permanent)]
+		if !ok || e.Pardoned() {
+			continue
+		}
+
+		e.PardonedAt = now
+		e.PardonedOn = "ledger"
+	}
+	s.Ledger.mu.Unlock()
+
+	r := Report{Unreachable: map[string]error{}}
+
+	results := s.Fleet.Each(s.Tag, func(name string, c *rcon.Conn) error {
+		return c.BanRemove(p)
+	})
+
+	for _, result := range results {
+		if errors.Is(result.Err, rcon.ErrResultFailed) {
+			continue
+		}
+
+		r.Actions = append(r.Actions, Action{
+			Server: result.Server,
+			Kind:   ActionPardon,
+			Entry:  Entry{Player: p},
+			Err:    result.Err,
+		})
+	}
+
+	err := s.Ledger.Save()
+	if err != nil {
+		return r, err
+	}
+
+	for _, a := range r.Actions {
+		if a.Err != nil {
+			return r, fmt.Errorf("failed to %s %s on %s: %w", a.Kind, p, a.Server, a.Err)
+		}
+	}
+
+	return r, nil
+}
+
+// observation represents the bans listed by every reachable server, keyed by ledger key.
+type observation struct {
+	servers map[string]bool
+	bans    map[string]map[string]rcon.Ban
+}
+
+func (s *Syncer) fetch() (observation, Report) {
+	o := observation{
+		servers: map[string]bool{},
+		bans:    map[string]map[string]rcon.Ban{},
+	}
+
+	r := Report{Unreachable: map[string]error{}}
+
+	var mu sync.Mutex
+
+	results := s.Fleet.Each(s.Tag, func(name string, c *rcon.Conn) error {
+		perm, err := c.BannedPermanently()
+		if err != nil {
+			return err
+		}
+
+		temp, err := c.BannedTemporarily()
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		o.servers[name] = true
+
+		for permanent, bans := range map[bool][]rcon.Ban{true: perm, false: temp} {
+			for _, b := range bans {
+				k := key(b.Player.ID64, permanent)
+
+				if o.bans[k] == nil {
+					o.bans[k] = map[string]rcon.Ban{}
+				}
+
+				o.bans[k][name] = b
+			}
+		}
+
+		return nil
+	})
+
+	for _, result := range results {
+		if result.Err != nil {
+			r.Unreachable[result.Server] = result.Err
+		}
+	}
+
+	return o, r
+}
+
+// plan will update the Ledger with the observed bans and return the actions required per server.
+// The Ledger must be locked.
+func (s *Syncer) plan(o observation, now time.Time) map[string][]*Action {
+	entries := s.Ledger.Entries
+
+	// Bans that disappeared from a server before expiring were pardoned there.
+	for k, e := range entries {
+		if e.Pardoned() {
+			continue
+		}
+
+		for server := range e.Servers {
+			if !o.servers[server] {
+				continue
+			}
+
+			if _, ok := o.bans[k][server]; ok {
+				continue
+			}
+
+			delete(e.Servers, server)
+
+			// Allow for clock drift between the server and the ledger near expiry.
+			if !e.Expired(now.Add(expirySlack)) {
+				e.PardonedAt = now
+				e.PardonedOn = server
+				break
+			}
+		}
+	}
+
+	for k, bans := range o.bans {
+		e, ok := entries[k]
+
+		switch {
+		case !ok:
+			e = entryFromBans(k, bans)
+			entries[k] = e
+		case e.Pardoned():
+			// A pardoned ban listed by a server it was never seen on has been issued again.
+			fresh := false
+			for server := range bans {
+				if !e.Servers[server] {
+					fresh = true
+				}
+			}
+
+			if fresh {
+				e = entryFromBans(k, bans)
+				entries[k] = e
+			}
+		}
+
+		for server := range bans {
+			if e.Pardoned() {
+				continue
+			}
+
+			e.Servers[server] = true
+		}
+	}
+
+	plan := map[string][]*Action{}
+
+	for k, e := range entries {
+		bans := o.bans[k]
+
+		if e.Pardoned() {
+			for server := range e.Servers {
+				if !o.servers[server] {
+					continue
+				}
+
+				if _, ok := bans[server]; !ok {
+					delete(e.Servers, server)
+					continue
+				}
+
+				plan[server] = append(plan[server], &Action{Server: server, Kind: ActionPardon, Entry: e.clone()})
+			}
+
+			continue
+		}
+
+		if len(bans) == 0 {
+			if e.Expired(now) || len(e.Servers) == 0 {
+				delete(entries, k)
+			}
+
+			continue
+		}
+
+		// Bans are applied in whole hours, so one about to expire would outlast its original.
+		if !e.Permanent && e.Remaining(now) < expirySlack {
+			continue
+		}
+
+		for server := range o.servers {
+			if _, ok := bans[server]; ok {
+				continue
+			}
+
+			plan[server] = append(plan[server], &Action{Server: server, Kind: ActionBan, Entry: e.clone()})
+		}
+	}
+
+	return plan
+}
+
+func (s *Syncer) apply(plan map[string][]*Action) []Action {
+	now := s.now()
+
+	s.Fleet.Each(s.Tag, func(name string, c *rcon.Conn) error {
+		for _, a := range plan[name] {
+			p := a.Entry.Player
+
+			switch {
+			case a.Kind == ActionPardon:
+				a.Err = c.BanRemove(p)
+			case a.Entry.Permanent:
+				a.Err = c.BanPermanently(p, a.Entry.Reason, a.Entry.Admin)
+			default:
+				hours := int(math.Ceil(a.Entry.Remaining(now).Hours()))
+				a.Err = c.BanTemporarily(p, hours, a.Entry.Reason, a.Entry.Admin)
+			}
+		}
+
+		return nil
+	})
+
+	s.Ledger.mu.Lock()
+	defer s.Ledger.mu.Unlock()
+
+	actions := []Action{}
+
+	for _, list := range plan {
+		for _, a := range list {
+			actions = append(actions, *a)
+
+			if a.Err != nil {
+				continue
+			}
+
+			e, ok := s.Ledger.Entries[key(a.Entry.Player.ID64, a.Entry.Permanent)]
+			if !ok {
+				continue
+			}
+
+			if a.Kind == ActionPardon {
+				delete(e.Servers, a.Server)
+			} else {
+				e.Servers[a.Server] = true
+			}
+		}
+	}
+
+	return actions
+}
+
+// entryFromBans will create an Entry from the earliest issued of a set of bans.
+func entryFromBans(k string, bans map[string]rcon.Ban) *Entry {
+	var first rcon.Ban
+
+	for _, b := range bans {
+		if first.Time.IsZero() || b.Time.Before(first.Time) {
+			first = b
+		}
+	}
+
+	e := &Entry{
+		Player:    first.Player,
+		Permanent: k == key(first.Player.ID64, true),
+		Reason:    first.Reason,
+		Admin:     first.Admin.Name,
+		Issued:    first.Time,
+		Servers:   map[string]bool{},
+	}
+
+	if !e.Permanent {
+		e.Expires = first.Time.Add(first.Duration)
+	}
+
+	return e
+}
@@ -0,0 +1,273 @@
+package bansync
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+)
+
+func TestPlan(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	bob := rcon.Player{Name: "Bob", ID64: "76561"}
+	eve := rcon.Player{Name: "Eve", ID64: "76562"}
+
+	perm := rcon.Ban{Player: bob, Reason: "cheating", Time: now.Add(-time.Hour)}
+	temp := func(d time.Duration, issued time.Time) rcon.Ban {
+		return rcon.Ban{Player: eve, Reason: "teamkilling", Duration: d, Time: issued}
+	}
+
+	entry := func(b rcon.Ban, permanent bool, servers ...string) *Entry {
+		k := key(b.Player.ID64, permanent)
+		e := entryFromBans(k, map[string]rcon.Ban{"": b})
+
+		for _, server := range servers {
+			e.Servers[server] = true
+		}
+
+		return e
+	}
+
+	tests := []struct {
+		name    string
+		entries map[string]*Entry
+		servers []string                       // Reachable servers.
+		bans    map[string]map[string]rcon.Ban // Listed bans by key and server.
+		want    []string                       // Planned actions as "server kind key".
+		check   func(t *testing.T, entries map[string]*Entry)
+	}{
+		{
+			name:    "new ban",
+			entries: map[string]*Entry{},
+			servers: []string{"a", "b", "c"},
+			bans:    map[string]map[string]rcon.Ban{"perm:76561": {"a": perm}},
+			want:    []string{"b ban perm:76561", "c ban perm:76561"},
+			check: func(t *testing.T, entries map[string]*Entry) {
+				e := entries["perm:76561"]
+				if e == nil || !e.Permanent || e.Reason != "cheating" || !e.Servers["a"] {
+					t.Errorf("entry = %+v, want a permanent ban seen on a", e)
+				}
+			},
+		},
+		{
+			name:    "synchronized",
+			entries: map[string]*Entry{"perm:76561": entry(perm, true, "a", "b")},
+			servers: []string{"a", "b"},
+			bans:    map[string]map[string]rcon.Ban{"perm:76561": {"a": perm, "b": perm}},
+			want:    []string{},
+		},
+		{
+			name:    "unreachable server",
+			entries: map[string]*Entry{"perm:76561": entry(perm, true, "a", "b")},
+			servers: []string{"a"},
+			bans:    map[string]map[string]rcon.Ban{"perm:76561": {"a": perm}},
+			want:    []string{},
+			check: func(t *testing.T, entries map[string]*Entry) {
+				if e := entries["perm:76561"]; e.Pardoned() || !e.Servers["b"] {
+					t.Errorf("entry = %+v, want unchanged", e)
+				}
+			},
+		},
+		{
+			name:    "pardoned on a server",
+			entries: map[string]*Entry{"perm:76561": entry(perm, true, "a", "b", "c")},
+			servers: []string{"a", "b", "c"},
+			bans:    map[string]map[string]rcon.Ban{"perm:76561": {"b": perm, "c": perm}},
+			want:    []string{"b pardon perm:76561", "c pardon perm:76561"},
+			check: func(t *testing.T, entries map[string]*Entry) {
+				if e := entries["perm:76561"]; e.PardonedOn != "a" || !e.PardonedAt.Equal(now) {
+					t.Errorf("entry = %+v, want pardoned on a", e)
+				}
+			},
+		},
+		{
+			name: "pardoned everywhere",
+			entries: map[string]*Entry{"perm:76561": func() *Entry {
+				e := entry(perm, true, "a")
+				e.PardonedAt = now.Add(-time.Hour)
+				e.PardonedOn = "b"
+				return e
+			}()},
+			servers: []string{"a", "b"},
+			bans:    map[string]map[string]rcon.Ban{},
+			want:    []string{},
+			check: func(t *testing.T, entries map[string]*Entry) {
+				if e := entries["perm:76561"]; len(e.Servers) != 0 || !e.Pardoned() {
+					t.Errorf("entry = %+v, want pardoned without servers", e)
+				}
+			},
+		},
+		{
+			name: "banned again after pardon",
+			entries: map[string]*Entry{"perm:76561": func() *Entry {
+				e := entry(perm, true)
+				e.PardonedAt = now.Add(-time.Hour)
+				e.PardonedOn = "a"
+				return e
+			}()},
+			servers: []string{"a", "b"},
+			bans:    map[string]map[string]rcon.Ban{"perm:76561": {"a": perm}},
+			want:    []string{"b ban perm:76561"},
+			check: func(t *testing.T, entries map[string]*Entry) {
+				if e := entries["perm:76561"]; e.Pardoned() {
+					t.Errorf("entry = %+v, want a new ban", e)
+				}
+			},
+		},
+		{
+			name:    "temporary ban",
+			entries: map[string]*Entry{},
+			servers: []string{"a", "b"},
+			bans:    map[string]map[string]rcon.Ban{"temp:76562": {"a": temp(2*time.Hour, now.Add(-time.Hour))}},
+			want:    []string{"b ban temp:76562"},
+			check: func(t *testing.T, entries map[string]*Entry) {
+				if e := entries["temp:76562"]; e.Remaining(now) != time.Hour {
+					t.Errorf("entry = %+v, want an hour remaining", e)
+				}
+			},
+		},
+		{
+			name:    "temporary ban near expiry",
+			entries: map[string]*Entry{"temp:76562": entry(temp(2*time.Hour, now.Add(-2*time.Hour+2*time.Minute)), false, "a", "b")},
+			servers: []string{"a", "b"},
+			// The server clock of a is ahead, and lifted the ban early.
+			bans: map[string]map[string]rcon.Ban{"temp:76562": {"b": temp(2*time.Hour, now.Add(-2*time.Hour+2*time.Minute))}},
+			want: []string{},
+			check: func(t *testing.T, entries map[string]*Entry) {
+				if e := entries["temp:76562"]; e.Pardoned() || e.Servers["a"] {
+					t.Errorf("entry = %+v, want expired on a", e)
+				}
+			},
+		},
+		{
+			name:    "expired",
+			entries: map[string]*Entry{"temp:76562": entry(temp(time.Hour, now.Add(-2*time.Hour)), false, "a", "b")},
+			servers: []string{"a", "b"},
+			bans:    map[string]map[string]rcon.Ban{},
+			want:    []string{},
+			check: func(t *testing.T, entries map[string]*Entry) {
+				if _, ok := entries["temp:76562"]; ok {
+					t.Error("expired entry kept in the ledger")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Syncer{Ledger: &Ledger{Entries: tt.entries}}
+
+			o := observation{servers: map[string]bool{}, bans: tt.bans}
+			for _, server := range tt.servers {
+				o.servers[server] = true
+			}
+
+			got := []string{}
+			for server, actions := range s.plan(o, now) {
+				for _, a := range actions {
+					got = append(got, server+" "+a.Kind+" "+key(a.Entry.Player.ID64, a.Entry.Permanent))
+				}
+			}
+
+			sort.Strings(got)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("plan() = %q, want %q", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("plan() = %q, want %q", got, tt.want)
+					break
+				}
+			}
+
+			if tt.check != nil {
+				tt.check(t, tt.entries)
+			}
+		})
+	}
+}
+
+func TestEntryFromBans(t *testing.T) {
+	issued := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	bans := map[string]rcon.Ban{
+		"a": {Player: rcon.Player{ID64: "76562"}, Reason: "second", Duration: 2 * time.Hour, Time: issued.Add(time.Minute)},
+		"b": {Player: rcon.Player{ID64: "76562"}, Reason: "first", Duration: 2 * time.Hour, Time: issued},
+	}
+
+	e := entryFromBans("temp:76562", bans)
+
+	if e.Permanent || e.Reason != "first" || !e.Issued.Equal(issued) || !e.Expires.Equal(issued.Add(2*time.Hour)) {
+		t.Errorf("entryFromBans() = %+v, want the first ban issued", e)
+	}
+
+	if len(e.Servers) != 0 {
+		t.Errorf("entryFromBans().Servers = %v, want none", e.Servers)
+	}
+}
+
+func TestPardon(t *testing.T) {
+	errDown := errors.New("server down")
+
+	f := rcon.NewFleet()
+
+	for _, name := range []string{"a", "b", "c"} {
+		srv := rcontest.NewServer(t)
+		c := srv.Conn(t)
+
+		switch name {
+		case "b":
+			c.Use(func(next rcon.Handler) rcon.Handler {
+				return func(r rcon.Request) (string, error) {
+					return "", errDown
+				}
+			})
+		case "c":
+			srv.Reply("pardontempban", "FAIL")
+			srv.Reply("pardonpermaban", "FAIL")
+		}
+
+		err := f.Add(name, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := OpenLedger("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+	l.Entries[key(p.ID64, true)] = &Entry{Player: p, Permanent: true}
+
+	s := New(f, "", l)
+	s.now = func() time.Time { return time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC) }
+
+	r, err := s.Pardon(p)
+	if !errors.Is(err, errDown) {
+		t.Errorf("Pardon() = %v, want the error of b wrapped", err)
+	}
+
+	servers := []string{}
+	for _, a := range r.Actions {
+		servers = append(servers, a.Server)
+	}
+
+	sort.Strings(servers)
+
+	if strings.Join(servers, " ") != "a b" {
+		t.Errorf("actions on %q, want a and b", servers)
+	}
+
+	if e, _ := l.Entry(p.ID64, true); !e.Pardoned() {
+		t.Errorf("entry = %+v, want pardoned", e)
+	}
+}
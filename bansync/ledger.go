@@ -0,0 +1,120 @@
+package bansync
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/jsonfile"
+)
+
+// Entry represents a ban tracked across servers.
+type Entry struct {
+	Player    rcon.Player `json:"player"`
+	Permanent bool        `json:"permanent"`
+	Reason    string      `json:"reason"`
+	Admin     string      `json:"admin"`
+	Issued    time.Time   `json:"issued"`
+	Expires   time.Time   `json:"expires,omitempty"` // Zero for permanent bans.
+
+	Servers map[string]bool `json:"servers"` // Servers the ban has been observed on.
+
+	PardonedAt time.Time `json:"pardoned_at,omitempty"`
+	PardonedOn string    `json:"pardoned_on,omitempty"`
+}
+
+// Ledger represents the local record of every synchronized ban, persisted as JSON.
+type Ledger struct {
+	mu      sync.Mutex
+	path    string
+	Entries map[string]*Entry `json:"entries"`
+}
+
+// OpenLedger will load a Ledger from path, creating an empty one if the file does not exist.
+func OpenLedger(path string) (*Ledger, error) {
+	l := &Ledger{
+		path:    path,
+		Entries: map[string]*Entry{},
+	}
+
+	err := jsonfile.Load(path, l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ban ledger: %w", err)
+	}
+
+	if l.Entries == nil {
+		l.Entries = map[string]*Entry{}
+	}
+
+	return l, nil
+}
+
+// Save will write the Ledger to disk, replacing the previous file atomically.
+func (l *Ledger) Save() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.path == "" {
+		return nil
+	}
+
+	err := jsonfile.Save(l.path, l)
+	if err != nil {
+		return fmt.Errorf("failed to save ban ledger: %w", err)
+	}
+
+	return nil
+}
+
+// Entry returns a copy of the entry for a player's permanent or temporary ban.
+func (l *Ledger) Entry(id64 string, permanent bool) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.Entries[key(id64, permanent)]
+	if !ok {
+		return Entry{}, false
+	}
+
+	return e.clone(), true
+}
+
+// clone returns a copy of the entry that does not share its Servers with the Ledger.
+func (e Entry) clone() Entry {
+	servers := map[string]bool{}
+	for server, ok := range e.Servers {
+		servers[server] = ok
+	}
+
+	e.Servers = servers
+
+	return e
+}
+
+// Pardoned reports whether the entry has been deliberately pardoned.
+func (e Entry) Pardoned() bool {
+	return !e.PardonedAt.IsZero()
+}
+
+// Expired reports whether a temporary ban has run its course.
+func (e Entry) Expired(now time.Time) bool {
+	return !e.Permanent && !now.Before(e.Expires)
+}
+
+// Remaining returns the time left on a temporary ban.
+func (e Entry) Remaining(now time.Time) time.Duration {
+	if e.Permanent {
+		return 0
+	}
+
+	return e.Expires.Sub(now)
+}
+
+func key(id64 string, permanent bool) string {
+	if permanent {
+		return "perm:" + id64
+	}
+
+	return "temp:" + id64
+}
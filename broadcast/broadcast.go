@@ -0,0 +1,218 @@
+// Package broadcast cycles the server's broadcast message through lists of templates.
+//
+// A Rotator sets the next message of the first Set matching the time of day and the population at
+// every interval, filling in live data such as the current map, the score and the top killer of
+// the match. The default broadcast is restored when the Rotator stops.
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/consume"
+	"github.com/verocity-gaming/rcon/internal/humanize"
+	"github.com/verocity-gaming/rcon/internal/poll"
+)
+
+// Set represents a list of messages shown in turn while its conditions hold. Messages support
+// {map}, {next_map}, {players}, {allies}, {axis}, {allied_score}, {axis_score}, {remaining},
+// {top_killer} and {top_kills}.
+type Set struct {
+	Name     string
+	Messages []string
+
+	// Start and End are the times of day, from midnight, between which the Set is shown, wrapping
+	// past midnight when End is before Start. The Set is shown all day when they are equal.
+	Start time.Duration
+	End   time.Duration
+
+	// MinPlayers and MaxPlayers are the population, inclusive, within which the Set is shown. No
+	// maximum applies when MaxPlayers is zero.
+	MinPlayers int
+	MaxPlayers int
+}
+
+// Report represents the outcome of a single Next.
+type Report struct {
+	Set     string // The Set shown, or empty for the default broadcast.
+	Message string
+	Players int
+}
+
+// Rotator represents the broadcast rotation of a single server.
+type Rotator struct {
+	Conn *rcon.Conn
+	Sets []Set
+
+	// Default is the broadcast shown when no Set matches, and restored when Run stops. An empty
+	// Default clears the broadcast.
+	Default string
+
+	// Location is the time zone of the times of day of the Sets, time.Local when nil.
+	Location *time.Location
+
+	// Operator is the name the broadcast is set in, "broadcast" by default.
+	Operator string
+
+	// OnReport is called after every Next started by Run.
+	OnReport func(Report, error)
+
+	mu    sync.Mutex
+	index map[string]int // Next message of each Set, keyed by name.
+	kills map[string]int // Kills in the current match, keyed by ID64.
+	names map[string]string
+	now   func() time.Time
+}
+
+// New returns a Rotator for a Conn cycling through sets.
+func New(c *rcon.Conn, sets ...Set) *Rotator {
+	return &Rotator{
+		Conn:     c,
+		Sets:     sets,
+		Operator: "broadcast",
+		index:    map[string]int{},
+		kills:    map[string]int{},
+		names:    map[string]string{},
+		now:      time.Now,
+	}
+}
+
+// Run will show the next message every interval until ctx is done, then restore the Default
+// broadcast.
+func (r *Rotator) Run(ctx context.Context, interval time.Duration) error {
+	defer r.restore()
+
+	return poll.Every(ctx, interval, func() {
+		rep, err := r.Next()
+		if r.OnReport != nil {
+			r.OnReport(rep, err)
+		}
+	})
+}
+
+// restore will set the Default broadcast, reporting a failure to OnReport.
+func (r *Rotator) restore() {
+	err := r.Conn.As(r.Operator).SetBroadcast(r.Default)
+	if err != nil && r.OnReport != nil {
+		r.OnReport(Report{Message: r.Default}, fmt.Errorf("failed to restore broadcast: %w", err))
+	}
+}
+
+// Consume will record the Events of a channel, e.g. a LogStream subscription, until it is closed
+// or the context is cancelled.
+func (r *Rotator) Consume(ctx context.Context, events <-chan rcon.Event) error {
+	return consume.Events(ctx, events, r.Record)
+}
+
+// Record will count the kills of a log Event towards the top killer, starting over with every
+// match. Other kinds of Event are ignored.
+func (r *Rotator) Record(e rcon.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch e.Kind {
+	case rcon.EventMatchStart:
+		r.kills = map[string]int{}
+		r.names = map[string]string{}
+	case rcon.EventKill:
+		if e.Player.ID64 == "" {
+			return
+		}
+
+		r.kills[e.Player.ID64]++
+		r.names[e.Player.ID64] = e.Player.Name
+	}
+}
+
+// Next will set the broadcast to the next message of the first Set matching the current time and
+// population, or to the Default when none does.
+func (r *Rotator) Next() (Report, error) {
+	state, err := r.Conn.GameState()
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to rotate broadcast: %w", err)
+	}
+
+	r.mu.Lock()
+
+	players := state.AlliedPlayers + state.AxisPlayers
+	rep := Report{Message: r.Default, Players: players}
+
+	for _, s := range r.Sets {
+		if len(s.Messages) == 0 || !s.matches(r.clock(), players) {
+			continue
+		}
+
+		i := r.index[s.Name] % len(s.Messages)
+		r.index[s.Name] = i + 1
+
+		rep.Set, rep.Message = s.Name, r.render(s.Messages[i], state)
+
+		break
+	}
+
+	r.mu.Unlock()
+
+	err = r.Conn.As(r.Operator).SetBroadcast(rep.Message)
+	if err != nil {
+		return rep, fmt.Errorf("failed to rotate broadcast: %w", err)
+	}
+
+	return rep, nil
+}
+
+// clock returns the time of day in the Location.
+func (r *Rotator) clock() time.Duration {
+	loc := r.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	now := r.now().In(loc)
+
+	return time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute + time.Duration(now.Second())*time.Second
+}
+
+// render will fill in the placeholders of a message.
+func (r *Rotator) render(message string, state rcon.GameState) string {
+	top, kills := "nobody", 0
+
+	for id, n := range r.kills {
+		if n > kills || n == kills && r.names[id] < top {
+			top, kills = r.names[id], n
+		}
+	}
+
+	return strings.NewReplacer(
+		"{map}", humanize.Map(state.Map),
+		"{next_map}", humanize.Map(state.NextMap),
+		"{players}", strconv.Itoa(state.AlliedPlayers+state.AxisPlayers),
+		"{allies}", strconv.Itoa(state.AlliedPlayers),
+		"{axis}", strconv.Itoa(state.AxisPlayers),
+		"{allied_score}", strconv.Itoa(state.AlliedScore),
+		"{axis_score}", strconv.Itoa(state.AxisScore),
+		"{remaining}", humanize.Duration(state.Remaining),
+		"{top_killer}", top,
+		"{top_kills}", strconv.Itoa(kills),
+	).Replace(message)
+}
+
+// matches reports whether the Set is shown at a time of day with a population.
+func (s Set) matches(clock time.Duration, players int) bool {
+	if players < s.MinPlayers || s.MaxPlayers > 0 && players > s.MaxPlayers {
+		return false
+	}
+
+	switch {
+	case s.Start == s.End:
+		return true
+	case s.Start < s.End:
+		return clock >= s.Start && clock < s.End
+	default:
+		return clock >= s.Start || clock < s.End
+	}
+}
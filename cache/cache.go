@@ -0,0 +1,217 @@
+// Package cache keeps the responses of read commands for a short time, so dashboards and bots can
+// poll a Conn without each poll reaching the server.
+//
+// A Cache is installed as Conn middleware. Concurrent identical reads that miss are coalesced into
+// a single command, and commands that change the server invalidate the reads they affect, so a
+// VIPAdd is immediately visible to the next VIPs.
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// DefaultTTLs are the lifetimes of cached responses, keyed by rcon.CommandName. Commands missing
+// from the map are never cached.
+var DefaultTTLs = map[string]time.Duration{
+	"get playerids":       2 * time.Second,
+	"get slots":           2 * time.Second,
+	"get gamestate":       2 * time.Second,
+	"get map":             5 * time.Second,
+	"get tempbans":        10 * time.Second,
+	"get permabans":       10 * time.Second,
+	"get adminids":        30 * time.Second,
+	"get vipids":          30 * time.Second,
+	"get profanity":       30 * time.Second,
+	"rotlist":             30 * time.Second,
+	"get name":            time.Minute,
+	"get admingroups":     5 * time.Minute,
+	"get mapsforrotation": 5 * time.Minute,
+}
+
+// invalidates maps commands that change the server to the reads they make stale.
+var invalidates = map[string][]string{
+	"kick":              {"get playerids", "get slots", "get gamestate"},
+	"tempban":           {"get tempbans", "get playerids", "get slots", "get gamestate"},
+	"permaban":          {"get permabans", "get playerids", "get slots", "get gamestate"},
+	"pardontempban":     {"get tempbans"},
+	"pardonpermaban":    {"get permabans"},
+	"switchteamnow":     {"get gamestate"},
+	"switchteamondeath": {"get gamestate"},
+	"adminadd":          {"get adminids", "get tempbans", "get permabans"},
+	"admindel":          {"get adminids", "get tempbans", "get permabans"},
+	"vipadd":            {"get vipids"},
+	"vipdel":            {"get vipids"},
+	"map":               {"get map", "get gamestate"},
+	"rotadd":            {"rotlist"},
+	"rotdel":            {"rotlist"},
+	"banprofanity":      {"get profanity"},
+	"unbanprofanity":    {"get profanity"},
+}
+
+// Cache represents the cached responses of a Conn.
+type Cache struct {
+	mu      sync.Mutex
+	ttls    map[string]time.Duration
+	entries map[string]entry
+	flights map[string]*flight
+	gen     map[string]uint64 // Incremented per command name on every invalidation.
+	stats   Stats
+	now     func() time.Time
+}
+
+// Stats represents the effectiveness of a Cache.
+type Stats struct {
+	Hits      uint64 // Reads answered from the cache.
+	Misses    uint64 // Reads sent to the server.
+	Coalesced uint64 // Reads that waited for an identical read in flight.
+}
+
+type entry struct {
+	result  string
+	expires time.Time
+}
+
+// flight represents a read in progress, shared by every identical read that misses meanwhile.
+type flight struct {
+	done   chan struct{}
+	result string
+	err    error
+}
+
+// New returns a Cache with lifetimes keyed by rcon.CommandName, DefaultTTLs when nil.
+func New(ttls map[string]time.Duration) *Cache {
+	if ttls == nil {
+		ttls = DefaultTTLs
+	}
+
+	return &Cache{
+		ttls:    ttls,
+		entries: map[string]entry{},
+		flights: map[string]*flight{},
+		gen:     map[string]uint64{},
+		now:     time.Now,
+	}
+}
+
+// Wrap is the rcon.Middleware of the Cache, e.g. conn.Use(c.Wrap).
+func (c *Cache) Wrap(next rcon.Handler) rcon.Handler {
+	return func(r rcon.Request) (string, error) {
+		name := rcon.CommandName(r.Cmds)
+
+		if stale, ok := invalidates[name]; ok {
+			result, err := next(r)
+
+			// Invalidated even on failure, as the server may have applied the change regardless.
+			c.Invalidate(stale...)
+
+			return result, err
+		}
+
+		ttl, ok := c.ttls[name]
+		if !ok || ttl <= 0 {
+			return next(r)
+		}
+
+		return c.read(name, strings.Join(r.Cmds, " "), ttl, func() (string, error) {
+			return next(r)
+		})
+	}
+}
+
+// read will answer a read from the cache, join an identical read in flight, or send it.
+func (c *Cache) read(name, key string, ttl time.Duration, send func() (string, error)) (string, error) {
+	c.mu.Lock()
+
+	if e, ok := c.entries[key]; ok && c.now().Before(e.expires) {
+		c.stats.Hits++
+		c.mu.Unlock()
+
+		return e.result, nil
+	}
+
+	if f, ok := c.flights[key]; ok {
+		c.stats.Coalesced++
+		c.mu.Unlock()
+
+		<-f.done
+
+		return f.result, f.err
+	}
+
+	f := &flight{done: make(chan struct{})}
+	c.flights[key] = f
+	c.stats.Misses++
+
+	gen := c.gen[name]
+	c.mu.Unlock()
+
+	f.result, f.err = send()
+
+	c.mu.Lock()
+
+	// An Invalidate may have replaced the flight with a newer read already.
+	if c.flights[key] == f {
+		delete(c.flights, key)
+	}
+
+	// A response read while a mutation was in progress may already be stale.
+	if f.err == nil && c.gen[name] == gen {
+		c.entries[key] = entry{result: f.result, expires: c.now().Add(ttl)}
+	}
+
+	c.mu.Unlock()
+
+	close(f.done)
+
+	return f.result, f.err
+}
+
+// Invalidate will drop the cached responses of commands, keyed by rcon.CommandName, so the next
+// reads reach the server rather than joining a read in flight. Every response is dropped when no
+// names are given.
+func (c *Cache) Invalidate(names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(names) == 0 {
+		for name := range c.ttls {
+			c.gen[name]++
+		}
+
+		c.entries = map[string]entry{}
+		c.flights = map[string]*flight{}
+
+		return
+	}
+
+	drop := map[string]bool{}
+	for _, name := range names {
+		drop[name] = true
+		c.gen[name]++
+	}
+
+	for key := range c.entries {
+		if drop[rcon.CommandName([]string{key})] {
+			delete(c.entries, key)
+		}
+	}
+
+	// Reads in flight may have started before the mutation, so later reads must not join them.
+	for key := range c.flights {
+		if drop[rcon.CommandName([]string{key})] {
+			delete(c.flights, key)
+		}
+	}
+}
+
+// Stats returns the hits, misses and coalesced reads of the Cache.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
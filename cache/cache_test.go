@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+var vips = []string{"get", "vipids"}
+
+// server represents the Handler behind a Cache, numbering its responses.
+type server struct {
+	mu      sync.Mutex
+	sent    int
+	err     error
+	block   chan struct{} // Reads wait for it to close, when set.
+	started chan struct{} // Receives every read sent.
+}
+
+func (s *server) handle(r rcon.Request) (string, error) {
+	s.mu.Lock()
+	s.sent++
+	n, err, block, started := s.sent, s.err, s.block, s.started
+	s.mu.Unlock()
+
+	if rcon.Mutating(r.Cmds) {
+		return "SUCCESS", nil
+	}
+
+	if started != nil {
+		started <- struct{}{}
+	}
+
+	if block != nil {
+		<-block
+	}
+
+	return "result " + strconv.Itoa(n), err
+}
+
+func (s *server) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sent
+}
+
+// clock represents a time advanced only by tests.
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func newCache() (*Cache, rcon.Handler, *server, *clock) {
+	s := &server{}
+	clk := &clock{t: time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)}
+
+	c := New(map[string]time.Duration{"get vipids": 30 * time.Second})
+	c.now = clk.now
+
+	return c, c.Wrap(s.handle), s, clk
+}
+
+func TestTTL(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmds     []string
+		elapsed  time.Duration
+		err      error
+		want     string
+		wantSent int
+	}{
+		{name: "fresh", cmds: vips, elapsed: 29 * time.Second, want: "result 1", wantSent: 1},
+		{name: "expired", cmds: vips, elapsed: 30 * time.Second, want: "result 2", wantSent: 2},
+		{name: "uncached", cmds: []string{"get", "adminids"}, want: "result 2", wantSent: 2},
+		{name: "failed", cmds: vips, err: errors.New("timeout"), want: "result 2", wantSent: 2},
+		{name: "arguments", cmds: []string{"get", "vipids", "2"}, want: "result 2", wantSent: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, h, s, clk := newCache()
+			s.err = tt.err
+
+			h(rcon.Request{Cmds: vips})
+
+			s.err = nil
+			clk.t = clk.t.Add(tt.elapsed)
+
+			got, err := h(rcon.Request{Cmds: tt.cmds})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want || s.count() != tt.wantSent {
+				t.Errorf("read %q after %d sent, want %q after %d", got, s.count(), tt.want, tt.wantSent)
+			}
+
+			if tt.wantSent == 1 && c.Stats() != (Stats{Hits: 1, Misses: 1}) {
+				t.Errorf("Stats() = %+v, want a hit and a miss", c.Stats())
+			}
+		})
+	}
+}
+
+func TestCoalesced(t *testing.T) {
+	c, h, s, _ := newCache()
+	s.block = make(chan struct{})
+	s.started = make(chan struct{}, 1)
+
+	const readers = 5
+
+	results := make(chan string, readers)
+	read := func() {
+		result, err := h(rcon.Request{Cmds: vips})
+		if err != nil {
+			t.Error(err)
+		}
+
+		results <- result
+	}
+
+	go read()
+	<-s.started
+
+	for i := 1; i < readers; i++ {
+		go read()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Coalesced < readers-1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Stats() = %+v, want %d coalesced reads", c.Stats(), readers-1)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	close(s.block)
+
+	for i := 0; i < readers; i++ {
+		if got := <-results; got != "result 1" {
+			t.Errorf("read %q, want the single response", got)
+		}
+	}
+
+	if s.count() != 1 {
+		t.Errorf("sent %d reads, want 1", s.count())
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	c, h, s, _ := newCache()
+
+	stale := make(chan struct{})
+	s.block = stale
+	s.started = make(chan struct{}, 1)
+
+	first := make(chan string, 1)
+	go func() {
+		result, _ := h(rcon.Request{Cmds: vips})
+		first <- result
+	}()
+
+	<-s.started
+
+	// The read in flight started before the VIP was added, so it may not include them.
+	_, err := h(rcon.Request{Cmds: []string{"vipadd", "76561198000000001", `"Bob"`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.mu.Lock()
+	s.block = nil
+	s.started = nil
+	s.mu.Unlock()
+
+	second, err := h(rcon.Request{Cmds: vips})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second != "result 3" {
+		t.Errorf("read %q after the mutation, want a new read", second)
+	}
+
+	close(stale)
+
+	if got := <-first; got != "result 1" {
+		t.Errorf("read %q in flight, want %q", got, "result 1")
+	}
+
+	third, err := h(rcon.Request{Cmds: vips})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if third != second {
+		t.Errorf("read %q after the stale read completed, want the cached %q", third, second)
+	}
+
+	if stats := c.Stats(); stats != (Stats{Hits: 1, Misses: 2}) {
+		t.Errorf("Stats() = %+v, want a hit and 2 misses", stats)
+	}
+
+	c.Invalidate()
+
+	fourth, _ := h(rcon.Request{Cmds: vips})
+	if fourth != "result 4" {
+		t.Errorf("read %q after invalidating everything, want a new read", fourth)
+	}
+}
@@ -0,0 +1,102 @@
+package chatbot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/verocity-gaming/rcon/internal/humanize"
+)
+
+// admin will message every admin online on behalf of the player, with their message if any.
+func (b *Bot) admin(r Request) (string, error) {
+	admins, _, err := b.permissions()
+	if err != nil {
+		return "", err
+	}
+
+	players, err := b.Conn.Players()
+	if err != nil {
+		return "", err
+	}
+
+	message := fmt.Sprintf("%s needs an admin.", r.Player.Name)
+	if len(r.Args) > 0 {
+		message = fmt.Sprintf("%s needs an admin: %s", r.Player.Name, strings.Join(r.Args, " "))
+	}
+
+	notified := 0
+
+	for _, p := range players {
+		if !admins[p.ID64] || p.ID64 == r.Player.ID64 {
+			continue
+		}
+
+		err := b.Conn.As(b.Operator).Message(p, message)
+		if err != nil {
+			b.error(err)
+			continue
+		}
+
+		notified++
+	}
+
+	if notified == 0 {
+		return "No admins are online, please try again later.", nil
+	}
+
+	return fmt.Sprintf("%s notified.", humanize.Plural(notified, "admin")), nil
+}
+
+// vip will tell the player whether they are a VIP, and when their membership expires.
+func (b *Bot) vip(r Request) (string, error) {
+	if !r.VIP {
+		return "You are not a VIP.", nil
+	}
+
+	if b.VIPs == nil {
+		return "You are a VIP.", nil
+	}
+
+	now := b.now()
+
+	ms, ok := b.VIPs.Membership(r.Player.ID64)
+	if !ok {
+		return "You are a VIP, and your membership does not expire.", nil
+	}
+
+	return ms.Describe(now), nil
+}
+
+// rules will send the numbered Rules.
+func (b *Bot) rules(r Request) (string, error) {
+	if len(b.Rules) == 0 {
+		return "No rules have been set.", nil
+	}
+
+	lines := []string{}
+	for i, rule := range b.Rules {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, rule))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// maps will send the current and next map, and the map rotation.
+func (b *Bot) maps(r Request) (string, error) {
+	state, err := b.Conn.GameState()
+	if err != nil {
+		return "", err
+	}
+
+	rotation, err := b.Conn.Rotation()
+	if err != nil {
+		return "", err
+	}
+
+	names := []string{}
+	for _, m := range rotation {
+		names = append(names, humanize.Map(m))
+	}
+
+	return fmt.Sprintf("Current map: %s\nNext map: %s\nRotation: %s", humanize.Map(state.Map), humanize.Map(state.NextMap), strings.Join(names, ", ")), nil
+}
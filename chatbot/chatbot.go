@@ -0,0 +1,310 @@
+// Package chatbot answers commands typed by players in the in-game chat, e.g. "!rules".
+//
+// A Bot consumes chat Events from a LogStream subscription and dispatches messages starting with
+// its Prefix to registered Commands, checking the player's permission against the server's admins
+// and VIPs and the Command's cooldown first. Replies are sent to the player as private messages.
+package chatbot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/consume"
+	"github.com/verocity-gaming/rcon/internal/humanize"
+	"github.com/verocity-gaming/rcon/internal/roles"
+	"github.com/verocity-gaming/rcon/vipmanager"
+)
+
+// Permission represents who may use a Command.
+type Permission int
+
+// Permissions in increasing order of privilege. Admins have every Permission.
+const (
+	PermissionEveryone Permission = iota
+	PermissionVIP
+	PermissionAdmin
+)
+
+// Handler returns the reply to a Request, or nothing when the reply is empty.
+type Handler func(r Request) (string, error)
+
+// Command represents a chat command.
+type Command struct {
+	// Name is the command without the Bot's Prefix, e.g. "rules".
+	Name string
+
+	// Usage is the description of the Command's arguments, e.g. "[message]".
+	Usage string
+
+	Permission Permission
+
+	// Cooldown is how long a player must wait between uses of the Command. Admins are exempt, and
+	// uses failing with an error do not count.
+	Cooldown time.Duration
+
+	Handler Handler
+}
+
+// Request represents a use of a Command by a player.
+type Request struct {
+	Player rcon.Player
+	Name   string
+	Args   []string
+	Event  rcon.Event
+
+	// Admin and VIP are whether the player is an admin or a VIP of the server.
+	Admin bool
+	VIP   bool
+}
+
+// Bot represents the chat commands of a single server.
+type Bot struct {
+	Conn *rcon.Conn
+
+	// Prefix starts every command. "!" by default.
+	Prefix string
+
+	// Rules are the lines sent by the built-in !rules command.
+	Rules []string
+
+	// VIPs, when set, provides the expiry shown by the built-in !vip command.
+	VIPs *vipmanager.Store
+
+	// PermissionTTL is how long the admins and VIPs of the server are kept between permission
+	// checks. A minute by default.
+	PermissionTTL time.Duration
+
+	// Operator is the name replies are sent in, "chatbot" by default.
+	Operator string
+
+	// OnError is called with errors from permission checks, handlers and replies.
+	OnError func(error)
+
+	mu        sync.Mutex
+	commands  map[string]Command
+	cooldowns map[string]time.Time // Keyed by command name and ID64.
+	cache     roles.Cache
+	now       func() time.Time
+}
+
+// New returns a Bot for a Conn with the built-in !admin, !vip, !rules and !maps commands.
+func New(c *rcon.Conn) *Bot {
+	b := &Bot{
+		Conn:          c,
+		Prefix:        "!",
+		PermissionTTL: time.Minute,
+		Operator:      "chatbot",
+		commands:      map[string]Command{},
+		cooldowns:     map[string]time.Time{},
+		now:           time.Now,
+	}
+
+	b.Register(
+		Command{Name: "admin", Usage: "[message]", Cooldown: 2 * time.Minute, Handler: b.admin},
+		Command{Name: "vip", Handler: b.vip},
+		Command{Name: "rules", Cooldown: 30 * time.Second, Handler: b.rules},
+		Command{Name: "maps", Cooldown: 30 * time.Second, Handler: b.maps},
+	)
+
+	return b
+}
+
+// Register will add Commands, replacing any Command of the same name.
+func (b *Bot) Register(commands ...Command) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, c := range commands {
+		b.commands[strings.ToLower(c.Name)] = c
+	}
+}
+
+// Unregister will remove the Commands of the given names.
+func (b *Bot) Unregister(names ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, name := range names {
+		delete(b.commands, strings.ToLower(name))
+	}
+}
+
+// Commands returns every registered Command, sorted by name.
+func (b *Bot) Commands() []Command {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	list := []Command{}
+	for _, c := range b.commands {
+		list = append(list, c)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// Consume will handle the Events of a channel, e.g. a LogStream subscription, until it is closed
+// or the context is cancelled.
+func (b *Bot) Consume(ctx context.Context, events <-chan rcon.Event) error {
+	return consume.Events(ctx, events, b.Handle)
+}
+
+// Handle will dispatch a chat Event to its Command. Other Events, messages without the Prefix and
+// unknown commands are ignored.
+func (b *Bot) Handle(e rcon.Event) {
+	r, c, ok := b.parse(e)
+	if !ok {
+		return
+	}
+
+	denied := b.permit(&r, c)
+	if denied != "" {
+		b.reply(r.Player, denied)
+		return
+	}
+
+	reply, err := c.Handler(r)
+	if err != nil {
+		b.error(fmt.Errorf("failed to handle %s%s from %s: %w", b.Prefix, c.Name, r.Player, err))
+		b.release(r, c)
+
+		reply = fmt.Sprintf("%s%s failed, please try again later.", b.Prefix, c.Name)
+	}
+
+	b.reply(r.Player, reply)
+}
+
+// parse returns the Request of a chat Event and its Command.
+func (b *Bot) parse(e rcon.Event) (Request, Command, bool) {
+	if e.Kind != rcon.EventChat || e.Player.ID64 == "" {
+		return Request{}, Command{}, false
+	}
+
+	message := strings.TrimSpace(e.Message)
+	if b.Prefix == "" || !strings.HasPrefix(message, b.Prefix) {
+		return Request{}, Command{}, false
+	}
+
+	message = strings.TrimPrefix(message, b.Prefix)
+
+	fields := strings.Fields(message)
+	if len(fields) == 0 {
+		return Request{}, Command{}, false
+	}
+
+	name := strings.ToLower(fields[0])
+
+	b.mu.Lock()
+	c, ok := b.commands[name]
+	b.mu.Unlock()
+
+	if !ok || c.Handler == nil {
+		return Request{}, Command{}, false
+	}
+
+	r := Request{
+		Player: e.Player,
+		Name:   name,
+		Args:   rcon.Arguments([]string{message}),
+		Event:  e,
+	}
+
+	return r, c, true
+}
+
+// permit will set the Admin and VIP of a Request, returning the reason the player may not use the
+// Command yet, if any.
+func (b *Bot) permit(r *Request, c Command) string {
+	admins, vips, err := b.permissions()
+	if err != nil {
+		b.error(err)
+
+		// Commands available to everyone go on without knowing who is an admin or a VIP.
+		if c.Permission > PermissionEveryone {
+			return fmt.Sprintf("%s%s is unavailable, please try again later.", b.Prefix, c.Name)
+		}
+	}
+
+	r.Admin = admins[r.Player.ID64]
+	r.VIP = vips[r.Player.ID64]
+
+	switch {
+	case c.Permission >= PermissionAdmin && !r.Admin:
+		return fmt.Sprintf("%s%s is only available to admins.", b.Prefix, c.Name)
+	case c.Permission >= PermissionVIP && !r.Admin && !r.VIP:
+		return fmt.Sprintf("%s%s is only available to VIPs.", b.Prefix, c.Name)
+	}
+
+	if r.Admin || c.Cooldown <= 0 {
+		return ""
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	key := cooldownKey(*r, c)
+
+	if until, ok := b.cooldowns[key]; ok && now.Before(until) {
+		return fmt.Sprintf("You can use %s%s again in %s.", b.Prefix, c.Name, humanize.Duration(until.Sub(now)))
+	}
+
+	for k, until := range b.cooldowns {
+		if !now.Before(until) {
+			delete(b.cooldowns, k)
+		}
+	}
+
+	b.cooldowns[key] = now.Add(c.Cooldown)
+
+	return ""
+}
+
+// release will end the cooldown a Request started, e.g. when its Command failed.
+func (b *Bot) release(r Request, c Command) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.cooldowns, cooldownKey(r, c))
+}
+
+func cooldownKey(r Request, c Command) string {
+	return c.Name + "/" + r.Player.ID64
+}
+
+// permissions returns the admins and VIPs of the server keyed by ID64, refreshed every
+// PermissionTTL. The previous lists are returned with the error of a failed refresh.
+func (b *Bot) permissions() (admins, vips map[string]bool, err error) {
+	admins, vips, err = b.cache.Get(b.Conn, b.PermissionTTL, b.now())
+	if err != nil {
+		return admins, vips, fmt.Errorf("failed to check permissions: %w", err)
+	}
+
+	return admins, vips, nil
+}
+
+// reply will send a private message to a player, unless it is empty.
+func (b *Bot) reply(p rcon.Player, message string) {
+	if message == "" {
+		return
+	}
+
+	err := b.Conn.As(b.Operator).Message(p, message)
+	if err != nil {
+		b.error(err)
+	}
+}
+
+func (b *Bot) error(err error) {
+	if b.OnError != nil {
+		b.OnError(err)
+	}
+}
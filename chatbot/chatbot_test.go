@@ -0,0 +1,224 @@
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+	"github.com/verocity-gaming/rcon/vipmanager"
+)
+
+var (
+	now = time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	bob   = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+	vip   = rcon.Player{Name: "Vic", ID64: "76561198000000002"}
+	admin = rcon.Player{Name: "Ada", ID64: "76561198000000003"}
+)
+
+func chat(p rcon.Player, message string) rcon.Event {
+	return rcon.Event{Kind: rcon.EventChat, Player: p, Message: message}
+}
+
+func TestParse(t *testing.T) {
+	b := New(nil)
+
+	tests := []struct {
+		name     string
+		event    rcon.Event
+		prefix   string
+		wantName string
+		wantArgs []string
+		wantOK   bool
+	}{
+		{name: "command", event: chat(bob, "!rules"), wantName: "rules", wantArgs: []string{}, wantOK: true},
+		{name: "arguments", event: chat(bob, "!admin help me"), wantName: "admin", wantArgs: []string{"help", "me"}, wantOK: true},
+		{name: "quoted", event: chat(bob, `!admin "help me" now`), wantName: "admin", wantArgs: []string{"help me", "now"}, wantOK: true},
+		{name: "case and space", event: chat(bob, "  !RULES  "), wantName: "rules", wantArgs: []string{}, wantOK: true},
+		{name: "prefix", event: chat(bob, ".rules"), prefix: ".", wantName: "rules", wantArgs: []string{}, wantOK: true},
+		{name: "no prefix", event: chat(bob, "rules")},
+		{name: "prefix only", event: chat(bob, "! ")},
+		{name: "unknown", event: chat(bob, "!teleport")},
+		{name: "no id", event: chat(rcon.Player{Name: "Bob"}, "!rules")},
+		{name: "not chat", event: rcon.Event{Kind: rcon.EventKill, Player: bob, Message: "!rules"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b.Prefix = "!"
+			if tt.prefix != "" {
+				b.Prefix = tt.prefix
+			}
+
+			r, c, ok := b.parse(tt.event)
+			if ok != tt.wantOK {
+				t.Fatalf("parse() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if !ok {
+				return
+			}
+
+			if r.Name != tt.wantName || c.Name != tt.wantName || fmt.Sprint(r.Args) != fmt.Sprint(tt.wantArgs) {
+				t.Errorf("parse() = %s %q, want %s %q", r.Name, r.Args, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// use represents a chat command and the reply it gets, relative to the first.
+type use struct {
+	at     time.Duration
+	player rcon.Player
+	fail   bool // Whether the Handler fails.
+	want   string
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name       string
+		permission Permission
+		uses       []use
+	}{
+		{
+			name: "cooldown",
+			uses: []use{
+				{at: 0, player: bob, want: "rolled"},
+				{at: 30 * time.Second, player: bob, want: "You can use !roll again in 30 seconds."},
+				{at: 30 * time.Second, player: vip, want: "rolled"},
+				{at: time.Minute, player: bob, want: "rolled"},
+			},
+		},
+		{
+			name: "refunded",
+			uses: []use{
+				{at: 0, player: bob, fail: true, want: "!roll failed, please try again later."},
+				{at: time.Second, player: bob, want: "rolled"},
+				{at: 2 * time.Second, player: bob, want: "You can use !roll again in 59 seconds."},
+			},
+		},
+		{
+			name: "admin exempt",
+			uses: []use{
+				{at: 0, player: admin, want: "rolled"},
+				{at: time.Second, player: admin, want: "rolled"},
+			},
+		},
+		{
+			name:       "vip",
+			permission: PermissionVIP,
+			uses: []use{
+				{at: 0, player: bob, want: "!roll is only available to VIPs."},
+				{at: 0, player: vip, want: "rolled"},
+				{at: 0, player: admin, want: "rolled"},
+			},
+		},
+		{
+			name:       "admin",
+			permission: PermissionAdmin,
+			uses: []use{
+				{at: 0, player: vip, want: "!roll is only available to admins."},
+				{at: 0, player: admin, want: "rolled"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := rcontest.NewServer(t)
+			srv.Reply("get adminids", rcontest.List(admin.ID64+` owner "`+admin.Name+`"`))
+			srv.Reply("get vipids", rcontest.List(vip.ID64+` "`+vip.Name+`"`))
+
+			var mu sync.Mutex
+			replies := []string{}
+
+			srv.Handle("message", func(args []string) string {
+				mu.Lock()
+				defer mu.Unlock()
+
+				replies = append(replies, args[1])
+
+				return "SUCCESS"
+			})
+
+			b := New(srv.Conn(t))
+
+			fail := false
+			b.Register(Command{
+				Name:       "roll",
+				Permission: tt.permission,
+				Cooldown:   time.Minute,
+				Handler: func(Request) (string, error) {
+					if fail {
+						return "", errors.New("dice lost")
+					}
+
+					return "rolled", nil
+				},
+			})
+
+			for i, u := range tt.uses {
+				at := now.Add(u.at)
+				b.now = func() time.Time { return at }
+				fail = u.fail
+
+				mu.Lock()
+				replies = replies[:0]
+				mu.Unlock()
+
+				b.Handle(chat(u.player, "!roll"))
+
+				mu.Lock()
+				got := strings.Join(replies, "|")
+				mu.Unlock()
+
+				if got != u.want {
+					t.Errorf("use %d by %s: replied %q, want %q", i, u.player.Name, got, u.want)
+				}
+			}
+		})
+	}
+}
+
+func TestVIP(t *testing.T) {
+	store := &vipmanager.Store{Memberships: map[string]*vipmanager.Membership{
+		"expired": {Expires: now.Add(-time.Minute)},
+		"active":  {Expires: now.Add(26 * time.Hour)},
+	}}
+
+	tests := []struct {
+		name  string
+		id64  string
+		vip   bool
+		store *vipmanager.Store
+		want  string
+	}{
+		{name: "not vip", id64: "active", store: store, want: "You are not a VIP."},
+		{name: "no store", id64: "active", vip: true, want: "You are a VIP."},
+		{name: "no membership", id64: "permanent", vip: true, store: store, want: "You are a VIP, and your membership does not expire."},
+		{name: "expired", id64: "expired", vip: true, store: store, want: "Your VIP membership has expired, and will be removed shortly."},
+		{name: "active", id64: "active", vip: true, store: store, want: "Your VIP membership expires in 1 day 2 hours, on Oct 20 22:00 UTC."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(nil)
+			b.VIPs = tt.store
+			b.now = func() time.Time { return now }
+
+			got, err := b.vip(Request{Player: rcon.Player{Name: "Bob", ID64: tt.id64}, VIP: tt.vip})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("vip() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+// Package chatfilter acts on abusive language in the in-game chat.
+//
+// The server's profanity list only censors words. A Filter consumes chat Events from a LogStream
+// subscription, matches them against word lists and regular expressions, and warns, punishes or
+// kicks the author according to the severity of the match. Words are matched whole, after undoing
+// leetspeak substitutions and repeated letters, and allowed words are never matched. The word list
+// can be exported to the server, so it is censored too.
+package chatfilter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/consume"
+)
+
+// Severity represents how offensive a match is.
+type Severity int
+
+// Severities in increasing order.
+const (
+	SeverityMild Severity = iota
+	SeverityModerate
+	SeveritySevere
+)
+
+// Action represents the consequence of a match.
+type Action string
+
+// Actions.
+const (
+	ActionNone   Action = "none" // Only report the match to OnMatch.
+	ActionWarn   Action = "warn" // Message the author.
+	ActionPunish Action = "punish"
+	ActionKick   Action = "kick"
+)
+
+// DefaultActions are the Actions for each Severity.
+var DefaultActions = map[Severity]Action{
+	SeverityMild:     ActionWarn,
+	SeverityModerate: ActionPunish,
+	SeveritySevere:   ActionKick,
+}
+
+// DefaultMessages are the messages sent to authors before each Action, supporting {name}.
+var DefaultMessages = map[Action]string{
+	ActionWarn:   "Watch your language, {name}.",
+	ActionPunish: "You have been punished for abusive language, {name}.",
+	ActionKick:   "You are being kicked for abusive language, {name}.",
+}
+
+// DefaultReason is the reason given to punishes and kicks.
+const DefaultReason = "Abusive language"
+
+// Pattern represents a regular expression matched against the lowercased chat message and its
+// normalized forms, e.g. `n[i1]g+`.
+type Pattern struct {
+	Expr     *regexp.Regexp
+	Severity Severity
+}
+
+// Match represents a chat message that was acted on.
+type Match struct {
+	Player   rcon.Player
+	Event    rcon.Event
+	Term     string // The word or expression matched.
+	Severity Severity
+	Action   Action
+	Err      error
+}
+
+// Filter represents the chat filter of a single server.
+type Filter struct {
+	Conn *rcon.Conn
+
+	Patterns []Pattern
+
+	// Allow holds words that are never matched, by words or Patterns, e.g. "scunthorpe".
+	Allow []string
+
+	// Actions are the Actions for each Severity, a copy of DefaultActions by default.
+	Actions map[Severity]Action
+
+	// Messages are the templates sent to authors for each Action, a copy of DefaultMessages by
+	// default.
+	Messages map[Action]string
+
+	// Reason is the reason given to punishes and kicks, DefaultReason by default.
+	Reason string
+
+	// Operator is the name warnings, punishes and kicks are sent in, "chatfilter" by default.
+	Operator string
+
+	// OnMatch is called for every message matched.
+	OnMatch func(Match)
+
+	mu    sync.Mutex
+	words map[string]word
+}
+
+type word struct {
+	expr     *regexp.Regexp
+	severity Severity
+}
+
+// New returns a Filter for a Conn with no words.
+func New(c *rcon.Conn) *Filter {
+	actions := map[Severity]Action{}
+	for s, a := range DefaultActions {
+		actions[s] = a
+	}
+
+	messages := map[Action]string{}
+	for a, m := range DefaultMessages {
+		messages[a] = m
+	}
+
+	return &Filter{
+		Conn:     c,
+		Actions:  actions,
+		Messages: messages,
+		Reason:   DefaultReason,
+		Operator: "chatfilter",
+		words:    map[string]word{},
+	}
+}
+
+// AddWords will match words with a Severity, replacing the Severity of words already added.
+func (f *Filter) AddWords(s Severity, words ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if normalize(w, true) == "" {
+			continue
+		}
+
+		f.words[w] = word{expr: compile(w), severity: s}
+	}
+}
+
+// RemoveWords will stop matching words.
+func (f *Filter) RemoveWords(words ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, w := range words {
+		delete(f.words, strings.ToLower(strings.TrimSpace(w)))
+	}
+}
+
+// Words returns every word matched, sorted.
+func (f *Filter) Words() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := []string{}
+	for w := range f.words {
+		list = append(list, w)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
+
+// Export will add the words matched to the server's profanities, so they are censored too. Words
+// the server cannot hold, e.g. phrases, are skipped and returned.
+func (f *Filter) Export() ([]string, error) {
+	words := []string{}
+	skipped := []string{}
+
+	for _, w := range f.Words() {
+		if rcon.ValidateProfanity(w) != nil {
+			skipped = append(skipped, w)
+			continue
+		}
+
+		words = append(words, w)
+	}
+
+	if len(words) == 0 {
+		return skipped, nil
+	}
+
+	err := f.Conn.As(f.Operator).SetProfanities(words...)
+	if err != nil {
+		return skipped, fmt.Errorf("failed to export words: %w", err)
+	}
+
+	return skipped, nil
+}
+
+// Check returns the most severe word or Pattern in a message.
+func (f *Filter) Check(message string) (term string, s Severity, ok bool) {
+	allowed := map[string]bool{}
+	for _, a := range f.Allow {
+		for _, v := range variants(a) {
+			allowed[strings.ReplaceAll(v, " ", "")] = true
+		}
+	}
+
+	kept := []string{}
+	for _, token := range strings.Fields(message) {
+		if !allowed[normalize(token, true)] && !allowed[normalize(token, false)] {
+			kept = append(kept, token)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, token := range kept {
+		for w, def := range f.words {
+			if ok && def.severity < s || ok && def.severity == s && w > term {
+				continue
+			}
+
+			for _, v := range variants(token) {
+				if def.expr.MatchString(v) {
+					term, s, ok = w, def.severity, true
+					break
+				}
+			}
+		}
+	}
+
+	// Patterns also see the words of a message joined, so they match words split by punctuation.
+	text := strings.ToLower(strings.Join(kept, " "))
+	forms := append([]string{text}, variants(text)...)
+
+	for _, p := range f.Patterns {
+		if p.Expr == nil || ok && p.Severity <= s {
+			continue
+		}
+
+		for _, form := range forms {
+			if p.Expr.MatchString(form) {
+				term, s, ok = p.Expr.String(), p.Severity, true
+				break
+			}
+		}
+	}
+
+	return term, s, ok
+}
+
+// Consume will handle the Events of a channel, e.g. a LogStream subscription, until it is closed
+// or the context is cancelled.
+func (f *Filter) Consume(ctx context.Context, events <-chan rcon.Event) error {
+	return consume.Events(ctx, events, f.Handle)
+}
+
+// Handle will check a chat Event and act on its author. Other Events are ignored.
+func (f *Filter) Handle(e rcon.Event) {
+	if e.Kind != rcon.EventChat || e.Player.ID64 == "" {
+		return
+	}
+
+	term, s, ok := f.Check(e.Message)
+	if !ok {
+		return
+	}
+
+	action, ok := f.Actions[s]
+	if !ok {
+		action = DefaultActions[s]
+	}
+
+	m := Match{Player: e.Player, Event: e, Term: term, Severity: s, Action: action}
+	m.Err = f.apply(m)
+
+	if f.OnMatch != nil {
+		f.OnMatch(m)
+	}
+}
+
+// apply will message the author and carry out the Action of a Match.
+func (f *Filter) apply(m Match) error {
+	if m.Action == ActionNone || m.Action == "" {
+		return nil
+	}
+
+	message, ok := f.Messages[m.Action]
+	if !ok {
+		message = DefaultMessages[m.Action]
+	}
+
+	c := f.Conn.As(f.Operator)
+
+	// The message is sent first, as kicked players cannot receive it afterwards.
+	err := c.Message(m.Player, strings.NewReplacer("{name}", m.Player.Name).Replace(message))
+
+	switch m.Action {
+	case ActionWarn:
+		return err
+	case ActionPunish:
+		return c.Punish(m.Player, f.reason())
+	case ActionKick:
+		return c.Kick(m.Player, f.reason())
+	default:
+		return fmt.Errorf("unknown action %q", m.Action)
+	}
+}
+
+func (f *Filter) reason() string {
+	if f.Reason == "" {
+		return DefaultReason
+	}
+
+	return f.Reason
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityMild:
+		return "mild"
+	case SeverityModerate:
+		return "moderate"
+	case SeveritySevere:
+		return "severe"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
@@ -0,0 +1,160 @@
+package chatfilter
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+)
+
+var bob = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in         string
+		substitute bool
+		want       string
+	}{
+		{in: "Hello", substitute: true, want: "hello"},
+		{in: "$h.1.t", substitute: true, want: "shit"},
+		{in: "$h.1.t", substitute: false, want: "ht"},
+		{in: "n00b", substitute: true, want: "noob"},
+		{in: "4$$", substitute: true, want: "ass"},
+		{in: "l|k3 th!s", substitute: true, want: "llke this"},
+		{in: "wow!", substitute: false, want: "wow"},
+		{in: "g g\twp", substitute: true, want: "g g wp"},
+		{in: "Äpfel", substitute: true, want: "äpfel"},
+		{in: "123", substitute: false, want: ""},
+	}
+
+	for _, tt := range tests {
+		got := normalize(tt.in, tt.substitute)
+		if got != tt.want {
+			t.Errorf("normalize(%q, %v) = %q, want %q", tt.in, tt.substitute, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	f := New(nil)
+	f.AddWords(SeverityMild, "shit", "ass")
+	f.AddWords(SeverityModerate, "idiot")
+	f.AddWords(SeveritySevere, "slur")
+	f.Patterns = []Pattern{
+		{Expr: regexp.MustCompile(`\bkys\b|kill yourself`), Severity: SeverityModerate},
+		{Expr: regexp.MustCompile(`noob`), Severity: SeverityMild},
+	}
+	f.Allow = []string{"scunthorpe", "a$$et"}
+
+	tests := []struct {
+		name     string
+		message  string
+		wantTerm string
+		wantSev  Severity
+		wantOK   bool
+	}{
+		{name: "clean", message: "good game everyone"},
+		{name: "word", message: "that was shit", wantTerm: "shit", wantSev: SeverityMild, wantOK: true},
+		{name: "leetspeak", message: "$h1t", wantTerm: "shit", wantSev: SeverityMild, wantOK: true},
+		{name: "repeated letters", message: "aaasss", wantTerm: "ass", wantSev: SeverityMild, wantOK: true},
+		{name: "punctuated", message: "you ass!", wantTerm: "ass", wantSev: SeverityMild, wantOK: true},
+		{name: "within word", message: "first class"},
+		{name: "allowed", message: "greetings from scunthorpe"},
+		{name: "allowed leetspeak", message: "a$$et"},
+		{name: "allowed as asset", message: "asset"},
+		{name: "most severe", message: "shit idiot slur", wantTerm: "slur", wantSev: SeveritySevere, wantOK: true},
+		{name: "pattern", message: "just kys", wantTerm: `\bkys\b|kill yourself`, wantSev: SeverityModerate, wantOK: true},
+		{name: "pattern across words", message: "kill. yourself", wantTerm: `\bkys\b|kill yourself`, wantSev: SeverityModerate, wantOK: true},
+		{name: "pattern leetspeak", message: "n00b", wantTerm: "noob", wantSev: SeverityMild, wantOK: true},
+		{name: "pattern more severe", message: "shit, kys", wantTerm: `\bkys\b|kill yourself`, wantSev: SeverityModerate, wantOK: true},
+		{name: "word more severe", message: "noob slur", wantTerm: "slur", wantSev: SeveritySevere, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term, s, ok := f.Check(tt.message)
+			if term != tt.wantTerm || s != tt.wantSev || ok != tt.wantOK {
+				t.Errorf("Check(%q) = %q, %v, %v, want %q, %v, %v", tt.message, term, s, ok, tt.wantTerm, tt.wantSev, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name       string
+		message    string
+		actions    map[Severity]Action
+		wantAction Action
+		wantSent   []string
+	}{
+		{name: "mild", message: "shit", wantAction: ActionWarn, wantSent: []string{"message"}},
+		{name: "moderate", message: "idiot", wantAction: ActionPunish, wantSent: []string{"message", "punish"}},
+		{name: "severe", message: "slur", wantAction: ActionKick, wantSent: []string{"message", "kick"}},
+		{name: "escalated", message: "shit idiot slur", wantAction: ActionKick, wantSent: []string{"message", "kick"}},
+		{name: "none", message: "shit", actions: map[Severity]Action{SeverityMild: ActionNone}, wantAction: ActionNone, wantSent: []string{}},
+		{name: "clean", message: "gg", wantSent: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := rcontest.NewServer(t)
+
+			f := New(srv.Conn(t))
+			f.AddWords(SeverityMild, "shit")
+			f.AddWords(SeverityModerate, "idiot")
+			f.AddWords(SeveritySevere, "slur")
+
+			if tt.actions != nil {
+				f.Actions = tt.actions
+			}
+
+			matches := []Match{}
+			f.OnMatch = func(m Match) { matches = append(matches, m) }
+
+			f.Handle(rcon.Event{Kind: rcon.EventChat, Player: bob, Message: tt.message})
+
+			if tt.wantAction == "" {
+				if len(matches) > 0 {
+					t.Errorf("matched %+v, want no match", matches)
+				}
+			} else {
+				if len(matches) != 1 || matches[0].Action != tt.wantAction || matches[0].Err != nil {
+					t.Errorf("matched %+v, want a single %s", matches, tt.wantAction)
+				}
+			}
+
+			sent := []string{}
+			for _, cmd := range srv.Sent() {
+				sent = append(sent, rcon.CommandName([]string{cmd}))
+			}
+
+			if strings.Join(sent, " ") != strings.Join(tt.wantSent, " ") {
+				t.Errorf("sent %q, want %q", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	srv := rcontest.NewServer(t)
+
+	f := New(srv.Conn(t))
+	f.AddWords(SeverityMild, "shit", "kill yourself", "ass")
+
+	skipped, err := f.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(skipped, ",") != "kill yourself" {
+		t.Errorf("Export() skipped %q, want the phrase", skipped)
+	}
+
+	sent := srv.Sent()
+	if len(sent) != 1 || !strings.Contains(sent[0], "ass,shit") || strings.Contains(sent[0], "kill") {
+		t.Errorf("sent %q, want the words only", sent)
+	}
+}
@@ -0,0 +1,61 @@
+package chatfilter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// leet maps characters commonly substituted for letters to the letters they stand for.
+var leet = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '9': 'g',
+	'@': 'a', '$': 's', '!': 'i', '|': 'l', '+': 't', '€': 'e',
+}
+
+// normalize will lowercase a message and remove every character but letters and whitespace, first
+// replacing leetspeak substitutions with letters when substitute is set, so "$h.1.t" becomes
+// "shit". Whitespace is kept, so the result can be split into words.
+func normalize(s string, substitute bool) string {
+	b := &strings.Builder{}
+
+	for _, r := range strings.ToLower(s) {
+		if l, ok := leet[r]; ok && substitute {
+			r = l
+		}
+
+		switch {
+		case unicode.IsSpace(r):
+			b.WriteRune(' ')
+		case unicode.IsLetter(r):
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// compile returns an expression matching a whole normalized word with any of its letters
+// repeated, so "ass" matches "aaasss" but not "as".
+func compile(word string) *regexp.Regexp {
+	b := &strings.Builder{}
+	b.WriteString("^")
+
+	for _, r := range normalize(word, true) {
+		if r == ' ' {
+			continue
+		}
+
+		b.WriteString(regexp.QuoteMeta(string(r)))
+		b.WriteString("+")
+	}
+
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String())
+}
+
+// variants returns the normalized forms of a word, with and without leetspeak substitutions, as
+// punctuation such as "!" may either stand for a letter or end a sentence.
+func variants(word string) []string {
+	return []string{normalize(word, true), normalize(word, false)}
+}
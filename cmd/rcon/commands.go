@@ -0,0 +1,863 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"gopkg.in/yaml.v3"
+)
+
+// errCancelled is returned when the user declines a confirmation.
+var errCancelled = errors.New("cancelled")
+
+// commands returns the command tree of the client.
+func commands() []*command {
+	return []*command{
+		{name: "name", help: "show the server name", run: serverName},
+		{name: "slots", help: "show the number of players and slots", run: slots},
+		{name: "broadcast", args: "<message>", help: "set the broadcast message", min: 1, max: -1, run: broadcast},
+		{name: "message", args: "<player> <message>", help: "send a private message to a player", min: 2, max: -1, run: messagePlayer},
+		{name: "send", args: "<command>", help: "send a raw command and print the response", min: 1, max: -1, run: send},
+		{name: "players", help: "list online players", run: players},
+		{name: "player", args: "<name>", help: "show a player", min: 1, max: 1, run: player},
+		{name: "kick", args: "<player>", help: "kick a player", flags: []string{"reason"}, min: 1, max: 1, run: kick},
+		{name: "punish", args: "<player>", help: "punish a player", flags: []string{"reason"}, min: 1, max: 1, run: punish},
+		{name: "switch", subs: []*command{
+			{name: "now", args: "<player>", help: "switch a player's team immediately", min: 1, max: 1, run: switchNow},
+			{name: "death", args: "<player>", help: "switch a player's team on death", min: 1, max: 1, run: switchOnDeath},
+		}},
+		{name: "ban", subs: []*command{
+			{name: "list", args: "[temp|perm]", help: "list banned players", max: 1, run: bans},
+			{name: "temp", args: "<player> <duration>", help: "ban a player temporarily, e.g. 24h or 7d", flags: []string{"reason", "admin"}, min: 2, max: 2, run: banTemp},
+			{name: "perm", args: "<player>", help: "ban a player permanently", flags: []string{"reason", "admin"}, min: 1, max: 1, run: banPerm},
+			{name: "remove", args: "<player>", help: "remove a player's ban", min: 1, max: 1, run: banRemove},
+		}},
+		{name: "admin", subs: []*command{
+			{name: "list", help: "list admins", run: admins},
+			{name: "add", args: "<id64> <role> <name>", help: "add an admin", min: 3, max: -1, run: adminAdd},
+			{name: "remove", args: "<id64>", help: "remove an admin", min: 1, max: 1, run: adminRemove},
+			{name: "groups", help: "list admin roles", run: adminGroups},
+		}},
+		{name: "vip", subs: []*command{
+			{name: "list", help: "list VIPs", run: vips},
+			{name: "add", args: "<id64> <name>", help: "add a VIP", min: 2, max: -1, run: vipAdd},
+			{name: "remove", args: "<id64>", help: "remove a VIP", min: 1, max: 1, run: vipRemove},
+			{name: "slots", args: "[count]", help: "show or set the number of VIP slots", max: 1, run: vipSlots},
+		}},
+		{name: "map", help: "show the current map", run: currentMap, subs: []*command{
+			{name: "set", args: "<map>", help: "change the current map", min: 1, max: 1, run: setMap},
+			{name: "list", help: "list maps available for rotation", run: maps},
+		}},
+		{name: "rotation", help: "list the map rotation", run: rotation, subs: []*command{
+			{name: "add", args: "<map>", help: "add a map to the rotation", min: 1, max: 1, run: rotationAdd},
+			{name: "remove", args: "<map>", help: "remove a map from the rotation", min: 1, max: 1, run: rotationRemove},
+			{name: "set", args: "<map>...", help: "replace the rotation", flags: []string{"dry-run"}, min: 1, max: -1, run: rotationSet},
+		}},
+		{name: "profanity", subs: []*command{
+			{name: "list", help: "list censored words", run: profanities},
+			{name: "add", args: "<word>...", help: "censor words", min: 1, max: -1, run: profanityAdd},
+			{name: "remove", args: "<word>...", help: "stop censoring words", min: 1, max: -1, run: profanityRemove},
+		}},
+		{name: "settings", help: "list server settings", run: settings, subs: []*command{
+			{name: "set", args: "<setting> <value>", help: "update a server setting, e.g. idle_time 15m", min: 2, max: 2, run: settingsSet},
+		}},
+		{name: "votekick", subs: []*command{
+			{name: "reset", help: "reset the vote kick thresholds", run: voteKickReset},
+		}},
+		{name: "shell", help: "start an interactive shell", run: startShell},
+		{name: "state", subs: []*command{
+			{name: "apply", args: "<file>", help: "reconcile the server with a YAML or JSON state file", flags: []string{"dry-run"}, min: 1, max: 1, run: stateApply},
+		}},
+	}
+}
+
+func serverName(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := c.Name()
+	if err != nil {
+		return nil, err
+	}
+
+	return &output{
+		header: []string{"name"},
+		rows:   [][]string{{name}},
+		value:  map[string]string{"name": name},
+	}, nil
+}
+
+func slots(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	players, max, err := c.Slots()
+	if err != nil {
+		return nil, err
+	}
+
+	return &output{
+		header: []string{"players", "slots"},
+		rows:   [][]string{{strconv.Itoa(players), strconv.Itoa(max)}},
+		value:  map[string]int{"players": players, "slots": max},
+	}, nil
+}
+
+func broadcast(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.SetBroadcast(strings.Join(args, " "))
+}
+
+func messagePlayer(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	p, _, err := resolvePlayer(c, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.Message(p, strings.Join(args[1:], " "))
+}
+
+func send(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.Send(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return message(result), nil
+}
+
+func players(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := c.Players()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(players, func(i, j int) bool { return strings.ToLower(players[i].Name) < strings.ToLower(players[j].Name) })
+
+	return playerOutput(players), nil
+}
+
+func player(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := c.Player(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	o := playerOutput([]rcon.Player{p})
+	o.value = p
+
+	return o, nil
+}
+
+func kick(e *env, args []string) (*output, error) {
+	return withTarget(e, args[0], func(c *rcon.Conn, p rcon.Player) error {
+		err := online(p)
+		if err != nil {
+			return err
+		}
+
+		return c.Kick(p, e.reason)
+	})
+}
+
+func punish(e *env, args []string) (*output, error) {
+	return withTarget(e, args[0], func(c *rcon.Conn, p rcon.Player) error {
+		err := online(p)
+		if err != nil {
+			return err
+		}
+
+		return c.Punish(p, e.reason)
+	})
+}
+
+func switchNow(e *env, args []string) (*output, error) {
+	return withPlayer(e, args[0], func(c *rcon.Conn, p rcon.Player) error {
+		return c.SetSwitchTeamNow(p)
+	})
+}
+
+func switchOnDeath(e *env, args []string) (*output, error) {
+	return withPlayer(e, args[0], func(c *rcon.Conn, p rcon.Player) error {
+		return c.SetSwitchTeamOnDeath(p)
+	})
+}
+
+func bans(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	kind := ""
+	if len(args) > 0 {
+		kind = args[0]
+	}
+
+	if kind != "" && kind != "temp" && kind != "perm" {
+		return nil, fmt.Errorf("unknown ban type %q, expected temp or perm", kind)
+	}
+
+	type ban struct {
+		Type     string    `json:"type"`
+		Name     string    `json:"name"`
+		ID64     string    `json:"id64"`
+		Reason   string    `json:"reason"`
+		Issued   time.Time `json:"issued"`
+		Duration string    `json:"duration,omitempty"`
+		Admin    string    `json:"admin"`
+	}
+
+	list := []ban{}
+
+	add := func(kind string, bans []rcon.Ban) {
+		for _, b := range bans {
+			d := ""
+			if b.Duration > 0 {
+				d = b.Duration.String()
+			}
+
+			list = append(list, ban{
+				Type:     kind,
+				Name:     b.Player.Name,
+				ID64:     b.Player.ID64,
+				Reason:   b.Reason,
+				Issued:   b.Time,
+				Duration: d,
+				Admin:    b.Admin.Name,
+			})
+		}
+	}
+
+	if kind == "" || kind == "temp" {
+		temp, err := c.BannedTemporarily()
+		if err != nil {
+			return nil, err
+		}
+
+		add("temp", temp)
+	}
+
+	if kind == "" || kind == "perm" {
+		perm, err := c.BannedPermanently()
+		if err != nil {
+			return nil, err
+		}
+
+		add("perm", perm)
+	}
+
+	o := &output{
+		header: []string{"type", "name", "id64", "reason", "issued", "duration", "admin"},
+		value:  list,
+	}
+
+	for _, b := range list {
+		o.rows = append(o.rows, []string{b.Type, b.Name, b.ID64, b.Reason, b.Issued.Format(time.RFC3339), b.Duration, b.Admin})
+	}
+
+	return o, nil
+}
+
+func banTemp(e *env, args []string) (*output, error) {
+	d, err := parseDuration(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	hours := int(math.Ceil(d.Hours()))
+	if hours < 1 {
+		return nil, fmt.Errorf("temporary bans must last at least one hour")
+	}
+
+	return withTarget(e, args[0], func(c *rcon.Conn, p rcon.Player) error {
+		if !e.ask(fmt.Sprintf("Ban %s for %d hours?", p, hours)) {
+			return errCancelled
+		}
+
+		return c.BanTemporarily(p, hours, e.reason, e.admin)
+	})
+}
+
+func banPerm(e *env, args []string) (*output, error) {
+	return withTarget(e, args[0], func(c *rcon.Conn, p rcon.Player) error {
+		if !e.ask(fmt.Sprintf("Ban %s permanently?", p)) {
+			return errCancelled
+		}
+
+		return c.BanPermanently(p, e.reason, e.admin)
+	})
+}
+
+func banRemove(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	// Banned players are offline, so the argument is used as is.
+	return nil, c.BanRemove(rcon.Player{ID64: args[0]})
+}
+
+func admins(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	admins, err := c.Admins()
+	if err != nil {
+		return nil, err
+	}
+
+	o := &output{
+		header: []string{"name", "id64", "role"},
+		value:  admins,
+	}
+
+	for _, a := range admins {
+		o.rows = append(o.rows, []string{a.Name, a.ID64, a.Role})
+	}
+
+	return o, nil
+}
+
+func adminAdd(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.AdminAdd(rcon.Admin{
+		Player: rcon.Player{
+			ID64: args[0],
+			Name: strings.Join(args[2:], " "),
+		},
+		Role: args[1],
+	})
+}
+
+func adminRemove(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.AdminRemove(rcon.Admin{Player: rcon.Player{ID64: args[0]}})
+}
+
+func adminGroups(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := c.AdminGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	return listOutput("role", groups), nil
+}
+
+func vips(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	vips, err := c.VIPs()
+	if err != nil {
+		return nil, err
+	}
+
+	players := []rcon.Player{}
+	for _, v := range vips {
+		players = append(players, v.Player)
+	}
+
+	return playerOutput(players), nil
+}
+
+func vipAdd(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.VIPAdd(rcon.VIP{Player: rcon.Player{ID64: args[0], Name: strings.Join(args[1:], " ")}})
+}
+
+func vipRemove(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.VIPRemove(rcon.VIP{Player: rcon.Player{ID64: args[0]}})
+}
+
+func vipSlots(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of vip slots %q", args[0])
+		}
+
+		return nil, c.SetVIPSlots(n)
+	}
+
+	n, err := c.VIPSlots()
+	if err != nil {
+		return nil, err
+	}
+
+	return &output{
+		header: []string{"vip_slots"},
+		rows:   [][]string{{strconv.Itoa(n)}},
+		value:  map[string]int{"vip_slots": n},
+	}, nil
+}
+
+func currentMap(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := c.Map()
+	if err != nil {
+		return nil, err
+	}
+
+	o := mapOutput([]rcon.Map{m})
+	o.value = mapValues([]rcon.Map{m})[0]
+
+	return o, nil
+}
+
+func setMap(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.SetMap(rcon.MapName(args[0]))
+}
+
+func maps(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	maps, err := c.Maps()
+	if err != nil {
+		return nil, err
+	}
+
+	return mapOutput(maps), nil
+}
+
+func rotation(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	maps, err := c.Rotation()
+	if err != nil {
+		return nil, err
+	}
+
+	return mapOutput(maps), nil
+}
+
+func rotationAdd(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.RotationAdd(rcon.MapName(args[0]))
+}
+
+func rotationRemove(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.RotationRemove(rcon.MapName(args[0]))
+}
+
+func rotationSet(e *env, args []string) (*output, error) {
+	names := []rcon.MapName{}
+	for _, a := range args {
+		names = append(names, rcon.MapName(a))
+	}
+
+	return reconcile(e, rcon.State{Rotation: names})
+}
+
+func profanities(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	words, err := c.Profanities()
+	if err != nil {
+		return nil, err
+	}
+
+	return listOutput("word", words), nil
+}
+
+func profanityAdd(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.SetProfanities(args...)
+}
+
+func profanityRemove(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.UnsetProfanities(args...)
+}
+
+func settings(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := c.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds := []string{}
+	for _, t := range s.VoteKickThreshold {
+		thresholds = append(thresholds, fmt.Sprintf("%d,%d", t.Players, t.Threshold))
+	}
+
+	o := &output{
+		header: []string{"setting", "value"},
+		rows: [][]string{
+			{"idle_time", s.IdleTime.String()},
+			{"max_ping", s.MaxPing.String()},
+			{"auto_balance", strconv.FormatBool(*s.AutoBalance)},
+			{"auto_balance_threshold", strconv.Itoa(*s.AutoBalanceThreshold)},
+			{"switch_team_cooldown", s.SwitchTeamCooldown.String()},
+			{"queue_length", strconv.Itoa(*s.QueueLength)},
+			{"vip_slots", strconv.Itoa(*s.VIPSlots)},
+			{"vote_kick", strconv.FormatBool(*s.VoteKick)},
+			{"vote_kick_threshold", strings.Join(thresholds, ",")},
+		},
+	}
+
+	values := map[string]string{}
+	for _, row := range o.rows {
+		values[row[0]] = row[1]
+	}
+
+	o.value = values
+
+	return o, nil
+}
+
+func settingsSet(e *env, args []string) (*output, error) {
+	name, value := args[0], args[1]
+
+	// Thresholds are written the way the server reports them, e.g. "0,1,10,5".
+	if name == "vote_kick_threshold" {
+		pairs := []string{}
+
+		parts := strings.Split(value, ",")
+		if len(parts)%2 != 0 {
+			return nil, fmt.Errorf("invalid vote kick threshold %q, expected players,threshold pairs", value)
+		}
+
+		for i := 0; i < len(parts); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("{players: %s, threshold: %s}", parts[i], parts[i+1]))
+		}
+
+		value = "[" + strings.Join(pairs, ", ") + "]"
+	}
+
+	s := rcon.Settings{}
+
+	d := yaml.NewDecoder(bytes.NewBufferString(name + ": " + value))
+	d.KnownFields(true)
+
+	err := d.Decode(&s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid setting %s: %v", name, err)
+	}
+
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.SetSettings(s)
+}
+
+func voteKickReset(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, c.ResetVoteKickThreshold()
+}
+
+func stateApply(e *env, args []string) (*output, error) {
+	s, err := rcon.LoadState(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return reconcile(e, s)
+}
+
+// reconcile will apply a desired state, or only print the plan with -dry-run.
+func reconcile(e *env, s rcon.State) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := rcon.Reconcile(c, s, e.dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	type operation struct {
+		Kind     string `json:"kind"`
+		Resource string `json:"resource"`
+		Target   string `json:"target"`
+	}
+
+	o := &output{
+		header: []string{"kind", "resource", "target"},
+		value:  []operation{},
+	}
+
+	for _, op := range p {
+		o.rows = append(o.rows, []string{op.Kind, op.Resource, op.Target})
+		o.value = append(o.value.([]operation), operation{op.Kind, op.Resource, op.Target})
+	}
+
+	return o, nil
+}
+
+// withPlayer will resolve a player and run fn with it.
+func withPlayer(e *env, arg string, fn func(c *rcon.Conn, p rcon.Player) error) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	p, _, err := resolvePlayer(c, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, fn(c, p)
+}
+
+// withTarget will resolve the target of a destructive command and run fn with it. A partial name
+// could match the wrong player, so it must be confirmed interactively, and is rejected otherwise.
+func withTarget(e *env, arg string, fn func(c *rcon.Conn, p rcon.Player) error) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	p, exact, err := resolvePlayer(c, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exact {
+		if e.confirm == nil {
+			return nil, fmt.Errorf("%q only partially matches %s, use their full name or ID64", arg, p)
+		}
+
+		if !e.confirm(fmt.Sprintf("%q matches %s, is this the right player?", arg, p)) {
+			return nil, errCancelled
+		}
+	}
+
+	return nil, fn(c, p)
+}
+
+// resolvePlayer will find an online player by ID64 or name, reporting whether the name matched
+// exactly. Offline players are only accepted by ID64, since commands targeting them need one.
+func resolvePlayer(c *rcon.Conn, arg string) (rcon.Player, bool, error) {
+	players, err := c.Players()
+	if err != nil {
+		return rcon.Player{}, false, err
+	}
+
+	for _, p := range players {
+		if p.ID64 == arg || p.Name == arg {
+			return p, true, nil
+		}
+	}
+
+	matches := []rcon.Player{}
+	for _, p := range players {
+		if strings.Contains(strings.ToLower(p.Name), strings.ToLower(arg)) {
+			matches = append(matches, p)
+		}
+	}
+
+	switch {
+	case len(matches) == 1:
+		return matches[0], false, nil
+	case len(matches) > 1:
+		return rcon.Player{}, false, fmt.Errorf("%q matches %d players, use their ID64", arg, len(matches))
+	case isID64(arg):
+		return rcon.Player{ID64: arg}, true, nil
+	default:
+		return rcon.Player{}, false, fmt.Errorf("player %q is not online", arg)
+	}
+}
+
+// online will return an error for an offline player, resolved by ID64 alone, as commands targeting
+// players by name cannot reach them.
+func online(p rcon.Player) error {
+	if p.Name == "" {
+		return fmt.Errorf("player %s is not online", p.ID64)
+	}
+
+	return nil
+}
+
+func isID64(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 64)
+	return err == nil
+}
+
+// parseDuration will parse a duration, additionally accepting days, e.g. "7d".
+func parseDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	return d, nil
+}
+
+func playerOutput(players []rcon.Player) *output {
+	o := &output{
+		header: []string{"name", "id64"},
+		value:  players,
+	}
+
+	for _, p := range players {
+		o.rows = append(o.rows, []string{p.Name, p.ID64})
+	}
+
+	return o
+}
+
+type mapValue struct {
+	Name     rcon.MapName `json:"name"`
+	Location string       `json:"location"`
+	Type     string       `json:"type"`
+	Side     string       `json:"side,omitempty"`
+}
+
+func mapValues(maps []rcon.Map) []mapValue {
+	values := []mapValue{}
+	for _, m := range maps {
+		values = append(values, mapValue{m.MapName, m.Location, m.Type, m.Side})
+	}
+
+	return values
+}
+
+func mapOutput(maps []rcon.Map) *output {
+	o := &output{
+		header: []string{"name", "location", "type", "side"},
+		value:  mapValues(maps),
+	}
+
+	for _, m := range maps {
+		o.rows = append(o.rows, []string{m.MapName.String(), m.Location, m.Type, m.Side})
+	}
+
+	return o
+}
+
+func listOutput(header string, items []string) *output {
+	o := &output{
+		header: []string{header},
+		value:  items,
+	}
+
+	for _, item := range items {
+		o.rows = append(o.rows, []string{item})
+	}
+
+	return o
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// errInterrupt is returned by readLine when the line is abandoned with Ctrl-C.
+var errInterrupt = errors.New("interrupted")
+
+// editor represents a minimal line editor for a terminal in raw mode, supporting cursor movement,
+// history and tab completion.
+type editor struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	prompt  string
+	history []string
+
+	// complete returns the candidates for the word ending at the cursor of line.
+	complete func(line string) []string
+}
+
+// readLine will read a single line, editing it in place as keys are pressed.
+func (ed *editor) readLine() (string, error) {
+	line := []rune{}
+	pos := 0
+	hist := len(ed.history)
+	draft := ""
+
+	ed.redraw(line, pos)
+
+	for {
+		r, _, err := ed.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(ed.out, "\r\n")
+			return string(line), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(ed.out, "^C\r\n")
+			return "", errInterrupt
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Fprint(ed.out, "\r\n")
+				return "", io.EOF
+			}
+		case 127, 8: // Backspace
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case 1: // Ctrl-A
+			pos = 0
+		case 5: // Ctrl-E
+			pos = len(line)
+		case 21: // Ctrl-U
+			line = line[pos:]
+			pos = 0
+		case 23: // Ctrl-W
+			start := pos
+			for start > 0 && line[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && line[start-1] != ' ' {
+				start--
+			}
+
+			line = append(line[:start], line[pos:]...)
+			pos = start
+		case '\t':
+			line, pos = ed.completeLine(line, pos)
+		case 27: // Escape sequences for arrows, home and end.
+			seq := ed.escape()
+
+			switch seq {
+			case "[A", "OA":
+				if hist > 0 {
+					if hist == len(ed.history) {
+						draft = string(line)
+					}
+
+					hist--
+					line = []rune(ed.history[hist])
+					pos = len(line)
+				}
+			case "[B", "OB":
+				if hist < len(ed.history) {
+					hist++
+
+					if hist == len(ed.history) {
+						line = []rune(draft)
+					} else {
+						line = []rune(ed.history[hist])
+					}
+
+					pos = len(line)
+				}
+			case "[C", "OC":
+				if pos < len(line) {
+					pos++
+				}
+			case "[D", "OD":
+				if pos > 0 {
+					pos--
+				}
+			case "[H", "OH", "[1~":
+				pos = 0
+			case "[F", "OF", "[4~":
+				pos = len(line)
+			case "[3~": // Delete
+				if pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+				}
+			}
+		default:
+			if !unicode.IsPrint(r) {
+				continue
+			}
+
+			line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+			pos++
+		}
+
+		ed.redraw(line, pos)
+	}
+}
+
+// escape will read the remainder of an escape sequence, e.g. "[A" for the up arrow.
+func (ed *editor) escape() string {
+	seq := ""
+
+	for {
+		r, _, err := ed.in.ReadRune()
+		if err != nil {
+			return seq
+		}
+
+		seq += string(r)
+
+		// Sequences end with a letter or a tilde, after the leading "[" or "O".
+		if len(seq) > 1 && (unicode.IsLetter(r) || r == '~') {
+			return seq
+		}
+
+		if len(seq) == 1 && r != '[' && r != 'O' {
+			return seq
+		}
+	}
+}
+
+// completeLine will complete the word at the cursor, inserting the common prefix of every
+// candidate, or listing them when the prefix cannot be extended.
+func (ed *editor) completeLine(line []rune, pos int) ([]rune, int) {
+	if ed.complete == nil {
+		return line, pos
+	}
+
+	before := string(line[:pos])
+
+	start := strings.LastIndex(before, " ") + 1
+	word := before[start:]
+
+	candidates := ed.complete(before)
+	if len(candidates) == 0 {
+		return line, pos
+	}
+
+	prefix := commonPrefix(candidates)
+
+	if len(candidates) == 1 {
+		prefix += " "
+	}
+
+	if len(prefix) > len(word) {
+		insert := []rune(prefix[len(word):])
+		line = append(line[:pos], append(insert, line[pos:]...)...)
+
+		return line, pos + len(insert)
+	}
+
+	fmt.Fprintf(ed.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+
+	return line, pos
+}
+
+// redraw will rewrite the prompt and line, placing the cursor at pos.
+func (ed *editor) redraw(line []rune, pos int) {
+	fmt.Fprintf(ed.out, "\r%s%s\x1b[K", ed.prompt, string(line))
+
+	if back := len(line) - pos; back > 0 {
+		fmt.Fprintf(ed.out, "\x1b[%dD", back)
+	}
+}
+
+func commonPrefix(s []string) string {
+	prefix := s[0]
+
+	for _, v := range s[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return prefix
+}
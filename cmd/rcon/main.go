@@ -0,0 +1,282 @@
+// Command rcon is a command line client for Hell Let Loose RCON servers.
+//
+// The server address and password are read from the -addr and -password flags, the RCON_ADDR and
+// RCON_PASSWORD environment variables, or a profile file selected with -profile or RCON_PROFILE.
+//
+//	rcon players -o json
+//	rcon ban temp 76561198000000000 24h -reason "teamkilling"
+//	rcon rotation set foy_warfare kursk_warfare
+//	rcon vip add 76561198000000000 "Some Player"
+//	rcon shell
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// globals represents the flags shared by every command.
+type globals struct {
+	addr     string
+	password string
+	profile  string
+	profiles string
+	format   string
+
+	// Options of individual commands.
+	reason string
+	admin  string
+	dryRun bool
+}
+
+// env represents the state shared by the commands of a single invocation.
+type env struct {
+	*globals
+
+	out  io.Writer
+	conn *rcon.Conn
+
+	// confirm asks the user a yes or no question, or is nil to assume yes.
+	confirm func(question string) bool
+}
+
+// command represents a subcommand of the client. A command with subcommands may still be run
+// itself when the next argument does not name one of them.
+type command struct {
+	name  string
+	args  string // Usage of the positional arguments.
+	help  string
+	flags []string // Names of the options accepted, e.g. "reason".
+
+	min, max int // Number of positional arguments accepted, max < 0 for unbounded.
+
+	run  func(e *env, args []string) (*output, error)
+	subs []*command
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rcon: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	g := &globals{}
+
+	fs := flag.NewFlagSet("rcon", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { usage(stderr, "", commands()) }
+
+	g.registerGlobals(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+
+		return err
+	}
+
+	e := &env{
+		globals: g,
+		out:     stdout,
+	}
+	defer e.close()
+
+	return e.exec(commands(), fs.Args())
+}
+
+// exec will find the command named by args and run it.
+func (e *env) exec(cmds []*command, args []string) error {
+	if len(args) == 0 || args[0] == "help" {
+		usage(e.out, "", cmds)
+		return nil
+	}
+
+	cmd, args, path := find(cmds, args)
+	if cmd == nil {
+		return fmt.Errorf("unknown command %q, see 'rcon help'", strings.Join(path, " "))
+	}
+
+	if cmd.run == nil {
+		usage(e.out, strings.Join(path, " "), cmd.subs)
+		return nil
+	}
+
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.SetOutput(e.out)
+	fs.Usage = func() {
+		fmt.Fprintf(e.out, "usage: rcon %s %s\n\n%s\n", strings.Join(path, " "), cmd.args, cmd.help)
+		fs.PrintDefaults()
+	}
+
+	e.register(fs, cmd.flags)
+
+	args, err := parse(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+
+		return err
+	}
+
+	if len(args) < cmd.min || (cmd.max >= 0 && len(args) > cmd.max) {
+		return fmt.Errorf("usage: rcon %s %s", strings.Join(path, " "), cmd.args)
+	}
+
+	o, err := cmd.run(e, args)
+	if err != nil {
+		return err
+	}
+
+	return render(e.out, e.format, o)
+}
+
+// find will walk the command tree along args, returning the deepest command matched, the
+// remaining arguments and the path of names walked.
+func find(cmds []*command, args []string) (*command, []string, []string) {
+	var found *command
+	path := []string{}
+
+	for len(args) > 0 {
+		var next *command
+
+		for _, c := range cmds {
+			if c.name == args[0] {
+				next = c
+				break
+			}
+		}
+
+		if next == nil {
+			if found == nil {
+				path = append(path, args[0])
+			}
+
+			break
+		}
+
+		found = next
+		path = append(path, args[0])
+		args = args[1:]
+		cmds = next.subs
+	}
+
+	return found, args, path
+}
+
+// parse will parse flags interleaved with positional arguments, returning the positional arguments.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		rest := fs.Args()
+
+		// Everything after a "--" terminator is positional.
+		if len(args) > len(rest) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// registerGlobals will add the connection flags and the output format to fs.
+func (g *globals) registerGlobals(fs *flag.FlagSet) {
+	fs.StringVar(&g.addr, "addr", "", "server address, e.g. 127.0.0.1:7779 (RCON_ADDR)")
+	fs.StringVar(&g.password, "password", "", "server password (RCON_PASSWORD)")
+	fs.StringVar(&g.profile, "profile", "", "profile name in the profile file (RCON_PROFILE)")
+	fs.StringVar(&g.profiles, "profiles", "", "profile file, default "+defaultProfilePath()+" (RCON_PROFILES)")
+	fs.StringVar(&g.format, "o", formatTable, "output format: table, json or csv")
+}
+
+// register will add the output format and the named command options to fs.
+func (g *globals) register(fs *flag.FlagSet, options []string) {
+	fs.StringVar(&g.format, "o", g.format, "output format: table, json or csv")
+
+	for _, name := range options {
+		switch name {
+		case "reason":
+			fs.StringVar(&g.reason, "reason", "", "reason shown to the player")
+		case "admin":
+			fs.StringVar(&g.admin, "admin", first(os.Getenv("RCON_ADMIN"), os.Getenv("USER")), "admin name recorded with the ban (RCON_ADMIN)")
+		case "dry-run":
+			fs.BoolVar(&g.dryRun, "dry-run", false, "print the changes without applying them")
+		}
+	}
+}
+
+// dial returns the Conn of the invocation, connecting on first use.
+func (e *env) dial() (*rcon.Conn, error) {
+	if e.conn != nil {
+		return e.conn, nil
+	}
+
+	p, err := resolveProfile(e.globals)
+	if err != nil {
+		return nil, err
+	}
+
+	e.conn, err = rcon.New(p.Addr, p.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", p.Addr, err)
+	}
+
+	return e.conn, nil
+}
+
+// ask will confirm an action with the user when running interactively.
+func (e *env) ask(question string) bool {
+	if e.confirm == nil {
+		return true
+	}
+
+	return e.confirm(question)
+}
+
+func (e *env) close() {
+	if e.conn != nil {
+		e.conn.Close()
+	}
+}
+
+// usage will print the command tree below prefix.
+func usage(w io.Writer, prefix string, cmds []*command) {
+	fmt.Fprintln(w, "usage: rcon [-addr host:port] [-password secret] [-profile name] [-o table|json|csv] <command>")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	var walk func(prefix string, cmds []*command)
+	walk = func(prefix string, cmds []*command) {
+		for _, c := range cmds {
+			name := strings.TrimSpace(prefix + " " + c.name)
+
+			if c.run != nil {
+				fmt.Fprintf(w, "  %-40s %s\n", strings.TrimSpace(name+" "+c.args), c.help)
+			}
+
+			walk(name, c.subs)
+		}
+	}
+
+	walk(prefix, cmds)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats.
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// output represents the result of a command, rendered as a table or CSV from its rows, or as JSON
+// from its value.
+type output struct {
+	header []string
+	rows   [][]string
+	value  interface{}
+}
+
+// message returns an output holding a single line of text.
+func message(s string) *output {
+	return &output{
+		header: []string{"result"},
+		rows:   [][]string{{s}},
+		value:  map[string]string{"result": s},
+	}
+}
+
+// render will write o to w in the given format. A nil output renders nothing.
+func render(w io.Writer, format string, o *output) error {
+	if o == nil {
+		return nil
+	}
+
+	switch format {
+	case formatJSON:
+		b, err := json.MarshalIndent(o.value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode output: %v", err)
+		}
+
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case formatCSV:
+		cw := csv.NewWriter(w)
+
+		err := cw.Write(o.header)
+		if err != nil {
+			return err
+		}
+
+		err = cw.WriteAll(o.rows)
+		if err != nil {
+			return err
+		}
+
+		return cw.Error()
+	case formatTable, "":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+		if len(o.header) > 1 {
+			fmt.Fprintln(tw, strings.ToUpper(strings.Join(o.header, "\t")))
+		}
+
+		for _, row := range o.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown output format %q, expected %s, %s or %s", format, formatTable, formatJSON, formatCSV)
+	}
+}
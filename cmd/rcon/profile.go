@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// profile represents the connection details of a server.
+type profile struct {
+	Addr     string `yaml:"addr"`
+	Password string `yaml:"password"`
+}
+
+// profiles represents a profile file, e.g.
+//
+//	default: eu-1
+//	profiles:
+//	  eu-1:
+//	    addr: 127.0.0.1:7779
+//	    password: secret
+type profiles struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+// defaultProfilePath returns the default location of the profile file.
+func defaultProfilePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "rcon", "profiles.yaml")
+}
+
+// resolveProfile will return the connection details from flags, then environment variables, and
+// finally the named (or default) profile of the profile file.
+func resolveProfile(g *globals) (profile, error) {
+	p := profile{
+		Addr:     first(g.addr, os.Getenv("RCON_ADDR")),
+		Password: first(g.password, os.Getenv("RCON_PASSWORD")),
+	}
+
+	if p.Addr != "" && p.Password != "" {
+		return p, nil
+	}
+
+	name := first(g.profile, os.Getenv("RCON_PROFILE"))
+	path := first(g.profiles, os.Getenv("RCON_PROFILES"), defaultProfilePath())
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && name == "" {
+			return profile{}, fmt.Errorf("no server address and password, set -addr and -password, RCON_ADDR and RCON_PASSWORD, or a profile in %s", path)
+		}
+
+		return profile{}, fmt.Errorf("failed to read profiles: %v", err)
+	}
+
+	ps := profiles{}
+
+	err = yaml.Unmarshal(b, &ps)
+	if err != nil {
+		return profile{}, fmt.Errorf("failed to parse profiles in %s: %v", path, err)
+	}
+
+	name = first(name, ps.Default)
+
+	found, ok := ps.Profiles[name]
+	if !ok {
+		return profile{}, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+
+	p.Addr = first(p.Addr, found.Addr)
+	p.Password = first(p.Password, found.Password)
+
+	return p, nil
+}
+
+// first returns the first non-empty string.
+func first(s ...string) string {
+	for _, v := range s {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,483 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// historySize is the number of lines of history kept.
+const historySize = 1000
+
+// playerCacheTTL is how long online players are cached for completion.
+const playerCacheTTL = 10 * time.Second
+
+// settingNames are the settings accepted by "settings set".
+var settingNames = []string{
+	"idle_time",
+	"max_ping",
+	"auto_balance",
+	"auto_balance_threshold",
+	"switch_team_cooldown",
+	"queue_length",
+	"vip_slots",
+	"vote_kick",
+	"vote_kick_threshold",
+}
+
+// shell represents an interactive session over a single Conn.
+type shell struct {
+	*env
+
+	conn     *rcon.Conn
+	in       *bufio.Reader
+	terminal bool
+	editor   *editor
+	raw      bool // Whether lines are sent to the server verbatim.
+
+	historyFile string
+
+	players     []rcon.Player
+	playersTime time.Time
+	maps        []string
+	roles       []string
+}
+
+// builtins are the commands handled by the shell itself.
+var builtins = []*command{
+	{name: "raw", help: "toggle sending lines to the server verbatim"},
+	{name: "exit", help: "leave the shell"},
+}
+
+func startShell(e *env, args []string) (*output, error) {
+	c, err := e.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	sh := &shell{
+		env:         e,
+		conn:        c,
+		in:          bufio.NewReader(os.Stdin),
+		terminal:    isTerminal(int(os.Stdin.Fd())),
+		historyFile: first(os.Getenv("RCON_HISTORY"), historyPath()),
+	}
+
+	sh.editor = &editor{
+		in:       sh.in,
+		out:      os.Stdout,
+		history:  loadHistory(sh.historyFile),
+		complete: sh.complete,
+	}
+
+	e.confirm = sh.confirm
+
+	name, err := c.Name()
+	if err == nil && sh.terminal {
+		fmt.Fprintf(e.out, "connected to %s, type 'help' for commands or 'exit' to leave\n", name)
+	}
+
+	for {
+		line, err := sh.read(sh.prompt())
+		if err == errInterrupt {
+			continue
+		}
+
+		if err == io.EOF {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		sh.remember(line)
+
+		if !sh.eval(line) {
+			return nil, nil
+		}
+	}
+}
+
+// eval will run a single line, returning false when the shell should exit.
+func (sh *shell) eval(line string) bool {
+	if sh.raw {
+		if line == "raw" || line == "exit" {
+			sh.raw = false
+			return true
+		}
+
+		result, err := sh.conn.Send(line)
+		if err != nil {
+			fmt.Fprintf(sh.out, "error: %v\n", err)
+			return true
+		}
+
+		fmt.Fprintln(sh.out, result)
+
+		return true
+	}
+
+	args, err := splitWords(line)
+	if err != nil {
+		fmt.Fprintf(sh.out, "error: %v\n", err)
+		return true
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return false
+	case "raw":
+		sh.raw = true
+		fmt.Fprintln(sh.out, "raw mode, lines are sent to the server verbatim until 'raw' or 'exit'")
+		return true
+	case "shell":
+		return true
+	case "help":
+		usage(sh.out, "", commands())
+		for _, b := range builtins {
+			fmt.Fprintf(sh.out, "  %-40s %s\n", b.name, b.help)
+		}
+
+		return true
+	}
+
+	err = sh.exec(commands(), args)
+	if err != nil {
+		fmt.Fprintf(sh.out, "error: %v\n", err)
+	}
+
+	return true
+}
+
+func (sh *shell) prompt() string {
+	if sh.raw {
+		return "rcon (raw)> "
+	}
+
+	return "rcon> "
+}
+
+// read will read a line with the editor on a terminal, or plainly from other input.
+func (sh *shell) read(prompt string) (string, error) {
+	if !sh.terminal {
+		line, err := sh.in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			return line, nil
+		}
+
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	restore, err := makeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer restore()
+
+	sh.editor.prompt = prompt
+
+	return sh.editor.readLine()
+}
+
+// confirm will ask a yes or no question, defaulting to no.
+func (sh *shell) confirm(question string) bool {
+	line, err := sh.read(question + " [y/N] ")
+	if err != nil {
+		return false
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+
+	return answer == "y" || answer == "yes"
+}
+
+// remember will add a line to the history, persisting it to the history file.
+func (sh *shell) remember(line string) {
+	h := sh.editor.history
+	if len(h) > 0 && h[len(h)-1] == line {
+		return
+	}
+
+	h = append(h, line)
+	if len(h) > historySize {
+		h = h[len(h)-historySize:]
+	}
+
+	sh.editor.history = h
+
+	if sh.historyFile == "" {
+		return
+	}
+
+	err := os.MkdirAll(filepath.Dir(sh.historyFile), 0700)
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(sh.historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, line)
+}
+
+// complete returns the candidates for the word ending the line, based on the command tree and
+// the argument names in its usage.
+func (sh *shell) complete(line string) []string {
+	if sh.raw {
+		return nil
+	}
+
+	words, _ := splitWords(line)
+
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	cmds := append(commands(), builtins...)
+
+	var cmd *command
+	for len(words) > 0 {
+		var next *command
+
+		for _, c := range cmds {
+			if c.name == words[0] {
+				next = c
+				break
+			}
+		}
+
+		if next == nil {
+			break
+		}
+
+		cmd = next
+		cmds = next.subs
+		words = words[1:]
+	}
+
+	candidates := []string{}
+
+	positional := countPositional(words)
+	if positional == 0 {
+		for _, c := range cmds {
+			candidates = append(candidates, c.name)
+		}
+	}
+
+	if cmd != nil && cmd.run != nil {
+		candidates = append(candidates, sh.arguments(argumentName(cmd.args, positional))...)
+	}
+
+	matches := []string{}
+	for _, c := range candidates {
+		if strings.Contains(c, " ") {
+			c = `"` + c + `"`
+		}
+
+		if strings.HasPrefix(strings.ToLower(c), strings.ToLower(current)) {
+			matches = append(matches, c)
+		}
+	}
+
+	sort.Strings(matches)
+
+	return matches
+}
+
+// arguments returns the candidates for an argument name from a command usage.
+func (sh *shell) arguments(name string) []string {
+	switch name {
+	case "player", "name":
+		values := []string{}
+		for _, p := range sh.onlinePlayers() {
+			values = append(values, p.Name, p.ID64)
+		}
+
+		return values
+	case "id64":
+		values := []string{}
+		for _, p := range sh.onlinePlayers() {
+			values = append(values, p.ID64)
+		}
+
+		return values
+	case "map":
+		if sh.maps == nil {
+			maps, err := sh.conn.Maps()
+			if err != nil {
+				return nil
+			}
+
+			sh.maps = []string{}
+			for _, m := range maps {
+				sh.maps = append(sh.maps, m.MapName.String())
+			}
+		}
+
+		return sh.maps
+	case "role":
+		if sh.roles == nil {
+			roles, err := sh.conn.AdminGroups()
+			if err != nil {
+				return nil
+			}
+
+			sh.roles = roles
+		}
+
+		return sh.roles
+	case "setting":
+		return settingNames
+	case "temp|perm":
+		return []string{"temp", "perm"}
+	}
+
+	return nil
+}
+
+func (sh *shell) onlinePlayers() []rcon.Player {
+	if time.Since(sh.playersTime) < playerCacheTTL {
+		return sh.players
+	}
+
+	players, err := sh.conn.Players()
+	if err != nil {
+		return sh.players
+	}
+
+	sh.players = players
+	sh.playersTime = time.Now()
+
+	return players
+}
+
+// countPositional returns the number of positional arguments in words, skipping flags and their values.
+func countPositional(words []string) int {
+	n := 0
+
+	for i := 0; i < len(words); i++ {
+		if !strings.HasPrefix(words[i], "-") {
+			n++
+			continue
+		}
+
+		// Boolean flags take no value.
+		if words[i] != "-dry-run" && !strings.Contains(words[i], "=") {
+			i++
+		}
+	}
+
+	return n
+}
+
+// argumentName returns the name of the i-th argument of a usage string, e.g. "player" for
+// "<player> <duration>" and 0. A trailing "..." repeats the last argument.
+func argumentName(usage string, i int) string {
+	fields := strings.Fields(usage)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	if i >= len(fields) {
+		last := fields[len(fields)-1]
+		if !strings.HasSuffix(last, "...") {
+			return ""
+		}
+
+		i = len(fields) - 1
+	}
+
+	return strings.Trim(fields[i], "<>[].")
+}
+
+// splitWords will split a line into words, honouring single and double quotes and backslash escapes.
+// An unterminated quote is reported as an error alongside the words read so far.
+func splitWords(line string) ([]string, error) {
+	words := []string{}
+
+	word := strings.Builder{}
+	inWord := false
+	quote := rune(0)
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	if quote != 0 {
+		return words, fmt.Errorf("unterminated quote")
+	}
+
+	return words, nil
+}
+
+// historyPath returns the default location of the history file.
+func historyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "rcon", "history")
+}
+
+func loadHistory(path string) []string {
+	b, err := os.ReadFile(path)
+	if err != nil || len(b) == 0 {
+		return []string{}
+	}
+
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	if len(lines) > historySize {
+		lines = lines[len(lines)-historySize:]
+	}
+
+	return lines
+}
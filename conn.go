@@ -6,15 +6,25 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Conn represents a connection to a HLL RCON server. A Conn supports multiple thread-safe
 // connections.
 type Conn struct {
-	active  int  // Estimated active connections.
-	closing bool // Closing fall to stop polling.
+	active  int64 // Estimated active connections.
+	closing bool  // Closing fall to stop polling.
 
 	pool sync.Pool // Collection of sessions.
+
+	mu          sync.RWMutex
+	observers   []func(CommandResult)
+	middlewares []Middleware
+	handler     Handler // The middlewares wrapped around the pool, rebuilt by Use.
+
+	parent   *Conn  // The Conn a handle returned by As shares its sessions with.
+	operator string // The operator of a handle returned by As.
 }
 
 type session struct {
@@ -29,6 +39,7 @@ var ErrResultFailed = errors.New("got FAIL response from server")
 // New returns a new HLL RCON client to set/get server parameters.
 func New(addr string, password string) (*Conn, error) {
 	c := &Conn{}
+	c.handler = c.sendSession
 
 	c.pool = sync.Pool{
 		New: func() interface{} {
@@ -58,7 +69,7 @@ func New(addr string, password string) (*Conn, error) {
 			}
 
 			if !c.closing {
-				c.active++
+				atomic.AddInt64(&c.active, 1)
 			}
 
 			return s
@@ -77,9 +88,13 @@ func New(addr string, password string) (*Conn, error) {
 
 // Close will attempt to close all active connections held by the internal pool.
 func (c *Conn) Close() error {
+	if c.parent != nil {
+		return c.parent.Close()
+	}
+
 	c.closing = true
 
-	for i := 0; i < c.active; i++ {
+	for i := int64(0); i < atomic.LoadInt64(&c.active); i++ {
 		switch s := c.pool.Get().(type) {
 		case error:
 			return s
@@ -101,6 +116,30 @@ func (c *Conn) Send(cmds ...string) (string, error) {
 }
 
 func (c *Conn) send(cmds ...string) (string, error) {
+	root := c.root()
+
+	root.mu.RLock()
+	h := root.handler
+	root.mu.RUnlock()
+
+	return h(Request{Cmds: cmds, Operator: c.operator})
+}
+
+func (c *Conn) sendSession(r Request) (string, error) {
+	start := time.Now()
+
+	result, err := c.sendPool(r.Cmds)
+
+	c.observe(CommandResult{
+		Name:     CommandName(r.Cmds),
+		Duration: time.Since(start),
+		Err:      err,
+	})
+
+	return result, err
+}
+
+func (c *Conn) sendPool(cmds []string) (string, error) {
 	switch s := c.pool.Get().(type) {
 	case error:
 		return "", s
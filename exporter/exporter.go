@@ -0,0 +1,409 @@
+// Package exporter exposes the state of a server and the health of its Conn as Prometheus metrics.
+//
+// Server metrics are sampled at an interval, log metrics are counted from a LogStream subscription,
+// and client metrics are observed from every command sent by the Conn. Metrics are written in the
+// Prometheus text exposition format, so no client library is required.
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/ratelimit"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the command latency histogram.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Exporter represents the metrics of a single Conn.
+type Exporter struct {
+	Conn *rcon.Conn
+
+	// Limiter, when set, adds the queue depths and delays of the Conn's rate limiter.
+	Limiter *ratelimit.Limiter
+
+	// OnError is called with errors from samples in Run.
+	OnError func(error)
+
+	mu sync.Mutex
+
+	sampled bool
+	up      bool
+	last    sample
+
+	events  map[rcon.EventKind]uint64
+	recent  map[rcon.EventKind][]time.Time // Events within the last minute.
+	buckets []float64
+	latency map[string]*histogram
+	errors  map[[2]string]uint64 // Keyed by command and error type.
+
+	now func() time.Time
+}
+
+// sample represents the state of a server read by Sample.
+type sample struct {
+	state    rcon.GameState
+	used     int
+	slots    int
+	capacity int // The maximum number of players in the join queue.
+	vips     int
+}
+
+type histogram struct {
+	counts []uint64 // Cumulative counts per bucket.
+	count  uint64
+	sum    float64
+}
+
+// Log event kinds counted by the Exporter.
+var counted = []rcon.EventKind{rcon.EventKill, rcon.EventTeamKill, rcon.EventChat}
+
+// New returns an Exporter for a Conn, observing every command it sends.
+func New(c *rcon.Conn) *Exporter {
+	e := &Exporter{
+		Conn:    c,
+		events:  map[rcon.EventKind]uint64{},
+		recent:  map[rcon.EventKind][]time.Time{},
+		buckets: DefaultBuckets,
+		latency: map[string]*histogram{},
+		errors:  map[[2]string]uint64{},
+		now:     time.Now,
+	}
+
+	c.Observe(e.observe)
+
+	return e
+}
+
+// Run will sample the server at an interval until the context is cancelled.
+func (e *Exporter) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		err := e.Sample()
+		if err != nil && e.OnError != nil {
+			e.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Consume will count the Events of a channel, e.g. a LogStream subscription, until it is closed or
+// the context is cancelled.
+func (e *Exporter) Consume(ctx context.Context, events <-chan rcon.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			e.Record(ev)
+		}
+	}
+}
+
+// Record will count a single log Event.
+func (e *Exporter) Record(ev rcon.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.events[ev.Kind]++
+	e.recent[ev.Kind] = append(e.trim(ev.Kind), e.now())
+}
+
+// trim will drop the recent Events of a kind older than a minute.
+func (e *Exporter) trim(kind rcon.EventKind) []time.Time {
+	cutoff := e.now().Add(-time.Minute)
+
+	recent := e.recent[kind]
+	for len(recent) > 0 && recent[0].Before(cutoff) {
+		recent = recent[1:]
+	}
+
+	return recent
+}
+
+// Sample will read the current state of the server. Metrics of a failed sample keep their
+// previous value, and hll_up is set to 0.
+func (e *Exporter) Sample() error {
+	s := sample{}
+
+	var err error
+
+	s.state, err = e.Conn.GameState()
+
+	if err == nil {
+		s.used, s.slots, err = e.Conn.Slots()
+	}
+
+	if err == nil {
+		s.capacity, err = e.Conn.QueueLength()
+	}
+
+	if err == nil {
+		var list []rcon.VIP
+
+		list, err = e.Conn.VIPs()
+		s.vips = len(list)
+	}
+
+	return e.update(s, err)
+}
+
+// update will keep a sample of the server, or mark it down when the sample failed.
+func (e *Exporter) update(s sample, err error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.up = err == nil
+	if err != nil {
+		return fmt.Errorf("failed to sample server: %w", err)
+	}
+
+	e.sampled = true
+	e.last = s
+
+	return nil
+}
+
+func (e *Exporter) observe(r rcon.CommandResult) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	h, ok := e.latency[r.Name]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(e.buckets))}
+		e.latency[r.Name] = h
+	}
+
+	seconds := r.Duration.Seconds()
+
+	for i, bound := range e.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+
+	h.count++
+	h.sum += seconds
+
+	if r.Err != nil {
+		e.errors[[2]string{r.Name, errorType(r.Err)}]++
+	}
+}
+
+// errorType will classify a command error for the rcon_command_errors_total metric.
+func errorType(err error) string {
+	var ne net.Error
+
+	switch {
+	case errors.Is(err, rcon.ErrResultFailed):
+		return "failed"
+	case errors.As(err, &ne) && ne.Timeout():
+		return "timeout"
+	case errors.As(err, &ne), errors.Is(err, io.EOF):
+		return "network"
+	default:
+		return "other"
+	}
+}
+
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	e.WriteTo(w)
+}
+
+// WriteTo will write every metric in the Prometheus text exposition format.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b := &bytes.Buffer{}
+	m := &metrics{b: b}
+
+	m.family("hll_up", "gauge", "Whether the last sample of the server succeeded.")
+	m.sample("hll_up", nil, boolean(e.up))
+
+	if e.sampled {
+		m.family("hll_players", "gauge", "Players per team.")
+		s := e.last
+
+		m.sample("hll_players", labels{"team", "allies"}, float64(s.state.AlliedPlayers))
+		m.sample("hll_players", labels{"team", "axis"}, float64(s.state.AxisPlayers))
+
+		m.family("hll_slots_used", "gauge", "Occupied player slots.")
+		m.sample("hll_slots_used", nil, float64(s.used))
+
+		m.family("hll_slots", "gauge", "Total player slots.")
+		m.sample("hll_slots", nil, float64(s.slots))
+
+		// The server reports the size of the join queue, but not how many players are waiting in it.
+		m.family("hll_queue_capacity", "gauge", "Maximum number of players in the join queue.")
+		m.sample("hll_queue_capacity", nil, float64(s.capacity))
+
+		m.family("hll_vips", "gauge", "VIPs registered on the server.")
+		m.sample("hll_vips", nil, float64(s.vips))
+
+		m.family("hll_score", "gauge", "Score of the current match per team.")
+		m.sample("hll_score", labels{"team", "allies"}, float64(s.state.AlliedScore))
+		m.sample("hll_score", labels{"team", "axis"}, float64(s.state.AxisScore))
+
+		m.family("hll_match_remaining_seconds", "gauge", "Time remaining in the current match.")
+		m.sample("hll_match_remaining_seconds", nil, s.state.Remaining.Seconds())
+
+		m.family("hll_match_info", "gauge", "Current and next map, always 1.")
+		m.sample("hll_match_info", labels{"map", string(s.state.Map.MapName), "next_map", string(s.state.NextMap.MapName)}, 1)
+	}
+
+	names := map[rcon.EventKind]string{
+		rcon.EventKill:     "hll_kills",
+		rcon.EventTeamKill: "hll_teamkills",
+		rcon.EventChat:     "hll_chat_messages",
+	}
+
+	for _, kind := range counted {
+		name := names[kind]
+
+		m.family(name+"_total", "counter", fmt.Sprintf("Log events of kind %s.", kind))
+		m.sample(name+"_total", nil, float64(e.events[kind]))
+
+		recent := e.trim(kind)
+		e.recent[kind] = recent
+
+		m.family(name+"_per_minute", "gauge", fmt.Sprintf("Log events of kind %s in the last minute.", kind))
+		m.sample(name+"_per_minute", nil, float64(len(recent)))
+	}
+
+	commands := []string{}
+	for name := range e.latency {
+		commands = append(commands, name)
+	}
+	sort.Strings(commands)
+
+	m.family("rcon_command_duration_seconds", "histogram", "Latency of commands sent to the server.")
+
+	for _, name := range commands {
+		h := e.latency[name]
+
+		for i, bound := range e.buckets {
+			m.sample("rcon_command_duration_seconds_bucket", labels{"command", name, "le", fmt.Sprint(bound)}, float64(h.counts[i]))
+		}
+
+		m.sample("rcon_command_duration_seconds_bucket", labels{"command", name, "le", "+Inf"}, float64(h.count))
+		m.sample("rcon_command_duration_seconds_sum", labels{"command", name}, h.sum)
+		m.sample("rcon_command_duration_seconds_count", labels{"command", name}, float64(h.count))
+	}
+
+	keys := [][2]string{}
+	for k := range e.errors {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	m.family("rcon_command_errors_total", "counter", "Failed commands by error type.")
+
+	for _, k := range keys {
+		m.sample("rcon_command_errors_total", labels{"command", k[0], "type", k[1]}, float64(e.errors[k]))
+	}
+
+	m.family("rcon_sessions", "gauge", "Sessions opened by the connection pool.")
+	m.sample("rcon_sessions", nil, float64(e.Conn.Sessions()))
+
+	if e.Limiter != nil {
+		stats := e.Limiter.Stats()
+		classes := []ratelimit.Priority{ratelimit.PriorityModeration, ratelimit.PriorityInteractive, ratelimit.PriorityBackground}
+
+		m.family("rcon_queue_depth", "gauge", "Commands waiting for the rate limiter.")
+		for _, p := range classes {
+			m.sample("rcon_queue_depth", labels{"priority", p.String()}, float64(stats.Queued[p]))
+		}
+
+		m.family("rcon_rate_limited_total", "counter", "Commands delayed by the rate limiter.")
+		for _, p := range classes {
+			m.sample("rcon_rate_limited_total", labels{"priority", p.String()}, float64(stats.Delayed[p]))
+		}
+
+		m.family("rcon_rate_limit_wait_seconds_total", "counter", "Time commands spent waiting for the rate limiter.")
+		for _, p := range classes {
+			m.sample("rcon_rate_limit_wait_seconds_total", labels{"priority", p.String()}, stats.Waited[p].Seconds())
+		}
+
+		m.family("rcon_rate_limit_dropped_total", "counter", "Commands rejected by a full rate limiter queue.")
+		for _, p := range classes {
+			m.sample("rcon_rate_limit_dropped_total", labels{"priority", p.String()}, float64(stats.Dropped[p]))
+		}
+	}
+
+	return b.WriteTo(w)
+}
+
+// labels represents label names and values in pairs.
+type labels []string
+
+// metrics will write samples in the text exposition format.
+type metrics struct {
+	b *bytes.Buffer
+}
+
+func (m *metrics) family(name, kind, help string) {
+	fmt.Fprintf(m.b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (m *metrics) sample(name string, l labels, v float64) {
+	m.b.WriteString(name)
+
+	if len(l) > 0 {
+		pairs := []string{}
+		for i := 0; i+1 < len(l); i += 2 {
+			pairs = append(pairs, fmt.Sprintf("%s=%q", l[i], escape(l[i+1])))
+		}
+
+		fmt.Fprintf(m.b, "{%s}", strings.Join(pairs, ","))
+	}
+
+	fmt.Fprintf(m.b, " %v\n", v)
+}
+
+// escape will remove characters %q would escape differently than the exposition format.
+func escape(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7F {
+			return ' '
+		}
+
+		return r
+	}, s)
+}
+
+func boolean(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
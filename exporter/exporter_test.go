@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/ratelimit"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// recorded is a sample read from a live server.
+var recorded = sample{
+	state: rcon.GameState{
+		AlliedPlayers: 35,
+		AxisPlayers:   34,
+		AlliedScore:   2,
+		AxisScore:     3,
+		Remaining:     42*time.Minute + 31*time.Second,
+		Map:           rcon.Map{MapName: "foy_warfare"},
+		NextMap:       rcon.Map{MapName: "kursk_warfare"},
+	},
+	used:     69,
+	slots:    100,
+	capacity: 6,
+	vips:     12,
+}
+
+func TestWriteTo(t *testing.T) {
+	start := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		limiter bool
+		run     func(e *Exporter, clock *time.Time)
+	}{
+		{
+			name: "unsampled",
+			run: func(e *Exporter, clock *time.Time) {
+				e.update(sample{}, errors.New("connection refused"))
+			},
+		},
+		{
+			name: "sampled",
+			run: func(e *Exporter, clock *time.Time) {
+				e.update(recorded, nil)
+
+				e.Record(rcon.Event{Kind: rcon.EventKill})
+				e.Record(rcon.Event{Kind: rcon.EventTeamKill})
+
+				// Events older than a minute leave the per minute gauges, but not the totals.
+				*clock = clock.Add(90 * time.Second)
+
+				e.Record(rcon.Event{Kind: rcon.EventKill})
+				e.Record(rcon.Event{Kind: rcon.EventChat})
+				e.Record(rcon.Event{Kind: rcon.EventConnected})
+
+				e.observe(rcon.CommandResult{Name: "get gamestate", Duration: 3 * time.Millisecond})
+				e.observe(rcon.CommandResult{Name: "get gamestate", Duration: 40 * time.Millisecond})
+				e.observe(rcon.CommandResult{Name: "kick", Duration: 2 * time.Second, Err: rcon.ErrResultFailed})
+				e.observe(rcon.CommandResult{Name: "kick", Duration: time.Millisecond, Err: errors.New("unexpected")})
+			},
+		},
+		{
+			name: "stale",
+			run: func(e *Exporter, clock *time.Time) {
+				e.update(recorded, nil)
+
+				// A failed sample keeps the previous values.
+				e.update(sample{}, errors.New("i/o timeout"))
+			},
+		},
+		{
+			name:    "limiter",
+			limiter: true,
+			run: func(e *Exporter, clock *time.Time) {
+				e.update(recorded, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := start
+
+			e := New(&rcon.Conn{})
+			e.now = func() time.Time { return clock }
+
+			if tt.limiter {
+				e.Limiter = ratelimit.New(10, 5)
+			}
+
+			tt.run(e, &clock)
+
+			b := &bytes.Buffer{}
+
+			_, err := e.WriteTo(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tt.name+".prom")
+
+			if *update {
+				err = ioutil.WriteFile(golden, b.Bytes(), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if b.String() != string(want) {
+				t.Errorf("WriteTo() mismatch with %s, got:\n%s", golden, b)
+			}
+		})
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{rcon.ErrResultFailed, "failed"},
+		{&timeoutError{}, "timeout"},
+		{errors.New("other"), "other"},
+	}
+
+	for _, tt := range tests {
+		got := errorType(tt.err)
+		if got != tt.want {
+			t.Errorf("errorType(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -0,0 +1,362 @@
+// Package feed streams log Events to browsers over WebSocket and Server-Sent Events.
+//
+// Clients choose the Events they receive with query parameters: type (a comma separated list of
+// event kinds), player (an ID64 matching the player or victim) and team. Each Event carries a
+// sequence number, and clients reconnecting with ?cursor=N, or the Last-Event-ID header of
+// EventSource, resume after Event N from the recent history kept by the Hub. Clients that fall
+// too far behind are disconnected with a notice, so one slow browser never stalls the others,
+// and can resume from their cursor.
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Message represents an Event sent to clients, with its sequence number.
+type Message struct {
+	ID uint64 `json:"id"`
+	rcon.Event
+}
+
+// Filter represents the Events a client receives. Empty fields match every Event.
+type Filter struct {
+	Kinds  map[rcon.EventKind]bool
+	Player string // ID64 of the player or victim.
+	Team   string // Team of the player or victim.
+}
+
+// Hub represents the set of connected clients and the recent history of Events.
+type Hub struct {
+	History   int           // Events kept for clients resuming from a cursor, 1024 when zero.
+	Buffer    int           // Events queued per client before it is disconnected, 256 when zero.
+	KeepAlive time.Duration // Interval of keep-alive messages on idle streams, 30s when zero.
+
+	// Origins lists the origins, e.g. https://stats.example.com, allowed to open WebSocket
+	// streams from another site. Requests from the Hub's own host are always allowed.
+	Origins []string
+
+	mu      sync.Mutex
+	seq     uint64
+	history []Message
+	clients map[*client]bool
+}
+
+// client represents a single connected stream.
+type client struct {
+	filter Filter
+	queue  chan Message
+	lagged chan struct{} // Closed when the queue overflows.
+}
+
+// New returns an empty Hub.
+func New() *Hub {
+	return &Hub{
+		clients: map[*client]bool{},
+	}
+}
+
+// Run will publish Events from a channel, e.g. a LogStream subscription, until it is closed or
+// the context is cancelled.
+func (h *Hub) Run(ctx context.Context, events <-chan rcon.Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			h.Publish(e)
+		}
+	}
+}
+
+// Publish will assign the next sequence number to an Event and send it to every matching client.
+// Clients with a full queue are disconnected rather than blocking the Hub.
+func (h *Hub) Publish(e rcon.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	m := Message{ID: h.seq, Event: e}
+
+	h.history = append(h.history, m)
+	if n := len(h.history) - h.historySize(); n > 0 {
+		h.history = append([]Message{}, h.history[n:]...)
+	}
+
+	for c := range h.clients {
+		if !c.filter.Match(e) {
+			continue
+		}
+
+		select {
+		case c.queue <- m:
+		default:
+			close(c.lagged)
+			delete(h.clients, c)
+		}
+	}
+}
+
+// Clients returns the number of connected clients.
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.clients)
+}
+
+// ServeHTTP will stream Events over WebSocket when the request asks for an upgrade, and as
+// Server-Sent Events otherwise.
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := ParseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cursor, err := parseCursor(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		h.serveWebSocket(w, r, filter, cursor)
+		return
+	}
+
+	h.serveEvents(w, r, filter, cursor)
+}
+
+// ParseFilter will read a Filter from the type, player and team query parameters of a request.
+func ParseFilter(r *http.Request) (Filter, error) {
+	query := r.URL.Query()
+
+	f := Filter{
+		Player: query.Get("player"),
+		Team:   query.Get("team"),
+	}
+
+	if f.Team != "" && !strings.EqualFold(f.Team, rcon.TeamAllies) && !strings.EqualFold(f.Team, rcon.TeamAxis) {
+		return Filter{}, fmt.Errorf("unknown team %q, expected %s or %s", f.Team, rcon.TeamAllies, rcon.TeamAxis)
+	}
+
+	kinds := query.Get("type")
+	if kinds == "" {
+		return f, nil
+	}
+
+	f.Kinds = map[rcon.EventKind]bool{}
+
+	for _, k := range strings.Split(kinds, ",") {
+		kind := rcon.EventKind(strings.TrimSpace(k))
+
+		switch kind {
+		case rcon.EventKill, rcon.EventTeamKill, rcon.EventChat, rcon.EventConnected, rcon.EventDisconnected,
+			rcon.EventTeamSwitch, rcon.EventMatchStart, rcon.EventMatchEnd, rcon.EventOther:
+			f.Kinds[kind] = true
+		default:
+			return Filter{}, fmt.Errorf("unknown event type %q", kind)
+		}
+	}
+
+	return f, nil
+}
+
+// Match reports whether an Event passes the Filter.
+func (f Filter) Match(e rcon.Event) bool {
+	if len(f.Kinds) > 0 && !f.Kinds[e.Kind] {
+		return false
+	}
+
+	if f.Player != "" && e.Player.ID64 != f.Player && e.Victim.ID64 != f.Player {
+		return false
+	}
+
+	if f.Team != "" && !strings.EqualFold(e.Team, f.Team) && !strings.EqualFold(e.VictimTeam, f.Team) {
+		return false
+	}
+
+	return true
+}
+
+// subscribe will register a client, returning the Events after cursor it missed. Both happen
+// under one lock, so the backlog and the queue neither overlap nor leave a gap.
+func (h *Hub) subscribe(filter Filter, cursor uint64) (*client, []Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := &client{
+		filter: filter,
+		queue:  make(chan Message, h.bufferSize()),
+		lagged: make(chan struct{}),
+	}
+
+	h.clients[c] = true
+
+	backlog := []Message{}
+
+	if cursor > 0 {
+		for _, m := range h.history {
+			if m.ID > cursor && filter.Match(m.Event) {
+				backlog = append(backlog, m)
+			}
+		}
+	}
+
+	return c, backlog
+}
+
+func (h *Hub) unsubscribe(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, c)
+}
+
+// serveEvents will stream Events as Server-Sent Events until the client disconnects or lags.
+func (h *Hub) serveEvents(w http.ResponseWriter, r *http.Request, filter Filter, cursor uint64) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	c, backlog := h.subscribe(filter, cursor)
+	defer h.unsubscribe(c)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(m Message) error {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", m.ID, m.Kind, b)
+
+		return err
+	}
+
+	for _, m := range backlog {
+		if write(m) != nil {
+			return
+		}
+	}
+
+	flusher.Flush()
+
+	t := time.NewTicker(h.keepAlive())
+	defer t.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.lagged:
+			fmt.Fprint(w, "event: lagged\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		case <-t.C:
+			_, err := fmt.Fprint(w, ": keep-alive\n\n")
+			if err != nil {
+				return
+			}
+
+			flusher.Flush()
+		case m := <-c.queue:
+			if write(m) != nil {
+				return
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+// allowOrigin reports whether a WebSocket request comes from the Hub's own host or an allowed
+// origin. Browsers don't apply the same-origin policy to WebSocket, so without this check any
+// site a user visits could read the feed with their credentials.
+func (h *Hub) allowOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, o := range h.Origins {
+		if strings.EqualFold(strings.TrimSuffix(o, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseCursor(r *http.Request) (uint64, error) {
+	s := r.URL.Query().Get("cursor")
+	if s == "" {
+		s = r.Header.Get("Last-Event-ID")
+	}
+
+	if s == "" {
+		return 0, nil
+	}
+
+	cursor, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cursor %q", s)
+	}
+
+	return cursor, nil
+}
+
+func (h *Hub) historySize() int {
+	if h.History > 0 {
+		return h.History
+	}
+
+	return 1024
+}
+
+func (h *Hub) bufferSize() int {
+	if h.Buffer > 0 {
+		return h.Buffer
+	}
+
+	return 256
+}
+
+func (h *Hub) keepAlive() time.Duration {
+	if h.KeepAlive > 0 {
+		return h.KeepAlive
+	}
+
+	return 30 * time.Second
+}
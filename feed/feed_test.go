@@ -0,0 +1,266 @@
+package feed
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+var (
+	bob = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+	eve = rcon.Player{Name: "Eve", ID64: "76561198000000002"}
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		query   string
+		event   rcon.Event
+		want    bool
+		wantErr bool
+	}{
+		{query: "", event: rcon.Event{Kind: rcon.EventChat}, want: true},
+		{query: "type=kill,teamkill", event: rcon.Event{Kind: rcon.EventTeamKill}, want: true},
+		{query: "type=kill,%20teamkill", event: rcon.Event{Kind: rcon.EventChat}, want: false},
+		{query: "type=headshot", wantErr: true},
+		{query: "player=" + bob.ID64, event: rcon.Event{Kind: rcon.EventKill, Player: eve, Victim: bob}, want: true},
+		{query: "player=" + bob.ID64, event: rcon.Event{Kind: rcon.EventKill, Player: eve}, want: false},
+		{query: "team=axis", event: rcon.Event{Kind: rcon.EventKill, Team: rcon.TeamAllies, VictimTeam: rcon.TeamAxis}, want: true},
+		{query: "team=allies", event: rcon.Event{Kind: rcon.EventChat, Team: rcon.TeamAxis}, want: false},
+		{query: "team=spectators", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			f, err := ParseFilter(httptest.NewRequest(http.MethodGet, "/events?"+tt.query, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFilter() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if err == nil && f.Match(tt.event) != tt.want {
+				t.Errorf("Match() = %v, want %v", !tt.want, tt.want)
+			}
+		})
+	}
+}
+
+// publish will send a kill, a chat message and another kill to a Hub.
+func publish(h *Hub) {
+	h.Publish(rcon.Event{Kind: rcon.EventKill, Player: bob, Victim: eve})
+	h.Publish(rcon.Event{Kind: rcon.EventChat, Player: eve, Message: "gg"})
+	h.Publish(rcon.Event{Kind: rcon.EventKill, Player: eve, Victim: bob})
+}
+
+func TestServeEvents(t *testing.T) {
+	h := New()
+	h.KeepAlive = time.Hour
+	publish(h)
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/events?type=kill", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Last-Event-ID", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	r := bufio.NewReader(resp.Body)
+
+	// Event 3 is the backlog after the cursor, and Event 5 is published once the client listens.
+	want := []struct {
+		id     string
+		killer string
+	}{
+		{id: "3", killer: eve.Name},
+		{id: "5", killer: bob.Name},
+	}
+
+	for i, w := range want {
+		if i == 1 {
+			h.Publish(rcon.Event{Kind: rcon.EventChat, Player: bob, Message: "sorry"})
+			h.Publish(rcon.Event{Kind: rcon.EventKill, Player: bob, Victim: eve})
+		}
+
+		lines := []string{}
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read %q: %v", lines, err)
+			}
+
+			if line == "\n" {
+				break
+			}
+
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+
+		if len(lines) != 3 || lines[0] != "id: "+w.id || lines[1] != "event: kill" || !strings.HasPrefix(lines[2], "data: ") {
+			t.Fatalf("event %q, want id %s, event kill and data", lines, w.id)
+		}
+
+		m := Message{}
+
+		err := json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id := strings.TrimPrefix(lines[0], "id: "); m.Player.Name != w.killer || id != w.id {
+			t.Errorf("data %+v, want the kill of %s", m, w.killer)
+		}
+	}
+}
+
+func TestServeWebSocket(t *testing.T) {
+	tests := []struct {
+		name       string
+		origin     string
+		version    string
+		wantStatus int
+	}{
+		{name: "no origin", wantStatus: http.StatusSwitchingProtocols},
+		{name: "same origin", origin: "http://{host}", wantStatus: http.StatusSwitchingProtocols},
+		{name: "allowed origin", origin: "https://stats.example.com", wantStatus: http.StatusSwitchingProtocols},
+		{name: "allowed origin case", origin: "https://Stats.Example.com", wantStatus: http.StatusSwitchingProtocols},
+		{name: "other origin", origin: "https://evil.example.com", wantStatus: http.StatusForbidden},
+		{name: "other port", origin: "http://{host}0", wantStatus: http.StatusForbidden},
+		{name: "version", version: "8", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New()
+			h.KeepAlive = time.Hour
+			h.Origins = []string{"https://stats.example.com/"}
+			publish(h)
+
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+
+			host := strings.TrimPrefix(srv.URL, "http://")
+
+			conn, err := net.Dial("tcp", host)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+			version := tt.version
+			if version == "" {
+				version = "13"
+			}
+
+			// The key and its accept value are the example of RFC 6455.
+			handshake := "GET /events?cursor=2 HTTP/1.1\r\n" +
+				"Host: " + host + "\r\n" +
+				"Upgrade: websocket\r\n" +
+				"Connection: keep-alive, Upgrade\r\n" +
+				"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+				"Sec-WebSocket-Version: " + version + "\r\n"
+
+			if tt.origin != "" {
+				handshake += "Origin: " + strings.Replace(tt.origin, "{host}", host, 1) + "\r\n"
+			}
+
+			_, err = io.WriteString(conn, handshake+"\r\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := bufio.NewReader(conn)
+
+			resp, err := http.ReadResponse(r, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			if tt.wantStatus != http.StatusSwitchingProtocols {
+				return
+			}
+
+			if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+				t.Errorf("Sec-WebSocket-Accept = %q, want the key hashed with the GUID", accept)
+			}
+
+			header := make([]byte, 2)
+
+			_, err = io.ReadFull(r, header)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Messages are longer than 125 bytes, so the length follows in two bytes.
+			if header[0] != 0x80|opText || header[1] != 126 {
+				t.Fatalf("frame header %x, want a final unmasked text frame with a 16 bit length", header)
+			}
+
+			length := make([]byte, 2)
+
+			_, err = io.ReadFull(r, length)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			payload := make([]byte, binary.BigEndian.Uint16(length))
+
+			_, err = io.ReadFull(r, payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m := Message{}
+
+			err = json.Unmarshal(payload, &m)
+			if err != nil || m.ID != 3 || m.Player.Name != eve.Name {
+				t.Errorf("message %s, want the backlog after the cursor", payload)
+			}
+
+			// Closing with a masked frame is answered with a close frame.
+			mask := []byte{1, 2, 3, 4}
+			close := []byte{0x80 | opClose, 0x80 | 2, mask[0], mask[1], mask[2], mask[3], 0, 0}
+			binary.BigEndian.PutUint16(close[6:], closeNormal)
+
+			for i := range close[6:] {
+				close[6+i] ^= mask[i%4]
+			}
+
+			_, err = conn.Write(close)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = io.ReadFull(r, header)
+			if err != nil || header[0] != 0x80|opClose {
+				t.Errorf("frame header %x (%v), want a close frame", header, err)
+			}
+		})
+	}
+}
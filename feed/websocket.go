@@ -0,0 +1,266 @@
+package feed
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes and close codes from RFC 6455.
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+
+	closeNormal     = 1000
+	closeTryAgain   = 1013
+	maxControlFrame = 125
+)
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// websocket represents the server side of a WebSocket connection. Only the subset of the
+// protocol needed to push text messages is implemented.
+type websocket struct {
+	mu   sync.Mutex // Serializes frames written by the stream and the reader.
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// serveWebSocket will upgrade the connection and stream Events as JSON text messages until the
+// client disconnects or lags.
+func (h *Hub) serveWebSocket(w http.ResponseWriter, r *http.Request, filter Filter, cursor uint64) {
+	if !h.allowOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	ws, err := upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.conn.Close()
+
+	c, backlog := h.subscribe(filter, cursor)
+	defer h.unsubscribe(c)
+
+	closed := make(chan struct{})
+	go func() {
+		ws.read()
+		close(closed)
+	}()
+
+	write := func(m Message) error {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+
+		return ws.write(opText, b)
+	}
+
+	for _, m := range backlog {
+		if write(m) != nil {
+			return
+		}
+	}
+
+	t := time.NewTicker(h.keepAlive())
+	defer t.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-c.lagged:
+			ws.close(closeTryAgain, "lagged")
+			return
+		case <-t.C:
+			if ws.write(opPing, nil) != nil {
+				return
+			}
+		case m := <-c.queue:
+			if write(m) != nil {
+				return
+			}
+		}
+	}
+}
+
+// upgrade will complete the WebSocket handshake and take over the connection.
+func upgrade(w http.ResponseWriter, r *http.Request) (*websocket, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+
+	if key == "" || !headerContains(r.Header, "Connection", "upgrade") || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "invalid websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("invalid websocket handshake")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket unsupported", http.StatusInternalServerError)
+		return nil, errors.New("websocket unsupported")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+
+	_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &websocket{conn: conn, rw: rw}, nil
+}
+
+// write will send a single unmasked frame.
+func (ws *websocket) write(op byte, payload []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	header := []byte{0x80 | op}
+
+	switch n := len(payload); {
+	case n <= 125:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	ws.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+
+	_, err := ws.rw.Write(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = ws.rw.Write(payload)
+	if err != nil {
+		return err
+	}
+
+	return ws.rw.Flush()
+}
+
+func (ws *websocket) close(code int, reason string) error {
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+
+	return ws.write(opClose, append(payload, reason...))
+}
+
+// read will consume frames from the client, answering pings, until the client closes the
+// connection. Messages from the client are ignored.
+func (ws *websocket) read() {
+	for {
+		op, payload, err := ws.frame()
+		if err != nil {
+			return
+		}
+
+		switch op {
+		case opClose:
+			ws.close(closeNormal, "")
+			return
+		case opPing:
+			if ws.write(opPong, payload) != nil {
+				return
+			}
+		}
+	}
+}
+
+// frame will read a single masked frame from the client.
+func (ws *websocket) frame() (byte, []byte, error) {
+	header := make([]byte, 2)
+
+	_, err := io.ReadFull(ws.rw, header)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	op := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	n := uint64(header[1] & 0x7F)
+
+	switch n {
+	case 126:
+		b := make([]byte, 2)
+
+		_, err = io.ReadFull(ws.rw, b)
+		n = uint64(binary.BigEndian.Uint16(b))
+	case 127:
+		b := make([]byte, 8)
+
+		_, err = io.ReadFull(ws.rw, b)
+		n = binary.BigEndian.Uint64(b)
+	}
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Clients must mask their frames, and have nothing to send but control frames.
+	if !masked || n > maxControlFrame*32 {
+		return 0, nil, errors.New("invalid websocket frame")
+	}
+
+	mask := make([]byte, 4)
+
+	_, err = io.ReadFull(ws.rw, mask)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	payload := make([]byte, n)
+
+	_, err = io.ReadFull(ws.rw, payload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return op, payload, nil
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
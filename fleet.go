@@ -0,0 +1,263 @@
+package rcon
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fleet represents a collection of Conns keyed by server name. Servers can be added and removed
+// while commands are running, and can be grouped with tags to target a subset of the Fleet.
+type Fleet struct {
+	mu      sync.RWMutex
+	servers map[string]*member
+}
+
+type member struct {
+	conn *Conn
+	tags map[string]bool
+}
+
+// Result represents the outcome of a Fleet command for a single server.
+type Result struct {
+	Server   string
+	Err      error
+	Duration time.Duration
+}
+
+// Results represents the outcome of a Fleet command, sorted by server name.
+type Results []Result
+
+// FleetError represents the errors of a Fleet command, keyed by server name.
+type FleetError map[string]error
+
+// NewFleet returns an empty Fleet.
+func NewFleet() *Fleet {
+	return &Fleet{
+		servers: map[string]*member{},
+	}
+}
+
+// Add will register a Conn under a unique server name. The Fleet takes ownership of the Conn,
+// closing it when removed.
+func (f *Fleet) Add(name string, c *Conn, tags ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.servers[name]; ok {
+		return fmt.Errorf("failed to add server %s: already exists", name)
+	}
+
+	m := &member{
+		conn: c,
+		tags: map[string]bool{},
+	}
+
+	for _, t := range tags {
+		m.tags[t] = true
+	}
+
+	f.servers[name] = m
+
+	return nil
+}
+
+// Remove will unregister and close the Conn for a server.
+func (f *Fleet) Remove(name string) error {
+	f.mu.Lock()
+	m, ok := f.servers[name]
+	delete(f.servers, name)
+	f.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("failed to remove server %s: does not exist", name)
+	}
+
+	err := m.conn.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close server %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// Conn returns the Conn registered for a server.
+func (f *Fleet) Conn(name string) (*Conn, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	m, ok := f.servers[name]
+	if !ok {
+		return nil, false
+	}
+
+	return m.conn, true
+}
+
+// SetTags will replace the tags of a server.
+func (f *Fleet) SetTags(name string, tags ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	m, ok := f.servers[name]
+	if !ok {
+		return fmt.Errorf("failed to tag server %s: does not exist", name)
+	}
+
+	m.tags = map[string]bool{}
+	for _, t := range tags {
+		m.tags[t] = true
+	}
+
+	return nil
+}
+
+// Tags returns the sorted tags of a server.
+func (f *Fleet) Tags(name string) []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	m, ok := f.servers[name]
+	if !ok {
+		return nil
+	}
+
+	tags := []string{}
+	for t := range m.tags {
+		tags = append(tags, t)
+	}
+
+	sort.Strings(tags)
+
+	return tags
+}
+
+// Names returns the sorted names of servers with a tag, or every server for an empty tag.
+func (f *Fleet) Names(tag string) []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	names := []string{}
+
+	for name, m := range f.servers {
+		if tag == "" || m.tags[tag] {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Do will run fn in parallel against every server with a tag, or every server for an empty tag.
+func (f *Fleet) Do(tag string, fn func(*Conn) error) Results {
+	return f.Each(tag, func(_ string, c *Conn) error {
+		return fn(c)
+	})
+}
+
+// Each behaves like Do, but also passes the server name to fn so results can be collected per server.
+func (f *Fleet) Each(tag string, fn func(name string, c *Conn) error) Results {
+	f.mu.RLock()
+
+	targets := map[string]*Conn{}
+	for name, m := range f.servers {
+		if tag == "" || m.tags[tag] {
+			targets[name] = m.conn
+		}
+	}
+
+	f.mu.RUnlock()
+
+	results := make(chan Result, len(targets))
+
+	for name, c := range targets {
+		go func(name string, c *Conn) {
+			start := time.Now()
+
+			err := fn(name, c)
+
+			results <- Result{
+				Server:   name,
+				Err:      err,
+				Duration: time.Since(start),
+			}
+		}(name, c)
+	}
+
+	r := Results{}
+	for range targets {
+		r = append(r, <-results)
+	}
+
+	sort.Slice(r, func(i, j int) bool { return r[i].Server < r[j].Server })
+
+	return r
+}
+
+// Close will close the Conn of every server and empty the Fleet.
+func (f *Fleet) Close() error {
+	errs := FleetError{}
+
+	for _, name := range f.Names("") {
+		err := f.Remove(name)
+		if err != nil {
+			errs[name] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Err returns a FleetError holding every failed server, or nil if all succeeded.
+func (r Results) Err() error {
+	errs := FleetError{}
+
+	for _, result := range r {
+		if result.Err != nil {
+			errs[result.Server] = result.Err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Failed returns the names of servers that returned an error.
+func (r Results) Failed() []string {
+	names := []string{}
+
+	for _, result := range r {
+		if result.Err != nil {
+			names = append(names, result.Server)
+		}
+	}
+
+	return names
+}
+
+func (e FleetError) Error() string {
+	names := []string{}
+	for name := range e {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	msgs := []string{}
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", name, e[name]))
+	}
+
+	return fmt.Sprintf("%d server(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
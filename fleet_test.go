@@ -0,0 +1,136 @@
+package rcon
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFleetEach(t *testing.T) {
+	tests := []struct {
+		name       string
+		tag        string
+		failing    map[string]bool
+		want       []string // Servers of the Results.
+		wantFailed []string
+		wantErr    string
+	}{
+		{
+			name:       "all",
+			want:       []string{"eu-1", "eu-2", "us-1"},
+			wantFailed: []string{},
+		},
+		{
+			name:       "tagged",
+			tag:        "eu",
+			want:       []string{"eu-1", "eu-2"},
+			wantFailed: []string{},
+		},
+		{
+			name:       "unknown tag",
+			tag:        "asia",
+			want:       []string{},
+			wantFailed: []string{},
+		},
+		{
+			name:       "one failed",
+			failing:    map[string]bool{"eu-2": true},
+			want:       []string{"eu-1", "eu-2", "us-1"},
+			wantFailed: []string{"eu-2"},
+			wantErr:    "1 server(s) failed: eu-2: eu-2 is down",
+		},
+		{
+			name:       "several failed",
+			failing:    map[string]bool{"us-1": true, "eu-1": true},
+			want:       []string{"eu-1", "eu-2", "us-1"},
+			wantFailed: []string{"eu-1", "us-1"},
+			wantErr:    "2 server(s) failed: eu-1: eu-1 is down; us-1: us-1 is down",
+		},
+		{
+			name:       "failed outside tag",
+			tag:        "us",
+			failing:    map[string]bool{"eu-1": true},
+			want:       []string{"us-1"},
+			wantFailed: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFleet()
+
+			for name, tags := range map[string][]string{"eu-1": {"eu"}, "eu-2": {"eu"}, "us-1": {"us"}} {
+				err := f.Add(name, nil, tags...)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			results := f.Each(tt.tag, func(name string, _ *Conn) error {
+				if tt.failing[name] {
+					return errors.New(name + " is down")
+				}
+
+				return nil
+			})
+
+			servers := []string{}
+			for _, r := range results {
+				servers = append(servers, r.Server)
+			}
+
+			if !equalStrings(servers, tt.want) {
+				t.Errorf("Each() servers = %q, want %q", servers, tt.want)
+			}
+
+			if failed := results.Failed(); !equalStrings(failed, tt.wantFailed) {
+				t.Errorf("Failed() = %q, want %q", failed, tt.wantFailed)
+			}
+
+			err := results.Err()
+
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Err() = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("Err() = %v, want %q", err, tt.wantErr)
+			}
+
+			var fe FleetError
+			if tt.wantErr != "" && (!errors.As(err, &fe) || len(fe) != len(tt.wantFailed)) {
+				t.Errorf("Err() = %#v, want a FleetError of every failed server", err)
+			}
+		})
+	}
+}
+
+func TestFleetAdd(t *testing.T) {
+	f := NewFleet()
+
+	err := f.Add("eu-1", nil, "eu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.Add("eu-1", nil)
+	if err == nil {
+		t.Error("Add() of a duplicate name succeeded")
+	}
+
+	err = f.SetTags("eu-1", "eu", "hardcore")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tags := f.Tags("eu-1"); !equalStrings(tags, []string{"eu", "hardcore"}) {
+		t.Errorf("Tags() = %q, want both tags", tags)
+	}
+
+	if names := f.Names("hardcore"); !equalStrings(names, []string{"eu-1"}) {
+		t.Errorf("Names() = %q, want the tagged server", names)
+	}
+
+	err = f.SetTags("us-1", "us")
+	if err == nil {
+		t.Error("SetTags() of an unknown server succeeded")
+	}
+}
@@ -0,0 +1,92 @@
+package rcon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GameState represents the progress of the current match.
+type GameState struct {
+	AlliedPlayers int
+	AxisPlayers   int
+	AlliedScore   int
+	AxisScore     int
+	Remaining     time.Duration
+	Map           Map
+	NextMap       Map
+}
+
+// GameState returns the team sizes, score, remaining time and maps of the current match.
+func (c *Conn) GameState() (GameState, error) {
+	result, err := c.send("get", "gamestate")
+	if err != nil {
+		return GameState{}, fmt.Errorf("failed to get game state: %w", err)
+	}
+
+	return parseGameState(result)
+}
+
+// parseGameState will parse the "key: value" lines of a gamestate response, e.g.
+//
+//	Players: Allied: 35 - Axis: 34
+//	Score: Allied: 2 - Axis: 3
+//	Remaining Time: 0:42:31
+//	Map: foy_warfare
+//	Next Map: kursk_warfare
+func parseGameState(s string) (GameState, error) {
+	g := GameState{}
+
+	for _, line := range strings.Split(s, "\n") {
+		args := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(args) < 2 {
+			continue
+		}
+
+		value := strings.TrimSpace(args[1])
+
+		var err error
+
+		switch args[0] {
+		case "Players":
+			g.AlliedPlayers, g.AxisPlayers, err = parseTeams(value)
+		case "Score":
+			g.AlliedScore, g.AxisScore, err = parseTeams(value)
+		case "Remaining Time":
+			g.Remaining, err = parseClock(value)
+		case "Map":
+			g.Map = mapFromString(value)
+		case "Next Map":
+			g.NextMap = mapFromString(value)
+		}
+
+		if err != nil {
+			return GameState{}, fmt.Errorf("failed to parse game state %s: %w", strings.ToLower(args[0]), err)
+		}
+	}
+
+	return g, nil
+}
+
+// parseTeams will parse a pair of team values, e.g. "Allied: 2 - Axis: 3".
+func parseTeams(s string) (allied, axis int, err error) {
+	_, err = fmt.Sscanf(s, "Allied: %d - Axis: %d", &allied, &axis)
+	return allied, axis, err
+}
+
+// parseClock will parse a duration written as h:mm:ss.
+func parseClock(s string) (time.Duration, error) {
+	d := time.Duration(0)
+
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, err
+		}
+
+		d = d*60 + time.Duration(n)
+	}
+
+	return d * time.Second, nil
+}
@@ -0,0 +1,67 @@
+package rcon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGameState(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    GameState
+		wantErr bool
+	}{
+		{
+			name: "match",
+			in:   "Players: Allied: 35 - Axis: 34\nScore: Allied: 2 - Axis: 3\nRemaining Time: 0:42:31\nMap: foy_warfare\nNext Map: kursk_warfare",
+			want: GameState{
+				AlliedPlayers: 35,
+				AxisPlayers:   34,
+				AlliedScore:   2,
+				AxisScore:     3,
+				Remaining:     42*time.Minute + 31*time.Second,
+				Map:           mapFromName(MapFoyWarfare),
+				NextMap:       mapFromName(MapKurskWarfare),
+			},
+		},
+		{
+			name: "crlf and unknown lines",
+			in:   "Players: Allied: 0 - Axis: 0\r\nRemaining Time: 1:30:00\r\nWarmup: true\r\n\r\n",
+			want: GameState{Remaining: 90 * time.Minute},
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: GameState{},
+		},
+		{
+			name:    "invalid players",
+			in:      "Players: Allied: many - Axis: 34",
+			wantErr: true,
+		},
+		{
+			name:    "invalid score",
+			in:      "Score: 2 - 3",
+			wantErr: true,
+		},
+		{
+			name:    "invalid remaining time",
+			in:      "Remaining Time: 0:4x:31",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGameState(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGameState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("parseGameState() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
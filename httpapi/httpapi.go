@@ -0,0 +1,272 @@
+// Package httpapi serves a Conn as a versioned REST API with JSON bodies.
+//
+// Every request must carry a bearer token, which grants a Scope. Read-only tokens may list server
+// state, moderator tokens may additionally act on players, and owner tokens may change the server
+// configuration. Errors are returned as JSON with a stable code, and the OpenAPI document of the
+// API is served at /v1/openapi.json.
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Scope represents the permissions granted to a token. Each Scope includes those below it.
+type Scope int
+
+// Scopes in increasing order of privilege.
+const (
+	ScopeRead Scope = iota
+	ScopeModerator
+	ScopeOwner
+)
+
+// Token represents the identity and permissions of an API client.
+type Token struct {
+	Name  string // The operator of commands sent with the token, and the admin of its bans.
+	Scope Scope
+}
+
+// Error codes returned in error responses.
+const (
+	CodeBadRequest    = "bad_request"
+	CodeUnauthorized  = "unauthorized"
+	CodeForbidden     = "forbidden"
+	CodeNotFound      = "not_found"
+	CodeMethod        = "method_not_allowed"
+	CodeCommandFailed = "command_failed"
+	CodeUnreachable   = "server_unreachable"
+	CodeTimeout       = "server_timeout"
+	CodeInternal      = "internal"
+)
+
+// maxBodySize is the largest request body accepted, far above any body of the API.
+const maxBodySize = 1 << 20
+
+// Error represents an error response.
+type Error struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server represents the HTTP API for a single Conn.
+type Server struct {
+	conn   *rcon.Conn
+	tokens map[string]Token
+	routes []*route
+}
+
+// route represents a single endpoint. Paths may hold parameters in braces, e.g. "/v1/vips/{id64}".
+type route struct {
+	method  string
+	path    string
+	scope   Scope
+	summary string
+
+	// request and response are zero values of the bodies, used to describe them in the OpenAPI document.
+	request  interface{}
+	response interface{}
+	status   int // Status of a successful response, http.StatusOK when zero.
+
+	handle func(r *request) (interface{}, error)
+}
+
+// request represents an authorized request with its path parameters.
+type request struct {
+	*http.Request
+
+	token  Token
+	params map[string]string
+	conn   *rcon.Conn // The Conn acting as the token, e.g. to be audited.
+}
+
+// New returns a Server for a Conn, accepting the tokens in the keys of tokens.
+func New(c *rcon.Conn, tokens map[string]Token) *Server {
+	s := &Server{
+		conn:   c,
+		tokens: tokens,
+	}
+
+	s.routes = s.endpoints()
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, params, allowed := s.match(r.Method, r.URL.Path)
+	if rt == nil {
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeError(w, &Error{Status: http.StatusMethodNotAllowed, Code: CodeMethod, Message: fmt.Sprintf("method %s not allowed", r.Method)})
+			return
+		}
+
+		writeError(w, &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: fmt.Sprintf("no endpoint at %s", r.URL.Path)})
+		return
+	}
+
+	token, ok := s.authorize(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="rcon"`)
+		writeError(w, &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized, Message: "missing or unknown bearer token"})
+		return
+	}
+
+	if token.Scope < rt.scope {
+		writeError(w, &Error{Status: http.StatusForbidden, Code: CodeForbidden, Message: fmt.Sprintf("token %s lacks the %s scope", token.Name, rt.scope)})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	v, err := rt.handle(&request{Request: r, token: token, params: params, conn: s.conn.As(token.Name)})
+	if err != nil {
+		writeError(w, toError(err))
+		return
+	}
+
+	status := rt.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if v == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, status, v)
+}
+
+// match will find the route for a method and path, returning the methods allowed on the path
+// when only the method differs.
+func (s *Server) match(method, path string) (*route, map[string]string, []string) {
+	allowed := []string{}
+
+	for _, rt := range s.routes {
+		params, ok := matchPath(rt.path, path)
+		if !ok {
+			continue
+		}
+
+		if rt.method == method {
+			return rt, params, nil
+		}
+
+		allowed = append(allowed, rt.method)
+	}
+
+	return nil, nil, allowed
+}
+
+func matchPath(pattern, path string) (map[string]string, bool) {
+	want := strings.Split(strings.Trim(pattern, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(want) != len(got) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+
+	for i := range want {
+		if strings.HasPrefix(want[i], "{") {
+			if got[i] == "" {
+				return nil, false
+			}
+
+			params[strings.Trim(want[i], "{}")] = got[i]
+			continue
+		}
+
+		if want[i] != got[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func (s *Server) authorize(r *http.Request) (Token, bool) {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, "Bearer ") {
+		return Token{}, false
+	}
+
+	t, ok := s.tokens[strings.TrimPrefix(h, "Bearer ")]
+
+	return t, ok
+}
+
+// decode will read a JSON request body into v, rejecting unknown fields and bodies larger than
+// maxBodySize.
+func (r *request) decode(v interface{}) error {
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+
+	err := d.Decode(v)
+	if err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+
+	return nil
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (s Scope) String() string {
+	switch s {
+	case ScopeRead:
+		return "read"
+	case ScopeModerator:
+		return "moderator"
+	case ScopeOwner:
+		return "owner"
+	default:
+		return fmt.Sprintf("scope(%d)", int(s))
+	}
+}
+
+func badRequest(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// toError will map a library error to an API error.
+func toError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	var ne net.Error
+	switch {
+	case errors.Is(err, rcon.ErrResultFailed):
+		return &Error{Status: http.StatusUnprocessableEntity, Code: CodeCommandFailed, Message: err.Error()}
+	case errors.As(err, &ne) && ne.Timeout():
+		return &Error{Status: http.StatusGatewayTimeout, Code: CodeTimeout, Message: err.Error()}
+	case errors.As(err, &ne):
+		return &Error{Status: http.StatusBadGateway, Code: CodeUnreachable, Message: err.Error()}
+	default:
+		return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: err.Error()}
+	}
+}
+
+func writeError(w http.ResponseWriter, e *Error) {
+	writeJSON(w, e.Status, map[string]*Error{"error": e})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(v)
+}
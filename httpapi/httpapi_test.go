@@ -0,0 +1,155 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+)
+
+var tokens = map[string]Token{
+	"read":      {Name: "dashboard", Scope: ScopeRead},
+	"moderator": {Name: "discord-bot", Scope: ScopeModerator},
+	"owner":     {Name: "alice", Scope: ScopeOwner},
+}
+
+func TestServeHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		fail       bool // Whether the server fails the command.
+		wantStatus int
+		wantCode   string
+		wantSent   string // The command sent by the request, if any, and its operator.
+	}{
+		{name: "no token", method: http.MethodGet, path: "/v1/players", wantStatus: http.StatusUnauthorized, wantCode: CodeUnauthorized},
+		{name: "unknown token", method: http.MethodGet, path: "/v1/players", token: "guessed", wantStatus: http.StatusUnauthorized, wantCode: CodeUnauthorized},
+		{name: "read", method: http.MethodGet, path: "/v1/players", token: "read", wantStatus: http.StatusOK},
+		{name: "read forbidden", method: http.MethodPost, path: "/v1/players/Bob/kick", token: "read", body: `{"reason": "teamkilling"}`, wantStatus: http.StatusForbidden, wantCode: CodeForbidden},
+		{name: "moderator", method: http.MethodPost, path: "/v1/players/Bob/kick", token: "moderator", body: `{"reason": "teamkilling"}`, wantStatus: http.StatusNoContent, wantSent: "kick by discord-bot"},
+		{name: "moderator by id", method: http.MethodPost, path: "/v1/players/76561198000000001/punish", token: "moderator", body: `{"reason": "teamkilling"}`, wantStatus: http.StatusNoContent, wantSent: "punish by discord-bot"},
+		{name: "moderator forbidden", method: http.MethodPost, path: "/v1/settings/vote_kick_threshold/reset", token: "moderator", wantStatus: http.StatusForbidden, wantCode: CodeForbidden},
+		{name: "owner", method: http.MethodPost, path: "/v1/settings/vote_kick_threshold/reset", token: "owner", wantStatus: http.StatusNoContent, wantSent: "resetvotekickthreshold by alice"},
+		{name: "owner includes moderator", method: http.MethodPost, path: "/v1/broadcast", token: "owner", body: `{"message": "hello"}`, wantStatus: http.StatusNoContent, wantSent: "broadcast by alice"},
+		{name: "not found", method: http.MethodGet, path: "/v1/teleport", token: "owner", wantStatus: http.StatusNotFound, wantCode: CodeNotFound},
+		{name: "player not found", method: http.MethodPost, path: "/v1/players/Mallory/kick", token: "moderator", body: `{"reason": "teamkilling"}`, wantStatus: http.StatusNotFound, wantCode: CodeNotFound},
+		{name: "method", method: http.MethodDelete, path: "/v1/players", token: "owner", wantStatus: http.StatusMethodNotAllowed, wantCode: CodeMethod},
+		{name: "malformed body", method: http.MethodPost, path: "/v1/broadcast", token: "moderator", body: `{"message": `, wantStatus: http.StatusBadRequest, wantCode: CodeBadRequest},
+		{name: "unknown field", method: http.MethodPost, path: "/v1/broadcast", token: "moderator", body: `{"text": "hello"}`, wantStatus: http.StatusBadRequest, wantCode: CodeBadRequest},
+		{name: "body too large", method: http.MethodPost, path: "/v1/broadcast", token: "moderator", body: `{"message": "` + strings.Repeat("a", maxBodySize) + `"}`, wantStatus: http.StatusBadRequest, wantCode: CodeBadRequest},
+		{name: "command failed", method: http.MethodPost, path: "/v1/broadcast", token: "moderator", body: `{"message": "hello"}`, fail: true, wantStatus: http.StatusUnprocessableEntity, wantCode: CodeCommandFailed, wantSent: "broadcast by discord-bot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := rcontest.NewServer(t)
+			srv.Reply("get playerids", rcontest.List("Bob : 76561198000000001"))
+
+			if tt.fail {
+				srv.Reply("broadcast", "FAIL")
+			}
+
+			c := srv.Conn(t)
+
+			var mu sync.Mutex
+			sent := []string{}
+
+			c.Use(func(next rcon.Handler) rcon.Handler {
+				return func(r rcon.Request) (string, error) {
+					if rcon.Mutating(r.Cmds) {
+						mu.Lock()
+						sent = append(sent, rcon.CommandName(r.Cmds)+" by "+r.Operator)
+						mu.Unlock()
+					}
+
+					return next(r)
+				}
+			})
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			w := httptest.NewRecorder()
+			New(c, tokens).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			if tt.wantCode != "" {
+				body := map[string]Error{}
+
+				err := json.Unmarshal(w.Body.Bytes(), &body)
+				if err != nil {
+					t.Fatalf("invalid error body %q: %v", w.Body, err)
+				}
+
+				if body["error"].Code != tt.wantCode || body["error"].Message == "" {
+					t.Errorf("error %+v, want code %s with a message", body["error"], tt.wantCode)
+				}
+			}
+
+			if tt.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("missing WWW-Authenticate header")
+			}
+
+			if tt.wantStatus == http.StatusMethodNotAllowed && w.Header().Get("Allow") != "GET" {
+				t.Errorf("Allow = %q, want GET", w.Header().Get("Allow"))
+			}
+
+			mu.Lock()
+			got := strings.Join(sent, ", ")
+			mu.Unlock()
+
+			if got != tt.wantSent {
+				t.Errorf("sent %q, want %q", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+// netError represents a network error, timed out or not.
+type netError struct {
+	timeout bool
+}
+
+func (e netError) Error() string   { return "connection reset" }
+func (e netError) Timeout() bool   { return e.timeout }
+func (e netError) Temporary() bool { return false }
+
+func TestToError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "api", err: badRequest("bad"), wantStatus: http.StatusBadRequest, wantCode: CodeBadRequest},
+		{name: "wrapped api", err: fmt.Errorf("failed: %w", &Error{Status: http.StatusNotFound, Code: CodeNotFound}), wantStatus: http.StatusNotFound, wantCode: CodeNotFound},
+		{name: "failed", err: fmt.Errorf("failed to kick: %w", rcon.ErrResultFailed), wantStatus: http.StatusUnprocessableEntity, wantCode: CodeCommandFailed},
+		{name: "timeout", err: fmt.Errorf("failed to kick: %w", netError{timeout: true}), wantStatus: http.StatusGatewayTimeout, wantCode: CodeTimeout},
+		{name: "unreachable", err: fmt.Errorf("failed to kick: %w", netError{}), wantStatus: http.StatusBadGateway, wantCode: CodeUnreachable},
+		{name: "other", err: errors.New("unexpected"), wantStatus: http.StatusInternalServerError, wantCode: CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := toError(tt.err)
+			if e.Status != tt.wantStatus || e.Code != tt.wantCode {
+				t.Errorf("toError() = %d %s, want %d %s", e.Status, e.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,575 @@
+package httpapi
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// endpoints returns every route of the API. The order is kept in the OpenAPI document.
+func (s *Server) endpoints() []*route {
+	return []*route{
+		{
+			method: http.MethodGet, path: "/v1/server", scope: ScopeRead,
+			summary:  "Get the server name and population",
+			response: ServerInfo{},
+			handle:   s.server,
+		},
+		{
+			method: http.MethodGet, path: "/v1/settings", scope: ScopeRead,
+			summary:  "Get the server settings",
+			response: Settings{},
+			handle:   s.settings,
+		},
+		{
+			method: http.MethodPatch, path: "/v1/settings", scope: ScopeOwner,
+			summary:  "Update the settings present in the body",
+			request:  Settings{},
+			response: Settings{},
+			handle:   s.updateSettings,
+		},
+		{
+			method: http.MethodPost, path: "/v1/settings/vote_kick_threshold/reset", scope: ScopeOwner,
+			summary: "Reset the vote kick threshold to the server default",
+			handle:  s.resetVoteKickThreshold,
+		},
+		{
+			method: http.MethodPost, path: "/v1/broadcast", scope: ScopeModerator,
+			summary: "Set the broadcast message",
+			request: MessageRequest{},
+			handle:  s.broadcast,
+		},
+		{
+			method: http.MethodGet, path: "/v1/players", scope: ScopeRead,
+			summary:  "List online players",
+			response: []rcon.Player{},
+			handle:   s.players,
+		},
+		{
+			method: http.MethodGet, path: "/v1/players/{player}", scope: ScopeRead,
+			summary:  "Get an online player by name or ID64",
+			response: rcon.Player{},
+			handle:   s.player,
+		},
+		{
+			method: http.MethodPost, path: "/v1/players/{player}/kick", scope: ScopeModerator,
+			summary: "Kick a player",
+			request: ReasonRequest{},
+			handle:  s.kick,
+		},
+		{
+			method: http.MethodPost, path: "/v1/players/{player}/punish", scope: ScopeModerator,
+			summary: "Punish a player",
+			request: ReasonRequest{},
+			handle:  s.punish,
+		},
+		{
+			method: http.MethodPost, path: "/v1/players/{player}/message", scope: ScopeModerator,
+			summary: "Send a message to a player",
+			request: MessageRequest{},
+			handle:  s.message,
+		},
+		{
+			method: http.MethodPost, path: "/v1/players/{player}/switch", scope: ScopeModerator,
+			summary: "Switch a player to the other team",
+			request: SwitchRequest{},
+			handle:  s.switchTeam,
+		},
+		{
+			method: http.MethodGet, path: "/v1/bans", scope: ScopeRead,
+			summary:  "List bans, optionally filtered with ?type=temp or ?type=perm",
+			response: []Ban{},
+			handle:   s.bans,
+		},
+		{
+			method: http.MethodPost, path: "/v1/bans", scope: ScopeModerator,
+			summary:  "Ban a player, permanently when no duration is given",
+			request:  BanRequest{},
+			response: Ban{},
+			status:   http.StatusCreated,
+			handle:   s.ban,
+		},
+		{
+			method: http.MethodDelete, path: "/v1/bans/{id64}", scope: ScopeModerator,
+			summary: "Remove a ban",
+			handle:  s.unban,
+		},
+		{
+			method: http.MethodGet, path: "/v1/admins", scope: ScopeRead,
+			summary:  "List admins",
+			response: []rcon.Admin{},
+			handle:   s.admins,
+		},
+		{
+			method: http.MethodGet, path: "/v1/admins/roles", scope: ScopeRead,
+			summary:  "List admin roles",
+			response: []string{},
+			handle:   s.roles,
+		},
+		{
+			method: http.MethodPost, path: "/v1/admins", scope: ScopeOwner,
+			summary:  "Add an admin",
+			request:  rcon.Admin{},
+			response: rcon.Admin{},
+			status:   http.StatusCreated,
+			handle:   s.addAdmin,
+		},
+		{
+			method: http.MethodDelete, path: "/v1/admins/{id64}", scope: ScopeOwner,
+			summary: "Remove an admin",
+			handle:  s.removeAdmin,
+		},
+		{
+			method: http.MethodGet, path: "/v1/vips", scope: ScopeRead,
+			summary:  "List VIPs",
+			response: []rcon.VIP{},
+			handle:   s.vips,
+		},
+		{
+			method: http.MethodPost, path: "/v1/vips", scope: ScopeOwner,
+			summary:  "Add a VIP",
+			request:  rcon.VIP{},
+			response: rcon.VIP{},
+			status:   http.StatusCreated,
+			handle:   s.addVIP,
+		},
+		{
+			method: http.MethodDelete, path: "/v1/vips/{id64}", scope: ScopeOwner,
+			summary: "Remove a VIP",
+			handle:  s.removeVIP,
+		},
+		{
+			method: http.MethodGet, path: "/v1/map", scope: ScopeRead,
+			summary:  "Get the current map",
+			response: Map{},
+			handle:   s.currentMap,
+		},
+		{
+			method: http.MethodPut, path: "/v1/map", scope: ScopeOwner,
+			summary: "Change the current map",
+			request: MapRequest{},
+			handle:  s.setMap,
+		},
+		{
+			method: http.MethodGet, path: "/v1/maps", scope: ScopeRead,
+			summary:  "List every available map",
+			response: []Map{},
+			handle:   s.maps,
+		},
+		{
+			method: http.MethodGet, path: "/v1/rotation", scope: ScopeRead,
+			summary:  "List the map rotation",
+			response: []Map{},
+			handle:   s.rotation,
+		},
+		{
+			method: http.MethodPut, path: "/v1/rotation", scope: ScopeOwner,
+			summary:  "Replace the map rotation, returning the operations applied",
+			request:  RotationRequest{},
+			response: []Operation{},
+			handle:   s.setRotation,
+		},
+		{
+			method: http.MethodGet, path: "/v1/profanities", scope: ScopeRead,
+			summary:  "List censored words",
+			response: []string{},
+			handle:   s.profanities,
+		},
+		{
+			method: http.MethodPut, path: "/v1/profanities", scope: ScopeOwner,
+			summary:  "Replace the censored words, returning the operations applied",
+			request:  WordsRequest{},
+			response: []Operation{},
+			handle:   s.setProfanities,
+		},
+		{
+			method: http.MethodPost, path: "/v1/commands", scope: ScopeOwner,
+			summary:  "Send a raw command",
+			request:  CommandRequest{},
+			response: CommandResponse{},
+			handle:   s.command,
+		},
+		{
+			method: http.MethodGet, path: "/v1/openapi.json", scope: ScopeRead,
+			summary: "Get the OpenAPI document of the API",
+			handle:  s.openapi,
+		},
+	}
+}
+
+func (s *Server) server(r *request) (interface{}, error) {
+	name, err := r.conn.Name()
+	if err != nil {
+		return nil, err
+	}
+
+	players, slots, err := r.conn.Slots()
+	if err != nil {
+		return nil, err
+	}
+
+	return ServerInfo{Name: name, Players: players, Slots: slots}, nil
+}
+
+func (s *Server) settings(r *request) (interface{}, error) {
+	settings, err := r.conn.Settings()
+	if err != nil {
+		return nil, err
+	}
+
+	return settingsFrom(settings), nil
+}
+
+func (s *Server) updateSettings(r *request) (interface{}, error) {
+	body := Settings{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.conn.SetSettings(body.settings())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.settings(r)
+}
+
+func (s *Server) resetVoteKickThreshold(r *request) (interface{}, error) {
+	return nil, r.conn.ResetVoteKickThreshold()
+}
+
+func (s *Server) broadcast(r *request) (interface{}, error) {
+	body := MessageRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, r.conn.SetBroadcast(body.Message)
+}
+
+func (s *Server) players(r *request) (interface{}, error) {
+	return r.conn.Players()
+}
+
+func (s *Server) player(r *request) (interface{}, error) {
+	return r.resolve(r.params["player"])
+}
+
+func (s *Server) kick(r *request) (interface{}, error) {
+	return s.withReason(r, r.conn.Kick)
+}
+
+func (s *Server) punish(r *request) (interface{}, error) {
+	return s.withReason(r, r.conn.Punish)
+}
+
+func (s *Server) withReason(r *request, fn func(p rcon.Player, reason string) error) (interface{}, error) {
+	body := ReasonRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := r.resolve(r.params["player"])
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, fn(p, body.Reason)
+}
+
+func (s *Server) message(r *request) (interface{}, error) {
+	body := MessageRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := r.resolve(r.params["player"])
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, r.conn.Message(p, body.Message)
+}
+
+func (s *Server) switchTeam(r *request) (interface{}, error) {
+	body := SwitchRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := r.resolve(r.params["player"])
+	if err != nil {
+		return nil, err
+	}
+
+	if body.OnDeath {
+		return nil, r.conn.SetSwitchTeamOnDeath(p)
+	}
+
+	return nil, r.conn.SetSwitchTeamNow(p)
+}
+
+func (s *Server) bans(r *request) (interface{}, error) {
+	kind := r.URL.Query().Get("type")
+
+	bans := []Ban{}
+
+	switch kind {
+	case "", "temp", "perm":
+	default:
+		return nil, badRequest("unknown ban type %q, expected temp or perm", kind)
+	}
+
+	if kind != "perm" {
+		temp, err := r.conn.BannedTemporarily()
+		if err != nil {
+			return nil, err
+		}
+
+		bans = append(bans, bansFrom("temp", temp)...)
+	}
+
+	if kind != "temp" {
+		perm, err := r.conn.BannedPermanently()
+		if err != nil {
+			return nil, err
+		}
+
+		bans = append(bans, bansFrom("perm", perm)...)
+	}
+
+	return bans, nil
+}
+
+func (s *Server) ban(r *request) (interface{}, error) {
+	body := BanRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.ID64 == "" {
+		return nil, badRequest("id64 is required")
+	}
+
+	p := rcon.Player{ID64: body.ID64}
+
+	ban := Ban{
+		Type:     "perm",
+		ID64:     body.ID64,
+		Reason:   body.Reason,
+		Issued:   time.Now().UTC(),
+		Duration: body.Duration,
+		Admin:    r.token.Name,
+	}
+
+	if body.Duration == nil {
+		return ban, r.conn.BanPermanently(p, body.Reason, r.token.Name)
+	}
+
+	if *body.Duration <= 0 {
+		return nil, badRequest("duration must be positive")
+	}
+
+	// The server bans in whole hours, so partial hours are rounded up.
+	hours := int(math.Ceil(time.Duration(*body.Duration).Hours()))
+	d := Duration(time.Duration(hours) * time.Hour)
+
+	ban.Type = "temp"
+	ban.Duration = &d
+
+	return ban, r.conn.BanTemporarily(p, hours, body.Reason, r.token.Name)
+}
+
+func (s *Server) unban(r *request) (interface{}, error) {
+	return nil, r.conn.BanRemove(rcon.Player{ID64: r.params["id64"]})
+}
+
+func (s *Server) admins(r *request) (interface{}, error) {
+	return r.conn.Admins()
+}
+
+func (s *Server) roles(r *request) (interface{}, error) {
+	return r.conn.AdminGroups()
+}
+
+func (s *Server) addAdmin(r *request) (interface{}, error) {
+	body := rcon.Admin{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.ID64 == "" || body.Role == "" {
+		return nil, badRequest("id64 and role are required")
+	}
+
+	return body, r.conn.AdminAdd(body)
+}
+
+func (s *Server) removeAdmin(r *request) (interface{}, error) {
+	return nil, r.conn.AdminRemove(rcon.Admin{Player: rcon.Player{ID64: r.params["id64"]}})
+}
+
+func (s *Server) vips(r *request) (interface{}, error) {
+	return r.conn.VIPs()
+}
+
+func (s *Server) addVIP(r *request) (interface{}, error) {
+	body := rcon.VIP{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.ID64 == "" {
+		return nil, badRequest("id64 is required")
+	}
+
+	return body, r.conn.VIPAdd(body)
+}
+
+func (s *Server) removeVIP(r *request) (interface{}, error) {
+	return nil, r.conn.VIPRemove(rcon.VIP{Player: rcon.Player{ID64: r.params["id64"]}})
+}
+
+func (s *Server) currentMap(r *request) (interface{}, error) {
+	m, err := r.conn.Map()
+	if err != nil {
+		return nil, err
+	}
+
+	return mapFrom(m), nil
+}
+
+func (s *Server) setMap(r *request) (interface{}, error) {
+	body := MapRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.Name == "" {
+		return nil, badRequest("name is required")
+	}
+
+	return nil, r.conn.SetMap(body.Name)
+}
+
+func (s *Server) maps(r *request) (interface{}, error) {
+	maps, err := r.conn.Maps()
+	if err != nil {
+		return nil, err
+	}
+
+	return mapsFrom(maps), nil
+}
+
+func (s *Server) rotation(r *request) (interface{}, error) {
+	maps, err := r.conn.Rotation()
+	if err != nil {
+		return nil, err
+	}
+
+	return mapsFrom(maps), nil
+}
+
+func (s *Server) setRotation(r *request) (interface{}, error) {
+	body := RotationRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body.Maps) == 0 {
+		return nil, badRequest("maps must not be empty")
+	}
+
+	return r.reconcile(rcon.State{Rotation: body.Maps})
+}
+
+func (s *Server) profanities(r *request) (interface{}, error) {
+	return r.conn.Profanities()
+}
+
+func (s *Server) setProfanities(r *request) (interface{}, error) {
+	body := WordsRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.Words == nil {
+		body.Words = []string{}
+	}
+
+	return r.reconcile(rcon.State{Profanities: body.Words})
+}
+
+func (s *Server) command(r *request) (interface{}, error) {
+	body := CommandRequest{}
+
+	err := r.decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	if body.Command == "" {
+		return nil, badRequest("command is required")
+	}
+
+	result, err := r.conn.Send(body.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	return CommandResponse{Result: result}, nil
+}
+
+func (s *Server) openapi(r *request) (interface{}, error) {
+	return s.document(), nil
+}
+
+func (r *request) reconcile(desired rcon.State) (interface{}, error) {
+	p, err := rcon.Reconcile(r.conn, desired, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return operationsFrom(p), nil
+}
+
+// resolve will find an online player by exact ID64 or name.
+func (r *request) resolve(arg string) (rcon.Player, error) {
+	players, err := r.conn.Players()
+	if err != nil {
+		return rcon.Player{}, err
+	}
+
+	for _, p := range players {
+		if p.ID64 == arg || p.Name == arg {
+			return p, nil
+		}
+	}
+
+	return rcon.Player{}, &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: fmt.Sprintf("player %s is not online", arg)}
+}
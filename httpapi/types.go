@@ -0,0 +1,227 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Duration represents a time.Duration written as a string in JSON, e.g. "15m0s".
+type Duration time.Duration
+
+// ServerInfo represents the identity and population of a server.
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Players int    `json:"players"`
+	Slots   int    `json:"slots"`
+}
+
+// Settings represents the configurable gameplay parameters of a server. Fields left out of an
+// update are unchanged.
+type Settings struct {
+	IdleTime             *Duration                `json:"idle_time,omitempty"`
+	MaxPing              *Duration                `json:"max_ping,omitempty"`
+	AutoBalance          *bool                    `json:"auto_balance,omitempty"`
+	AutoBalanceThreshold *int                     `json:"auto_balance_threshold,omitempty"`
+	SwitchTeamCooldown   *Duration                `json:"switch_team_cooldown,omitempty"`
+	QueueLength          *int                     `json:"queue_length,omitempty"`
+	VIPSlots             *int                     `json:"vip_slots,omitempty"`
+	VoteKick             *bool                    `json:"vote_kick,omitempty"`
+	VoteKickThreshold    []rcon.VoteKickThreshold `json:"vote_kick_threshold,omitempty"`
+}
+
+// Map represents a playable map.
+type Map struct {
+	Name     rcon.MapName `json:"name"`
+	Location string       `json:"location"`
+	Type     string       `json:"type"`
+	Side     string       `json:"side,omitempty"`
+}
+
+// Ban represents a temporary or permanent ban.
+type Ban struct {
+	Type     string    `json:"type"` // Either "temp" or "perm".
+	Name     string    `json:"name"`
+	ID64     string    `json:"id64"`
+	Reason   string    `json:"reason"`
+	Issued   time.Time `json:"issued"`
+	Duration *Duration `json:"duration,omitempty"`
+	Admin    string    `json:"admin"`
+}
+
+// BanRequest represents a new ban. Bans without a duration are permanent.
+type BanRequest struct {
+	ID64     string    `json:"id64"`
+	Reason   string    `json:"reason"`
+	Duration *Duration `json:"duration,omitempty"`
+}
+
+// ReasonRequest represents an action against a player with a reason shown to them.
+type ReasonRequest struct {
+	Reason string `json:"reason"`
+}
+
+// MessageRequest represents a message shown to players.
+type MessageRequest struct {
+	Message string `json:"message"`
+}
+
+// SwitchRequest represents a team switch, either immediately or on the player's next death.
+type SwitchRequest struct {
+	OnDeath bool `json:"on_death"`
+}
+
+// MapRequest represents a map change.
+type MapRequest struct {
+	Name rcon.MapName `json:"name"`
+}
+
+// RotationRequest represents the desired map rotation.
+type RotationRequest struct {
+	Maps []rcon.MapName `json:"maps"`
+}
+
+// WordsRequest represents the desired list of censored words.
+type WordsRequest struct {
+	Words []string `json:"words"`
+}
+
+// CommandRequest represents a raw command sent to the server.
+type CommandRequest struct {
+	Command string `json:"command"`
+}
+
+// CommandResponse represents the raw response to a command.
+type CommandResponse struct {
+	Result string `json:"result"`
+}
+
+// Operation represents a single change made while reconciling the server with a desired state.
+type Operation struct {
+	Kind     string `json:"kind"`
+	Resource string `json:"resource"`
+	Target   string `json:"target"`
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	s := ""
+
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return fmt.Errorf("duration must be a string, e.g. \"15m\"")
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+
+	return nil
+}
+
+func settingsFrom(s rcon.Settings) Settings {
+	return Settings{
+		IdleTime:             durationFrom(s.IdleTime),
+		MaxPing:              durationFrom(s.MaxPing),
+		AutoBalance:          s.AutoBalance,
+		AutoBalanceThreshold: s.AutoBalanceThreshold,
+		SwitchTeamCooldown:   durationFrom(s.SwitchTeamCooldown),
+		QueueLength:          s.QueueLength,
+		VIPSlots:             s.VIPSlots,
+		VoteKick:             s.VoteKick,
+		VoteKickThreshold:    s.VoteKickThreshold,
+	}
+}
+
+func (s Settings) settings() rcon.Settings {
+	return rcon.Settings{
+		IdleTime:             s.IdleTime.duration(),
+		MaxPing:              s.MaxPing.duration(),
+		AutoBalance:          s.AutoBalance,
+		AutoBalanceThreshold: s.AutoBalanceThreshold,
+		SwitchTeamCooldown:   s.SwitchTeamCooldown.duration(),
+		QueueLength:          s.QueueLength,
+		VIPSlots:             s.VIPSlots,
+		VoteKick:             s.VoteKick,
+		VoteKickThreshold:    s.VoteKickThreshold,
+	}
+}
+
+func durationFrom(d *time.Duration) *Duration {
+	if d == nil {
+		return nil
+	}
+
+	v := Duration(*d)
+
+	return &v
+}
+
+func (d *Duration) duration() *time.Duration {
+	if d == nil {
+		return nil
+	}
+
+	v := time.Duration(*d)
+
+	return &v
+}
+
+func mapsFrom(maps []rcon.Map) []Map {
+	list := []Map{}
+	for _, m := range maps {
+		list = append(list, mapFrom(m))
+	}
+
+	return list
+}
+
+func mapFrom(m rcon.Map) Map {
+	return Map{
+		Name:     m.MapName,
+		Location: m.Location,
+		Type:     m.Type,
+		Side:     m.Side,
+	}
+}
+
+func bansFrom(kind string, bans []rcon.Ban) []Ban {
+	list := []Ban{}
+
+	for _, b := range bans {
+		ban := Ban{
+			Type:   kind,
+			Name:   b.Player.Name,
+			ID64:   b.Player.ID64,
+			Reason: b.Reason,
+			Issued: b.Time,
+			Admin:  b.Admin.Name,
+		}
+
+		if b.Duration > 0 {
+			d := Duration(b.Duration)
+			ban.Duration = &d
+		}
+
+		list = append(list, ban)
+	}
+
+	return list
+}
+
+func operationsFrom(p rcon.Plan) []Operation {
+	list := []Operation{}
+	for _, op := range p {
+		list = append(list, Operation{Kind: op.Kind, Resource: op.Resource, Target: op.Target})
+	}
+
+	return list
+}
@@ -0,0 +1,25 @@
+// Package consume runs the loop shared by the helpers reading log Events from a channel.
+package consume
+
+import (
+	"context"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Events will call fn with the Events of a channel, e.g. a LogStream subscription, until it is
+// closed, returning nil, or ctx is done, returning the error of ctx.
+func Events(ctx context.Context, events <-chan rcon.Event, fn func(rcon.Event)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			fn(e)
+		}
+	}
+}
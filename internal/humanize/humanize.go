@@ -0,0 +1,50 @@
+// Package humanize formats values for messages shown to players.
+package humanize
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Duration will format a duration in its two largest units, e.g. "2 days 5 hours", or in seconds
+// when shorter than a minute.
+func Duration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%s %s", Plural(days, "day"), Plural(hours, "hour"))
+	case days > 0:
+		return Plural(days, "day")
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%s %s", Plural(hours, "hour"), Plural(minutes, "minute"))
+	case hours > 0:
+		return Plural(hours, "hour")
+	case d > 0 && d < time.Minute:
+		return Plural(int((d+time.Second-1)/time.Second), "second")
+	default:
+		return Plural(minutes, "minute")
+	}
+}
+
+// Plural will format a count with a unit, pluralized when the count is not one.
+func Plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+// Map returns the readable name of a Map, or its MapName when unknown.
+func Map(m rcon.Map) string {
+	if m.Location == "" {
+		return m.MapName.String()
+	}
+
+	return m.String()
+}
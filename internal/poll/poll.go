@@ -0,0 +1,24 @@
+// Package poll runs the periodic loop shared by the helpers polling a server.
+package poll
+
+import (
+	"context"
+	"time"
+)
+
+// Every will call fn immediately and then every interval until ctx is done, returning the error of
+// ctx.
+func Every(ctx context.Context, interval time.Duration, fn func()) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		fn()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package rcontest provides an in-process HLL RCON server for tests, in the spirit of httptest.
+//
+// Commands are routed by their rcon.CommandName to handlers, e.g. "get vipids" or "kick". Commands
+// without a handler answer "SUCCESS", except "get" commands, which answer "FAIL".
+package rcontest
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Password is the password accepted by a Server.
+const Password = "rcontest"
+
+// key is the XOR key sent to clients.
+var key = []byte("rcontest")
+
+// HandlerFunc answers a command given its arguments, unquoted.
+type HandlerFunc func(args []string) string
+
+// Server represents a fake server listening on a local port.
+type Server struct {
+	Addr string
+
+	mu       sync.Mutex
+	l        net.Listener
+	handlers map[string]HandlerFunc
+	sent     []string
+}
+
+// NewServer starts a Server, closed with the test.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{
+		Addr:     l.Addr().String(),
+		l:        l,
+		handlers: map[string]HandlerFunc{},
+	}
+
+	go s.serve()
+
+	t.Cleanup(func() { l.Close() })
+
+	return s
+}
+
+// Conn returns a Conn logged in to the Server, closed with the test.
+func (s *Server) Conn(t testing.TB) *rcon.Conn {
+	t.Helper()
+
+	c, err := rcon.New(s.Addr, Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+// Handle will answer the commands named name with fn, replacing any previous handler.
+func (s *Server) Handle(name string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[name] = fn
+}
+
+// Reply will answer the commands named name with result.
+func (s *Server) Reply(name, result string) {
+	s.Handle(name, func([]string) string { return result })
+}
+
+// Sent returns the commands received since the last call to Reset, except logins.
+func (s *Server) Sent() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.sent...)
+}
+
+// Reset will forget the commands received.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = nil
+}
+
+// List returns items in the tab separated format of list responses, e.g. "get vipids".
+func List(items ...string) string {
+	return strings.Join(append([]string{strconv.Itoa(len(items))}, items...), "\t") + "\t"
+}
+
+func (s *Server) serve() {
+	for {
+		c, err := s.l.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.session(c)
+	}
+}
+
+func (s *Server) session(c net.Conn) {
+	defer c.Close()
+
+	_, err := c.Write(key)
+	if err != nil {
+		return
+	}
+
+	b := make([]byte, 8196)
+
+	for {
+		n, err := c.Read(b)
+		if err != nil {
+			return
+		}
+
+		_, err = c.Write(xor([]byte(s.handle(string(xor(b[:n]))))))
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handle(line string) string {
+	if strings.HasPrefix(line, "login ") {
+		if line == "login "+Password {
+			return "SUCCESS"
+		}
+
+		return "FAIL"
+	}
+
+	cmds := []string{line}
+	name := rcon.CommandName(cmds)
+
+	args := rcon.Arguments(cmds)
+	if strings.HasPrefix(name, "get ") && len(args) > 0 {
+		args = args[1:]
+	}
+
+	s.mu.Lock()
+	s.sent = append(s.sent, line)
+	fn, ok := s.handlers[name]
+	s.mu.Unlock()
+
+	switch {
+	case ok:
+		return fn(args)
+	case strings.HasPrefix(name, "get "):
+		return "FAIL"
+	default:
+		return "SUCCESS"
+	}
+}
+
+func xor(b []byte) []byte {
+	d := make([]byte, len(b))
+
+	for i := range b {
+		d[i] = b[i] ^ key[i%len(key)]
+	}
+
+	return d
+}
@@ -0,0 +1,60 @@
+// Package roles keeps the admins and VIPs of a server for the helpers that treat them differently,
+// e.g. a chat bot checking permissions.
+package roles
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Cache represents the admins and VIPs of a server, refreshed once older than a TTL. The zero
+// value is an empty Cache.
+type Cache struct {
+	mu      sync.Mutex
+	admins  map[string]bool
+	vips    map[string]bool
+	fetched time.Time
+}
+
+// Get returns the admins and VIPs of the server keyed by ID64, refreshed when older than ttl. The
+// previous lists are returned with the error of a failed refresh. The lock is not held while
+// refreshing, so other callers are not blocked by the server.
+func (r *Cache) Get(c *rcon.Conn, ttl time.Duration, now time.Time) (admins, vips map[string]bool, err error) {
+	r.mu.Lock()
+	admins, vips = r.admins, r.vips
+	fresh := admins != nil && now.Sub(r.fetched) < ttl
+	r.mu.Unlock()
+
+	if fresh {
+		return admins, vips, nil
+	}
+
+	list, err := c.Admins()
+	if err != nil {
+		return admins, vips, fmt.Errorf("failed to get admins: %w", err)
+	}
+
+	members, err := c.VIPs()
+	if err != nil {
+		return admins, vips, fmt.Errorf("failed to get VIPs: %w", err)
+	}
+
+	admins = map[string]bool{}
+	for _, a := range list {
+		admins[a.ID64] = true
+	}
+
+	vips = map[string]bool{}
+	for _, v := range members {
+		vips[v.ID64] = true
+	}
+
+	r.mu.Lock()
+	r.admins, r.vips, r.fetched = admins, vips, now
+	r.mu.Unlock()
+
+	return admins, vips, nil
+}
@@ -0,0 +1,217 @@
+package rcon
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EventKind represents the kind of a log Event.
+type EventKind string
+
+// Event kinds parsed from the server log. Lines that are not understood are kept as EventOther.
+const (
+	EventKill         EventKind = "kill"
+	EventTeamKill     EventKind = "teamkill"
+	EventChat         EventKind = "chat"
+	EventConnected    EventKind = "connected"
+	EventDisconnected EventKind = "disconnected"
+	EventTeamSwitch   EventKind = "teamswitch"
+	EventMatchStart   EventKind = "match_start"
+	EventMatchEnd     EventKind = "match_end"
+	EventOther        EventKind = "other"
+)
+
+// Teams as written in the server log.
+const (
+	TeamAllies = "Allies"
+	TeamAxis   = "Axis"
+)
+
+// Event represents a single line of the server log. Only the fields relevant to its Kind are set.
+type Event struct {
+	Kind EventKind `json:"kind"`
+	Time time.Time `json:"time"`
+
+	// Player is the killer, the author of a chat message, or the player connecting, leaving or
+	// switching team. Team is the team of Player, or the team switched to.
+	Player Player `json:"player"`
+	Team   string `json:"team,omitempty"`
+
+	// Victim is the player killed, and VictimTeam their team.
+	Victim     Player `json:"victim"`
+	VictimTeam string `json:"victim_team,omitempty"`
+	Weapon     string `json:"weapon,omitempty"`
+
+	// Channel is the chat channel of a message, either "Team" or "Unit".
+	Channel string `json:"channel,omitempty"`
+
+	// Message is the chat message, the map of a match event, or the unparsed text of EventOther.
+	Message string `json:"message,omitempty"`
+
+	// AlliedScore and AxisScore are the final score of EventMatchEnd.
+	AlliedScore int `json:"allied_score,omitempty"`
+	AxisScore   int `json:"axis_score,omitempty"`
+
+	Raw string `json:"raw"`
+}
+
+var (
+	matchLogLine    = regexp.MustCompile(`^\[[^\]]*\((\d+)\)\] (.*)$`)
+	matchKill       = regexp.MustCompile(`^(TEAM )?KILL: (.+)\((Allies|Axis)/([^)]*)\) -> (.+)\((Allies|Axis)/([^)]*)\) with (.+)$`)
+	matchChat       = regexp.MustCompile(`^CHAT\[(Team|Unit)\]\[(.+)\((Allies|Axis)/([^)]*)\)\]: (.*)$`)
+	matchConnected  = regexp.MustCompile(`^(CONNECTED|DISCONNECTED) (.+) \(([^)]*)\)$`)
+	matchTeamSwitch = regexp.MustCompile(`^TEAMSWITCH (.+) \((\S*) > (\S*)\)$`)
+	matchStart      = regexp.MustCompile(`^MATCH START (.+)$`)
+	matchEnd        = regexp.MustCompile("^MATCH ENDED `(.+)` ALLIED \\((\\d+) - (\\d+)\\) AXIS$")
+)
+
+// Logs returns the Events written to the server log within a duration, rounded up to whole minutes.
+// Responses are limited to a single read, so long durations on busy servers may be truncated.
+func (c *Conn) Logs(since time.Duration) ([]Event, error) {
+	minutes := int(math.Ceil(since.Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	result, err := c.send("showlog", strconv.Itoa(minutes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logs: %w", err)
+	}
+
+	return parseLogs(result), nil
+}
+
+// parseLogs will parse the lines of a showlog response. Lines without a timestamp continue the
+// previous Event, as chat messages may span several lines.
+func parseLogs(s string) []Event {
+	events := []Event{}
+
+	s = strings.TrimSpace(s)
+	if s == "" || s == "EMPTY" {
+		return events
+	}
+
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		args := matchLogLine.FindStringSubmatch(line)
+		if len(args) == 0 {
+			if len(events) > 0 {
+				e := &events[len(events)-1]
+				e.Raw += "\n" + line
+
+				if e.Kind == EventChat || e.Kind == EventOther {
+					e.Message += "\n" + line
+				}
+			}
+
+			continue
+		}
+
+		unix, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		e := parseEvent(args[2])
+		e.Time = time.Unix(unix, 0).UTC()
+		e.Raw = line
+
+		events = append(events, e)
+	}
+
+	return events
+}
+
+func parseEvent(s string) Event {
+	if args := matchKill.FindStringSubmatch(s); len(args) > 0 {
+		kind := EventKill
+		if args[1] != "" {
+			kind = EventTeamKill
+		}
+
+		return Event{
+			Kind:       kind,
+			Player:     Player{Name: args[2], ID64: args[4]},
+			Team:       args[3],
+			Victim:     Player{Name: args[5], ID64: args[7]},
+			VictimTeam: args[6],
+			Weapon:     args[8],
+		}
+	}
+
+	if args := matchChat.FindStringSubmatch(s); len(args) > 0 {
+		return Event{
+			Kind:    EventChat,
+			Channel: args[1],
+			Player:  Player{Name: args[2], ID64: args[4]},
+			Team:    args[3],
+			Message: args[5],
+		}
+	}
+
+	if args := matchConnected.FindStringSubmatch(s); len(args) > 0 {
+		kind := EventConnected
+		if args[1] == "DISCONNECTED" {
+			kind = EventDisconnected
+		}
+
+		return Event{
+			Kind:   kind,
+			Player: Player{Name: args[2], ID64: args[3]},
+		}
+	}
+
+	if args := matchTeamSwitch.FindStringSubmatch(s); len(args) > 0 {
+		return Event{
+			Kind:   EventTeamSwitch,
+			Player: Player{Name: args[1]},
+			Team:   args[3],
+		}
+	}
+
+	if args := matchEnd.FindStringSubmatch(s); len(args) > 0 {
+		allied, _ := strconv.Atoi(args[2])
+		axis, _ := strconv.Atoi(args[3])
+
+		return Event{
+			Kind:        EventMatchEnd,
+			Message:     args[1],
+			AlliedScore: allied,
+			AxisScore:   axis,
+		}
+	}
+
+	if args := matchStart.FindStringSubmatch(s); len(args) > 0 {
+		return Event{
+			Kind:    EventMatchStart,
+			Message: args[1],
+		}
+	}
+
+	return Event{
+		Kind:    EventOther,
+		Message: s,
+	}
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case EventKill, EventTeamKill:
+		return fmt.Sprintf("%s: %s -> %s with %s", e.Kind, e.Player, e.Victim, e.Weapon)
+	case EventChat:
+		return fmt.Sprintf("chat[%s] %s: %s", e.Channel, e.Player, e.Message)
+	case EventConnected, EventDisconnected:
+		return fmt.Sprintf("%s: %s", e.Kind, e.Player)
+	case EventTeamSwitch:
+		return fmt.Sprintf("%s: %s to %s", e.Kind, e.Player.Name, e.Team)
+	case EventMatchEnd:
+		return fmt.Sprintf("%s: %s allied %d - %d axis", e.Kind, e.Message, e.AlliedScore, e.AxisScore)
+	default:
+		return fmt.Sprintf("%s: %s", e.Kind, e.Message)
+	}
+}
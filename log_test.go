@@ -0,0 +1,127 @@
+package rcon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLogs(t *testing.T) {
+	at := time.Unix(1792440000, 0).UTC()
+
+	bob := Player{Name: "Bob", ID64: "76561198000000001"}
+	eve := Player{Name: "Eve (2)", ID64: "76561198000000002"}
+
+	tests := []struct {
+		name string
+		in   string
+		want []Event
+	}{
+		{
+			name: "empty",
+			in:   "EMPTY",
+			want: []Event{},
+		},
+		{
+			name: "kill",
+			in:   "[1:02 min (1792440000)] KILL: Bob(Allies/76561198000000001) -> Eve (2)(Axis/76561198000000002) with M1 GARAND",
+			want: []Event{{
+				Kind:       EventKill,
+				Player:     bob,
+				Team:       TeamAllies,
+				Victim:     eve,
+				VictimTeam: TeamAxis,
+				Weapon:     "M1 GARAND",
+			}},
+		},
+		{
+			name: "team kill",
+			in:   "[1:02 min (1792440000)] TEAM KILL: Bob(Allies/76561198000000001) -> Eve (2)(Allies/76561198000000002) with MK2 GRENADE",
+			want: []Event{{
+				Kind:       EventTeamKill,
+				Player:     bob,
+				Team:       TeamAllies,
+				Victim:     eve,
+				VictimTeam: TeamAllies,
+				Weapon:     "MK2 GRENADE",
+			}},
+		},
+		{
+			name: "chat",
+			in:   "[1:02 min (1792440000)] CHAT[Unit][Bob(Allies/76561198000000001)]: push B: now\nplease\r\n",
+			want: []Event{{
+				Kind:    EventChat,
+				Channel: "Unit",
+				Player:  bob,
+				Team:    TeamAllies,
+				Message: "push B: now\nplease",
+			}},
+		},
+		{
+			name: "connections",
+			in: "[1:02 min (1792440000)] CONNECTED Eve (2) (76561198000000002)\n" +
+				"[1:02 min (1792440000)] DISCONNECTED Bob (76561198000000001)\n" +
+				"[1:02 min (1792440000)] TEAMSWITCH Eve (2) (None > Axis)",
+			want: []Event{
+				{Kind: EventConnected, Player: eve},
+				{Kind: EventDisconnected, Player: bob},
+				{Kind: EventTeamSwitch, Player: Player{Name: "Eve (2)"}, Team: TeamAxis},
+			},
+		},
+		{
+			name: "match",
+			in: "[1:02 min (1792440000)] MATCH START FOY Warfare\n" +
+				"[1:02 min (1792440000)] MATCH ENDED `FOY Warfare` ALLIED (2 - 3) AXIS",
+			want: []Event{
+				{Kind: EventMatchStart, Message: "FOY Warfare"},
+				{Kind: EventMatchEnd, Message: "FOY Warfare", AlliedScore: 2, AxisScore: 3},
+			},
+		},
+		{
+			name: "other",
+			in:   "continued before the first event\n[1:02 min (1792440000)] VOTESYS: Player [Bob] Started a vote\nof type (PVR_Kick_Abuse)",
+			want: []Event{{
+				Kind:    EventOther,
+				Message: "VOTESYS: Player [Bob] Started a vote\nof type (PVR_Kick_Abuse)",
+			}},
+		},
+		{
+			// A continuation line is part of the raw line, but not of the message of a kill.
+			name: "kill continued",
+			in:   "[1:02 min (1792440000)] KILL: Bob(Allies/76561198000000001) -> Eve (2)(Axis/76561198000000002) with M1 GARAND\nextra",
+			want: []Event{{
+				Kind:       EventKill,
+				Player:     bob,
+				Team:       TeamAllies,
+				Victim:     eve,
+				VictimTeam: TeamAxis,
+				Weapon:     "M1 GARAND",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLogs(tt.in)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseLogs() = %+v, want %d events", got, len(tt.want))
+			}
+
+			for i, want := range tt.want {
+				if !got[i].Time.Equal(at) {
+					t.Errorf("parseLogs()[%d].Time = %v, want %v", i, got[i].Time, at)
+				}
+
+				if got[i].Raw == "" {
+					t.Errorf("parseLogs()[%d].Raw is empty", i)
+				}
+
+				got[i].Time, got[i].Raw = time.Time{}, ""
+
+				if got[i] != want {
+					t.Errorf("parseLogs()[%d] = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
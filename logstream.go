@@ -0,0 +1,187 @@
+package rcon
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LogStream represents a live feed of Events, polled from the server log and delivered to every
+// subscriber. Events already delivered are skipped when log windows overlap between polls.
+type LogStream struct {
+	Conn *Conn
+
+	// Lookback is how far back the first poll reads the log. When zero, only Events written after
+	// the first poll are delivered.
+	Lookback time.Duration
+
+	// OnError is called with errors from polls in Run, which are otherwise retried silently.
+	OnError func(error)
+
+	mu   sync.Mutex
+	subs map[*subscription]bool
+
+	poll    sync.Mutex // Held for a whole poll, so Events are delivered in order.
+	started bool
+	last    time.Time      // Time of the newest delivered Event.
+	seen    map[string]int // Lines delivered with the time of last, as the log has second precision.
+	window  time.Duration  // Log window read on each poll.
+	now     func() time.Time
+}
+
+type subscription struct {
+	mu   sync.Mutex // Held while sending, so ch is never closed mid-send.
+	ch   chan Event
+	done chan struct{}
+}
+
+// NewLogStream returns a LogStream for a Conn.
+func NewLogStream(c *Conn) *LogStream {
+	return &LogStream{
+		Conn: c,
+		subs: map[*subscription]bool{},
+		seen: map[string]int{},
+		now:  time.Now,
+	}
+}
+
+// Subscribe returns a channel receiving every new Event, and a function to cancel the subscription
+// and close the channel. Delivery blocks while the buffer is full, so subscribers must keep up.
+func (s *LogStream) Subscribe(buffer int) (<-chan Event, func()) {
+	sub := &subscription{
+		ch:   make(chan Event, buffer),
+		done: make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	s.subs[sub] = true
+	s.mu.Unlock()
+
+	var once sync.Once
+
+	return sub.ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, sub)
+			s.mu.Unlock()
+
+			close(sub.done)
+
+			sub.mu.Lock()
+			close(sub.ch)
+			sub.mu.Unlock()
+		})
+	}
+}
+
+// Run will poll the server log at an interval until the context is cancelled.
+func (s *LogStream) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	s.poll.Lock()
+	s.window = interval + time.Minute
+	s.poll.Unlock()
+
+	for {
+		_, err := s.Poll()
+		if err != nil && s.OnError != nil {
+			s.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Poll will read the server log once, delivering and returning the Events not seen before.
+func (s *LogStream) Poll() ([]Event, error) {
+	s.poll.Lock()
+	defer s.poll.Unlock()
+
+	window := s.window
+	if window == 0 {
+		window = time.Minute
+	}
+
+	if !s.started && s.Lookback > window {
+		window = s.Lookback
+	}
+
+	events, err := s.Conn.Logs(window)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.started {
+		s.started = true
+		s.last = s.now().Add(-s.Lookback).Truncate(time.Second)
+	}
+
+	fresh := []Event{}
+	newest := s.last
+
+	for _, e := range events {
+		switch {
+		case e.Time.Before(s.last):
+			continue
+		case e.Time.Equal(s.last):
+			if s.seen[e.Raw] > 0 {
+				s.seen[e.Raw]--
+				continue
+			}
+		}
+
+		fresh = append(fresh, e)
+
+		if e.Time.After(newest) {
+			newest = e.Time
+		}
+	}
+
+	// Lines sharing the newest timestamp may be read again on the next poll.
+	s.last = newest
+
+	s.seen = map[string]int{}
+	for _, e := range events {
+		if e.Time.Equal(s.last) {
+			s.seen[e.Raw]++
+		}
+	}
+
+	s.mu.Lock()
+	subs := []*subscription{}
+	for sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	s.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.send(fresh)
+	}
+
+	return fresh, nil
+}
+
+// send will deliver Events in order, giving up once the subscription is cancelled.
+func (sub *subscription) send(events []Event) {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	for _, e := range events {
+		select {
+		case <-sub.done:
+			return
+		default:
+		}
+
+		select {
+		case sub.ch <- e:
+		case <-sub.done:
+			return
+		}
+	}
+}
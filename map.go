@@ -51,7 +51,7 @@ const (
 func (c *Conn) Map() (Map, error) {
 	result, err := c.send("get", "map")
 	if err != nil {
-		return Map{}, fmt.Errorf("failed to get current map: %v", err)
+		return Map{}, fmt.Errorf("failed to get current map: %w", err)
 	}
 
 	return mapFromString(result), nil
@@ -61,7 +61,7 @@ func (c *Conn) Map() (Map, error) {
 func (c *Conn) Maps() ([]Map, error) {
 	result, err := c.send("get", "mapsforrotation")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get maps for rotation: %v", err)
+		return nil, fmt.Errorf("failed to get maps for rotation: %w", err)
 	}
 
 	maps := []Map{}
@@ -82,7 +82,7 @@ func (c *Conn) Maps() ([]Map, error) {
 func (c *Conn) Rotation() ([]Map, error) {
 	result, err := c.send("rotlist")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get map rotation: %v", err)
+		return nil, fmt.Errorf("failed to get map rotation: %w", err)
 	}
 
 	maps := []Map{}
@@ -102,7 +102,7 @@ func (c *Conn) Rotation() ([]Map, error) {
 func (c *Conn) RotationAdd(n MapName) error {
 	_, err := c.send("rotadd", n.String())
 	if err != nil {
-		return fmt.Errorf("failed to add map to rotation: %v", err)
+		return fmt.Errorf("failed to add map to rotation: %w", err)
 	}
 
 	return nil
@@ -112,7 +112,7 @@ func (c *Conn) RotationAdd(n MapName) error {
 func (c *Conn) RotationRemove(n MapName) error {
 	_, err := c.send("rotdel", n.String())
 	if err != nil {
-		return fmt.Errorf("failed to add map to rotation: %v", err)
+		return fmt.Errorf("failed to add map to rotation: %w", err)
 	}
 
 	return nil
@@ -122,7 +122,7 @@ func (c *Conn) RotationRemove(n MapName) error {
 func (c *Conn) SetMap(n MapName) error {
 	_, err := c.send("map", n.String())
 	if err != nil {
-		return fmt.Errorf("failed to set map as %s: %v", n, err)
+		return fmt.Errorf("failed to set map as %s: %w", n, err)
 	}
 
 	return nil
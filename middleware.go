@@ -0,0 +1,189 @@
+package rcon
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Request represents a command passing through the middlewares of a Conn.
+type Request struct {
+	Cmds     []string // The command tokens, e.g. "kick" followed by its quoted arguments.
+	Operator string   // The operator sending the command through a handle returned by As, if any.
+}
+
+// Handler represents a step in sending a command, receiving the Request and returning the response
+// of the server.
+type Handler func(r Request) (string, error)
+
+// Middleware wraps a Handler, e.g. to log, measure, limit or reject commands.
+type Middleware func(next Handler) Handler
+
+// Use will wrap every command sent by the Conn with middlewares. Middlewares registered first are
+// outermost, so they see the command before and the response after the others.
+func (c *Conn) Use(middlewares ...Middleware) {
+	c = c.root()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.middlewares = append(c.middlewares, middlewares...)
+
+	h := Handler(c.sendSession)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+
+	c.handler = h
+}
+
+// As returns a handle to the Conn sending commands on behalf of an operator, who is passed to every
+// Middleware in the Request, e.g. to be audited. The handle shares the sessions and middlewares of
+// the Conn.
+func (c *Conn) As(operator string) *Conn {
+	return &Conn{parent: c.root(), operator: operator}
+}
+
+// root returns the Conn holding the sessions and middlewares of a handle.
+func (c *Conn) root() *Conn {
+	if c.parent != nil {
+		return c.parent
+	}
+
+	return c
+}
+
+// Latency returns a Middleware calling fn with the duration and error of every command. Unlike
+// Observe, it measures the middlewares registered after it, e.g. the wait for a rate limiter.
+func Latency(fn func(CommandResult)) Middleware {
+	return func(next Handler) Handler {
+		return func(r Request) (string, error) {
+			start := time.Now()
+
+			result, err := next(r)
+
+			fn(CommandResult{
+				Name:     CommandName(r.Cmds),
+				Duration: time.Since(start),
+				Err:      err,
+			})
+
+			return result, err
+		}
+	}
+}
+
+// Logging returns a Middleware writing a line of key=value pairs to l for every command, e.g.
+//
+//	command="get playerids" args=0 duration=2.1ms bytes=412
+//	command=kick args=2 duration=3.4ms error="got FAIL response from server"
+//
+// Arguments are counted rather than written, as they may hold player messages.
+func Logging(l *log.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(r Request) (string, error) {
+			start := time.Now()
+
+			result, err := next(r)
+
+			name := CommandName(r.Cmds)
+			tokens := len(r.Cmds)
+			if tokens == 1 {
+				tokens = len(strings.Fields(r.Cmds[0]))
+			}
+
+			args := tokens - len(strings.Fields(name))
+
+			line := fmt.Sprintf("command=%s args=%d duration=%s", logValue(name), args, time.Since(start).Round(100*time.Microsecond))
+
+			if err != nil {
+				line += " error=" + logValue(err.Error())
+			} else {
+				line += fmt.Sprintf(" bytes=%d", len(result))
+			}
+
+			l.Print(line)
+
+			return result, err
+		}
+	}
+}
+
+// CommandName returns a command without its arguments, keeping the variable of "get" commands,
+// e.g. "get playerids".
+func CommandName(cmds []string) string {
+	args := strings.Fields(strings.Join(cmds, " "))
+
+	switch {
+	case len(args) == 0:
+		return ""
+	case len(args) > 1 && strings.EqualFold(args[0], "get"):
+		return strings.ToLower(args[0] + " " + args[1])
+	default:
+		return strings.ToLower(args[0])
+	}
+}
+
+// mutating holds the commands that change the server or act on players.
+var mutating = map[string]bool{
+	"kick": true, "punish": true, "message": true, "switchteamnow": true, "switchteamondeath": true,
+	"tempban": true, "permaban": true, "pardontempban": true, "pardonpermaban": true,
+	"adminadd": true, "admindel": true, "vipadd": true, "vipdel": true,
+	"map": true, "rotadd": true, "rotdel": true, "broadcast": true,
+	"banprofanity": true, "unbanprofanity": true, "resetvotekickthreshold": true,
+	"setkickidletime": true, "sethighping": true, "setautobalanceenabled": true, "setautobalancethreshold": true,
+	"setteamswitchcooldown": true, "setmaxqueuedplayers": true, "setnumvipslots": true,
+	"setvotekickenabled": true, "setvotekickthreshold": true,
+}
+
+// Mutating reports whether a command changes the server or acts on a player, e.g. "kick".
+func Mutating(cmds []string) bool {
+	return mutating[CommandName(cmds)]
+}
+
+// Arguments returns the arguments of a command without its name, with quoted arguments unquoted,
+// e.g. the name and reason of a kick.
+func Arguments(cmds []string) []string {
+	args := []string{}
+
+	b := &strings.Builder{}
+	quoted, started := false, false
+
+	for _, r := range strings.Join(cmds, " ") {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case r == ' ' && !quoted:
+			if started {
+				args = append(args, b.String())
+				b.Reset()
+			}
+
+			started = false
+		default:
+			b.WriteRune(r)
+			started = true
+		}
+	}
+
+	if started {
+		args = append(args, b.String())
+	}
+
+	if len(args) == 0 {
+		return args
+	}
+
+	return args[1:]
+}
+
+// logValue will quote a value containing spaces or quotes.
+func logValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return s
+}
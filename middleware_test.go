@@ -0,0 +1,166 @@
+package rcon_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+)
+
+// trace represents the calls of the middlewares of a Conn.
+type trace struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+// middleware returns a Middleware recording its name and the operator of each Request on the way
+// in, and its name on the way out.
+func (t *trace) middleware(name string) rcon.Middleware {
+	return func(next rcon.Handler) rcon.Handler {
+		return func(r rcon.Request) (string, error) {
+			t.add(name + ">" + r.Operator)
+
+			result, err := next(r)
+
+			t.add("<" + name)
+
+			return result, err
+		}
+	}
+}
+
+func (t *trace) add(step string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.steps = append(t.steps, step)
+}
+
+func (t *trace) take() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := strings.Join(t.steps, " ")
+	t.steps = nil
+
+	return s
+}
+
+func TestUse(t *testing.T) {
+	srv := rcontest.NewServer(t)
+	c := srv.Conn(t)
+
+	tr := &trace{}
+	c.Use(tr.middleware("a"), tr.middleware("b"))
+
+	// Middlewares registered through a handle wrap the commands of the Conn too.
+	c.As("alice").Use(tr.middleware("c"))
+
+	tests := []struct {
+		name string
+		conn *rcon.Conn
+		want string
+	}{
+		{name: "conn", conn: c, want: "a> b> c> <c <b <a"},
+		{name: "handle", conn: c.As("alice"), want: "a>alice b>alice c>alice <c <b <a"},
+		{name: "handle of handle", conn: c.As("alice").As("bob"), want: "a>bob b>bob c>bob <c <b <a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.Reset()
+
+			err := tt.conn.SetBroadcast("hello")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := tr.take(); got != tt.want {
+				t.Errorf("middlewares called as %q, want %q", got, tt.want)
+			}
+
+			if sent := srv.Sent(); len(sent) != 1 {
+				t.Errorf("sent %q, want a single command", sent)
+			}
+		})
+	}
+}
+
+func TestUseRejected(t *testing.T) {
+	srv := rcontest.NewServer(t)
+	c := srv.Conn(t)
+
+	errRejected := errors.New("rejected")
+
+	tr := &trace{}
+	observed := []string{}
+
+	c.Observe(func(r rcon.CommandResult) { observed = append(observed, r.Name) })
+	c.Use(tr.middleware("outer"), func(next rcon.Handler) rcon.Handler {
+		return func(r rcon.Request) (string, error) {
+			if rcon.Mutating(r.Cmds) {
+				return "", errRejected
+			}
+
+			return next(r)
+		}
+	}, tr.middleware("inner"))
+
+	srv.Reset()
+
+	err := c.As("alice").Kick(rcon.Player{Name: "Bob"}, "teamkilling")
+	if !errors.Is(err, errRejected) {
+		t.Errorf("Kick() = %v, want the middleware's error", err)
+	}
+
+	if got := tr.take(); got != "outer>alice <outer" {
+		t.Errorf("middlewares called as %q, want the inner one skipped", got)
+	}
+
+	if sent := srv.Sent(); len(sent) > 0 || len(observed) > 0 {
+		t.Errorf("sent %q and observed %q, want nothing", sent, observed)
+	}
+
+	srv.Reply("get name", "Server")
+
+	_, err = c.Name()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := tr.take(); got != "outer> inner> <inner <outer" {
+		t.Errorf("middlewares called as %q, want both", got)
+	}
+
+	if strings.Join(observed, " ") != "get name" {
+		t.Errorf("observed %q, want the command sent", observed)
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := []struct {
+		cmds         []string
+		want         string
+		wantMutating bool
+	}{
+		{cmds: []string{"get", "playerids"}, want: "get playerids"},
+		{cmds: []string{"GET PlayerIDs"}, want: "get playerids"},
+		{cmds: []string{"kick", `"Bob"`, `"teamkilling"`}, want: "kick", wantMutating: true},
+		{cmds: []string{`Broadcast "hello world"`}, want: "broadcast", wantMutating: true},
+		{cmds: []string{"showlog", "5"}, want: "showlog"},
+		{cmds: []string{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := rcon.CommandName(tt.cmds); got != tt.want {
+			t.Errorf("CommandName(%q) = %q, want %q", tt.cmds, got, tt.want)
+		}
+
+		if got := rcon.Mutating(tt.cmds); got != tt.wantMutating {
+			t.Errorf("Mutating(%q) = %v, want %v", tt.cmds, got, tt.wantMutating)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package rcon
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// CommandResult represents the outcome of a single command sent by a Conn.
+type CommandResult struct {
+	Name     string // The command without its arguments, e.g. "kick" or "get playerids".
+	Duration time.Duration
+	Err      error
+}
+
+// Observe will register fn to be called after every command sent by the Conn, e.g. to collect
+// latency and error metrics. fn is called synchronously, so it must not block. Commands answered
+// or rejected by a Middleware never reach the server, and are not observed.
+func (c *Conn) Observe(fn func(CommandResult)) {
+	c = c.root()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.observers = append(c.observers, fn)
+}
+
+// Sessions returns the estimated number of sessions opened by the Conn's pool.
+func (c *Conn) Sessions() int {
+	return int(atomic.LoadInt64(&c.root().active))
+}
+
+func (c *Conn) observe(r CommandResult) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, fn := range c.observers {
+		fn(r)
+	}
+}
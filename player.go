@@ -9,8 +9,8 @@ import (
 )
 
 type Player struct {
-	Name string
-	ID64 string
+	Name string `yaml:"name" json:"name"`
+	ID64 string `yaml:"id64" json:"id64"`
 }
 
 type Ban struct {
@@ -24,7 +24,7 @@ type Ban struct {
 func (c *Conn) BannedTemporarily() ([]Ban, error) {
 	result, err := c.send("get", "tempbans")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get permanent bans: %v", err)
+		return nil, fmt.Errorf("failed to get permanent bans: %w", err)
 	}
 
 	args := strings.Split(result, "\t")
@@ -32,13 +32,18 @@ func (c *Conn) BannedTemporarily() ([]Ban, error) {
 		return nil, fmt.Errorf("failed to parse temporary ban information")
 	}
 
+	bans := []Ban{}
+
+	// Admins are only needed to resolve the admin of each ban.
+	if len(args) < 3 {
+		return bans, nil
+	}
+
 	admins, err := c.Admins()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse temporary ban admins: %v", err)
+		return nil, fmt.Errorf("failed to parse temporary ban admins: %w", err)
 	}
 
-	bans := []Ban{}
-
 	for _, ban := range args[1 : len(args)-1] {
 		b, err := parseBanned(ban, admins)
 		if err != nil {
@@ -58,7 +63,7 @@ func (b Ban) String() string {
 func (c *Conn) BannedPermanently() ([]Ban, error) {
 	result, err := c.send("get", "permabans")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get permanent bans: %v", err)
+		return nil, fmt.Errorf("failed to get permanent bans: %w", err)
 	}
 
 	args := strings.Split(result, "\t")
@@ -66,13 +71,18 @@ func (c *Conn) BannedPermanently() ([]Ban, error) {
 		return nil, fmt.Errorf("failed to parse temporary ban information")
 	}
 
+	bans := []Ban{}
+
+	// Admins are only needed to resolve the admin of each ban.
+	if len(args) < 3 {
+		return bans, nil
+	}
+
 	admins, err := c.Admins()
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse temporary ban admins: %v", err)
+		return nil, fmt.Errorf("failed to parse temporary ban admins: %w", err)
 	}
 
-	bans := []Ban{}
-
 	for _, ban := range args[1 : len(args)-1] {
 		b, err := parseBanned(ban, admins)
 		if err != nil {
@@ -89,7 +99,7 @@ func (c *Conn) BannedPermanently() ([]Ban, error) {
 func (c *Conn) BanPermanently(p Player, reason, admin string) error {
 	_, err := c.send("permaban", q(p.ID64), q(reason), q(admin))
 	if err != nil {
-		return fmt.Errorf("failed to set permanent ban %s: %v", p, err)
+		return fmt.Errorf("failed to set permanent ban %s: %w", p, err)
 	}
 
 	return nil
@@ -100,12 +110,12 @@ func (c *Conn) BanRemove(p Player) error {
 	_, err := c.send("pardontempban", q(p.ID64))
 	if err != nil {
 		if err != ErrResultFailed {
-			return fmt.Errorf("failed to remove ban for %s: %v", p, err)
+			return fmt.Errorf("failed to remove ban for %s: %w", p, err)
 		}
 
 		_, err := c.send("pardonpermaban", q(p.ID64))
 		if err != nil {
-			return fmt.Errorf("failed to remove ban for %s: %v", p, err)
+			return fmt.Errorf("failed to remove ban for %s: %w", p, err)
 		}
 	}
 
@@ -116,7 +126,7 @@ func (c *Conn) BanRemove(p Player) error {
 func (c *Conn) BanTemporarily(p Player, hours int, reason, admin string) error {
 	_, err := c.send("tempban", q(p.ID64), strconv.Itoa(hours), q(reason), q(admin))
 	if err != nil {
-		return fmt.Errorf("failed to set temporary ban %s: %v", p, err)
+		return fmt.Errorf("failed to set temporary ban %s: %w", p, err)
 	}
 
 	return nil
@@ -126,7 +136,7 @@ func (c *Conn) BanTemporarily(p Player, hours int, reason, admin string) error {
 func (c *Conn) Kick(p Player, reason string) error {
 	_, err := c.send("kick", q(p.Name), q(reason))
 	if err != nil {
-		return fmt.Errorf("failed to kick %s: %v", p, err)
+		return fmt.Errorf("failed to kick %s: %w", p, err)
 	}
 
 	return nil
@@ -136,7 +146,17 @@ func (c *Conn) Kick(p Player, reason string) error {
 func (c *Conn) Punish(p Player, reason string) error {
 	_, err := c.send("punish", q(p.Name), q(reason))
 	if err != nil {
-		return fmt.Errorf("failed to punish %s: %v", p, err)
+		return fmt.Errorf("failed to punish %s: %w", p, err)
+	}
+
+	return nil
+}
+
+// Message will send a private message to an active player.
+func (c *Conn) Message(p Player, message string) error {
+	_, err := c.send("message", q(p.ID64), q(message))
+	if err != nil {
+		return fmt.Errorf("failed to message %s: %w", p, err)
 	}
 
 	return nil
@@ -146,7 +166,7 @@ func (c *Conn) Punish(p Player, reason string) error {
 func (c *Conn) Player(username string) (Player, error) {
 	result, err := c.send("playerinfo", username)
 	if err != nil {
-		return Player{}, fmt.Errorf("failed to get player information for %s: %v", username, err)
+		return Player{}, fmt.Errorf("failed to get player information for %s: %w", username, err)
 	}
 
 	args := strings.Split(result, "\n")
@@ -171,7 +191,7 @@ func (c *Conn) Player(username string) (Player, error) {
 func (c *Conn) Players() ([]Player, error) {
 	result, err := c.send("get", "playerids")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get information for all players: %v", err)
+		return nil, fmt.Errorf("failed to get information for all players: %w", err)
 	}
 
 	args := strings.Split(result, "\t")
@@ -196,7 +216,7 @@ func (c *Conn) Players() ([]Player, error) {
 func (c *Conn) SetSwitchTeamNow(p Player) error {
 	_, err := c.send("switchteamnow", p.ID64)
 	if err != nil {
-		return fmt.Errorf("failed to set switch player now for %s: %v", p.String(), err)
+		return fmt.Errorf("failed to set switch player now for %s: %w", p.String(), err)
 	}
 
 	return nil
@@ -205,7 +225,7 @@ func (c *Conn) SetSwitchTeamNow(p Player) error {
 func (c *Conn) SetSwitchTeamOnDeath(p Player) error {
 	_, err := c.send("switchteamondeath", p.ID64)
 	if err != nil {
-		return fmt.Errorf("failed to set switch player on death for %s: %v", p.String(), err)
+		return fmt.Errorf("failed to set switch player on death for %s: %w", p.String(), err)
 	}
 
 	return nil
@@ -216,7 +236,7 @@ func (p Player) String() string {
 }
 
 var matchBannedName = regexp.MustCompile(`: nickname "(.*?)" banned"`)
-var matchBanned = regexp.MustCompile(`(.*?) : nickname "(.*?)" banned for (.*?) hours on (.*?) for "(.*?)" by admin "(.*?)"`)
+var matchBanned = regexp.MustCompile(`(.*?) : nickname "(.*?)" banned(?: for (.*?) hours)? on (.*?) for "(.*?)" by admin "(.*?)"`)
 
 // parseBanned will return a slice of items that are in quotes from a string.
 func parseBanned(s string, admins []Admin) (Ban, error) {
@@ -232,16 +252,21 @@ func parseBanned(s string, admins []Admin) (Ban, error) {
 	b.ID64 = match[1]
 	b.Name = match[2]
 
-	hours, err := strconv.Atoi(match[3])
-	if err != nil {
-		return Ban{}, fmt.Errorf("failed to parse temporary ban hours: %v", err)
+	// Permanent bans have no duration.
+	if match[3] != "" {
+		hours, err := strconv.Atoi(match[3])
+		if err != nil {
+			return Ban{}, fmt.Errorf("failed to parse temporary ban hours: %w", err)
+		}
+
+		b.Duration = time.Duration(hours) * time.Hour
 	}
 
-	b.Duration = time.Duration(hours) * time.Hour
+	var err error
 
 	b.Time, err = time.Parse("2006.01.02-15.04.05", match[4])
 	if err != nil {
-		return Ban{}, fmt.Errorf("failed to parse temporary ban time: %v", err)
+		return Ban{}, fmt.Errorf("failed to parse temporary ban time: %w", err)
 	}
 
 	b.Reason = match[5]
@@ -0,0 +1,199 @@
+package playerdb
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/consume"
+)
+
+// RecordRoster will apply a RosterEvent, e.g. as the OnEvent of a RosterWatcher. Sessions and
+// playtime are counted when a player leaves.
+func (db *DB) RecordRoster(e rcon.RosterEvent) {
+	if e.Player.ID64 == "" {
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	r := db.seen(e.Player, e.Time)
+
+	switch e.Kind {
+	case rcon.PlayerJoined:
+		db.online[r.ID64] = e.Start
+	case rcon.PlayerLeft:
+		delete(db.online, r.ID64)
+
+		r.Sessions++
+		r.Playtime += e.Duration()
+	}
+}
+
+// Consume will record the Events of a channel, e.g. a LogStream subscription, until it is closed
+// or the context is cancelled.
+func (db *DB) Consume(ctx context.Context, events <-chan rcon.Event) error {
+	return consume.Events(ctx, events, db.Record)
+}
+
+// Record will apply a log Event, recording the names of the players involved and counting kills,
+// deaths and team kills.
+func (db *DB) Record(e rcon.Event) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if e.Player.ID64 != "" {
+		r := db.seen(e.Player, e.Time)
+
+		switch e.Kind {
+		case rcon.EventKill:
+			r.Kills++
+		case rcon.EventTeamKill:
+			r.TeamKills++
+		}
+	}
+
+	if e.Victim.ID64 != "" {
+		r := db.seen(e.Victim, e.Time)
+
+		switch e.Kind {
+		case rcon.EventKill, rcon.EventTeamKill:
+			r.Deaths++
+		}
+	}
+}
+
+// Wrap is the rcon.Middleware recording the kicks, punishes and bans sent by a Conn, e.g.
+// conn.Use(db.Wrap). Failed commands are not recorded.
+func (db *DB) Wrap(next rcon.Handler) rcon.Handler {
+	return func(r rcon.Request) (string, error) {
+		result, err := next(r)
+		if err == nil {
+			db.action(rcon.CommandName(r.Cmds), rcon.Arguments(r.Cmds), r.Operator)
+		}
+
+		return result, err
+	}
+}
+
+// action will record a command acting on a player, sent by operator if known. Kicks and punishes
+// name the player, and bans their ID64 and admin.
+func (db *DB) action(name string, args []string, operator string) {
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+
+		return ""
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	a := Action{Time: db.now().UTC(), Admin: operator}
+	target := ""
+
+	switch name {
+	case "kick", "punish":
+		target = db.resolve(arg(0))
+		a.Kind, a.Reason = ActionKick, arg(1)
+
+		if name == "punish" {
+			a.Kind = ActionPunish
+		}
+	case "tempban":
+		target = arg(0)
+		a.Kind, a.Reason = ActionBanTemporary, arg(2)
+		a.Hours, _ = strconv.Atoi(arg(1))
+
+		if arg(3) != "" {
+			a.Admin = arg(3)
+		}
+	case "permaban":
+		target = arg(0)
+		a.Kind, a.Reason = ActionBanPermanent, arg(1)
+
+		if arg(2) != "" {
+			a.Admin = arg(2)
+		}
+	default:
+		return
+	}
+
+	if target == "" {
+		return
+	}
+
+	r := db.record(target)
+	r.Actions = append(r.Actions, a)
+}
+
+// resolve returns the ID64 of the player most recently seen with a name, preferring players
+// online.
+func (db *DB) resolve(name string) string {
+	id64 := ""
+	online := false
+	last := time.Time{}
+
+	for _, r := range db.Records {
+		for _, a := range r.Names {
+			if a.Name != name {
+				continue
+			}
+
+			_, on := db.online[r.ID64]
+
+			if id64 == "" || on && !online || on == online && a.LastSeen.After(last) {
+				id64, online, last = r.ID64, on, a.LastSeen
+			}
+		}
+	}
+
+	return id64
+}
+
+// seen will record a player seen at a time under their current name, returning their record.
+func (db *DB) seen(p rcon.Player, t time.Time) *Record {
+	if t.IsZero() {
+		t = db.now()
+	}
+
+	t = t.UTC()
+
+	r := db.record(p.ID64)
+
+	if r.FirstSeen.IsZero() || t.Before(r.FirstSeen) {
+		r.FirstSeen = t
+	}
+
+	if t.After(r.LastSeen) {
+		r.LastSeen = t
+	}
+
+	if p.Name == "" {
+		return r
+	}
+
+	for i := range r.Names {
+		a := &r.Names[i]
+		if a.Name != p.Name {
+			continue
+		}
+
+		if t.Before(a.FirstSeen) {
+			a.FirstSeen = t
+		}
+
+		if t.After(a.LastSeen) {
+			a.LastSeen = t
+		}
+
+		return r
+	}
+
+	r.Names = append(r.Names, Alias{Name: p.Name, FirstSeen: t, LastSeen: t})
+
+	return r
+}
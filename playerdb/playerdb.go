@@ -0,0 +1,268 @@
+// Package playerdb keeps a persistent history of every player seen on a server.
+//
+// A DB is fed by a RosterWatcher for sessions and names, by a LogStream subscription for kills and
+// deaths, and by Conn middleware for kicks, bans and punishes. Records are keyed by ID64 and keep
+// every name a player has used, so the aliases of previously banned players can be recognized.
+package playerdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/jsonfile"
+	"github.com/verocity-gaming/rcon/internal/poll"
+)
+
+// Record represents the history of a single player.
+type Record struct {
+	ID64      string        `json:"id64"`
+	Names     []Alias       `json:"names"`
+	FirstSeen time.Time     `json:"first_seen"`
+	LastSeen  time.Time     `json:"last_seen"`
+	Playtime  time.Duration `json:"playtime"`
+	Sessions  int           `json:"sessions"`
+
+	Kills     int `json:"kills"`
+	Deaths    int `json:"deaths"`
+	TeamKills int `json:"teamkills"`
+
+	Actions []Action `json:"actions,omitempty"`
+	Notes   []Note   `json:"notes,omitempty"`
+}
+
+// Alias represents a name used by a player.
+type Alias struct {
+	Name      string    `json:"name"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// ActionKind represents the kind of an Action.
+type ActionKind string
+
+// Action kinds.
+const (
+	ActionKick         ActionKind = "kick"
+	ActionPunish       ActionKind = "punish"
+	ActionBanTemporary ActionKind = "ban_temporary"
+	ActionBanPermanent ActionKind = "ban_permanent"
+)
+
+// Action represents a kick, punish or ban of a player.
+type Action struct {
+	Kind   ActionKind `json:"kind"`
+	Time   time.Time  `json:"time"`
+	Reason string     `json:"reason,omitempty"`
+	Admin  string     `json:"admin,omitempty"`
+	Hours  int        `json:"hours,omitempty"` // Length of ActionBanTemporary.
+}
+
+// Note represents a comment left by an admin about a player.
+type Note struct {
+	Time   time.Time `json:"time"`
+	Author string    `json:"author"`
+	Text   string    `json:"text"`
+}
+
+// DB represents the history of every player keyed by ID64, persisted as JSON.
+type DB struct {
+	mu      sync.Mutex
+	path    string
+	dirty   bool
+	online  map[string]time.Time // Start of the current session of players online.
+	now     func() time.Time
+	Records map[string]*Record `json:"records"`
+
+	// OnError is called with errors from saves in Run.
+	OnError func(error) `json:"-"`
+}
+
+// Open will load a DB from path, creating an empty one if the file does not exist. An empty path
+// keeps the DB in memory.
+func Open(path string) (*DB, error) {
+	db := &DB{
+		path:    path,
+		online:  map[string]time.Time{},
+		now:     time.Now,
+		Records: map[string]*Record{},
+	}
+
+	err := jsonfile.Load(path, db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load player database: %v", err)
+	}
+
+	if db.Records == nil {
+		db.Records = map[string]*Record{}
+	}
+
+	return db, nil
+}
+
+// Save will write the DB to disk, replacing the previous file atomically.
+func (db *DB) Save() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return db.save()
+}
+
+func (db *DB) save() error {
+	if db.path == "" {
+		return nil
+	}
+
+	err := jsonfile.Save(db.path, db)
+	if err != nil {
+		return fmt.Errorf("failed to save player database: %v", err)
+	}
+
+	db.dirty = false
+
+	return nil
+}
+
+// Run will save the DB every interval if it changed, and once more when ctx is done, as kills and
+// deaths change it too often to save on every Event.
+func (db *DB) Run(ctx context.Context, interval time.Duration) error {
+	flush := func() {
+		err := db.flush()
+		if err != nil && db.OnError != nil {
+			db.OnError(err)
+		}
+	}
+
+	err := poll.Every(ctx, interval, flush)
+	flush()
+
+	return err
+}
+
+// flush will save the DB if it changed since the last save.
+func (db *DB) flush() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !db.dirty {
+		return nil
+	}
+
+	return db.save()
+}
+
+// Player returns a copy of the record for an ID64. Playtime includes the current session of
+// players online.
+func (db *DB) Player(id64 string) (Record, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	r, ok := db.Records[id64]
+	if !ok {
+		return Record{}, false
+	}
+
+	return db.copy(r), true
+}
+
+// Search returns a copy of the records of every player who has ever used a name containing name,
+// ignoring case, most recently seen first.
+func (db *DB) Search(name string) []Record {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	name = strings.ToLower(name)
+
+	list := []Record{}
+	for _, r := range db.Records {
+		for _, a := range r.Names {
+			if strings.Contains(strings.ToLower(a.Name), name) {
+				list = append(list, db.copy(r))
+				break
+			}
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+
+	return list
+}
+
+// AddNote will add a note about a player, saving the DB immediately.
+func (db *DB) AddNote(id64, author, text string) error {
+	if id64 == "" {
+		return fmt.Errorf("failed to add note: missing ID64")
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	r := db.record(id64)
+	r.Notes = append(r.Notes, Note{Time: db.now().UTC(), Author: author, Text: text})
+
+	return db.save()
+}
+
+// record returns the record for an ID64, creating it if necessary.
+func (db *DB) record(id64 string) *Record {
+	db.dirty = true
+
+	r, ok := db.Records[id64]
+	if !ok {
+		r = &Record{ID64: id64, Names: []Alias{}}
+		db.Records[id64] = r
+	}
+
+	return r
+}
+
+// copy returns a deep copy of a record, adding the current session to its playtime.
+func (db *DB) copy(r *Record) Record {
+	c := *r
+	c.Names = append([]Alias{}, r.Names...)
+	c.Actions = append([]Action(nil), r.Actions...)
+	c.Notes = append([]Note(nil), r.Notes...)
+
+	if start, ok := db.online[r.ID64]; ok {
+		c.Playtime += db.now().Sub(start)
+	}
+
+	return c
+}
+
+// Name returns the name the player was most recently seen with.
+func (r Record) Name() string {
+	name := ""
+	last := time.Time{}
+
+	for _, a := range r.Names {
+		if name == "" || !a.LastSeen.Before(last) {
+			name, last = a.Name, a.LastSeen
+		}
+	}
+
+	return name
+}
+
+// Bans returns the bans issued against the player, temporary or permanent.
+func (r Record) Bans() []Action {
+	bans := []Action{}
+	for _, a := range r.Actions {
+		if a.Kind == ActionBanTemporary || a.Kind == ActionBanPermanent {
+			bans = append(bans, a)
+		}
+	}
+
+	return bans
+}
+
+// Player returns the player under their most recent name.
+func (r Record) Player() rcon.Player {
+	return rcon.Player{Name: r.Name(), ID64: r.ID64}
+}
@@ -0,0 +1,310 @@
+package playerdb
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+var (
+	now = time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	bob = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+	eve = rcon.Player{Name: "Eve", ID64: "76561198000000002"}
+)
+
+func newDB(t *testing.T) *DB {
+	db, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.now = func() time.Time { return now }
+
+	return db
+}
+
+// sighting represents a player seen in a log Event, relative to now.
+type sighting struct {
+	name string
+	at   time.Duration
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		sightings []sighting
+		want      []Alias
+		wantName  string
+	}{
+		{
+			name:      "single",
+			sightings: []sighting{{name: "Bob", at: 0}, {name: "Bob", at: time.Hour}},
+			want:      []Alias{{Name: "Bob", FirstSeen: now, LastSeen: now.Add(time.Hour)}},
+			wantName:  "Bob",
+		},
+		{
+			name:      "renamed",
+			sightings: []sighting{{name: "Bob", at: 0}, {name: "Bobby", at: time.Hour}},
+			want: []Alias{
+				{Name: "Bob", FirstSeen: now, LastSeen: now},
+				{Name: "Bobby", FirstSeen: now.Add(time.Hour), LastSeen: now.Add(time.Hour)},
+			},
+			wantName: "Bobby",
+		},
+		{
+			name:      "renamed back",
+			sightings: []sighting{{name: "Bob", at: 0}, {name: "Bobby", at: time.Hour}, {name: "Bob", at: 2 * time.Hour}},
+			want: []Alias{
+				{Name: "Bob", FirstSeen: now, LastSeen: now.Add(2 * time.Hour)},
+				{Name: "Bobby", FirstSeen: now.Add(time.Hour), LastSeen: now.Add(time.Hour)},
+			},
+			wantName: "Bob",
+		},
+		{
+			name:      "out of order",
+			sightings: []sighting{{name: "Bob", at: time.Hour}, {name: "Bob", at: 0}, {name: "Bob", at: 30 * time.Minute}},
+			want:      []Alias{{Name: "Bob", FirstSeen: now, LastSeen: now.Add(time.Hour)}},
+			wantName:  "Bob",
+		},
+		{
+			name:      "unnamed",
+			sightings: []sighting{{name: "Bob", at: 0}, {name: "", at: time.Hour}},
+			want:      []Alias{{Name: "Bob", FirstSeen: now, LastSeen: now}},
+			wantName:  "Bob",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+
+			first, last := time.Time{}, time.Time{}
+
+			for _, s := range tt.sightings {
+				at := now.Add(s.at)
+				db.Record(rcon.Event{Kind: rcon.EventChat, Time: at, Player: rcon.Player{Name: s.name, ID64: bob.ID64}})
+
+				if first.IsZero() || at.Before(first) {
+					first = at
+				}
+
+				if at.After(last) {
+					last = at
+				}
+			}
+
+			r, ok := db.Player(bob.ID64)
+			if !ok {
+				t.Fatal("player not recorded")
+			}
+
+			if fmt.Sprint(r.Names) != fmt.Sprint(tt.want) {
+				t.Errorf("Names = %v, want %v", r.Names, tt.want)
+			}
+
+			if r.Name() != tt.wantName {
+				t.Errorf("Name() = %q, want %q", r.Name(), tt.wantName)
+			}
+
+			if !r.FirstSeen.Equal(first) || !r.LastSeen.Equal(last) {
+				t.Errorf("seen from %v to %v, want %v to %v", r.FirstSeen, r.LastSeen, first, last)
+			}
+		})
+	}
+}
+
+// session represents a RosterEvent of bob, relative to now.
+type session struct {
+	kind  rcon.RosterEventKind
+	start time.Duration
+	end   time.Duration // Of PlayerLeft.
+}
+
+func TestPlaytime(t *testing.T) {
+	tests := []struct {
+		name         string
+		sessions     []session
+		wantSessions int
+		wantPlaytime time.Duration // At now.
+	}{
+		{
+			name:         "online",
+			sessions:     []session{{kind: rcon.PlayerJoined, start: -time.Hour}},
+			wantPlaytime: time.Hour,
+		},
+		{
+			name: "left",
+			sessions: []session{
+				{kind: rcon.PlayerJoined, start: -3 * time.Hour},
+				{kind: rcon.PlayerLeft, start: -3 * time.Hour, end: -2 * time.Hour},
+			},
+			wantSessions: 1,
+			wantPlaytime: time.Hour,
+		},
+		{
+			name: "sessions",
+			sessions: []session{
+				{kind: rcon.PlayerJoined, start: -5 * time.Hour},
+				{kind: rcon.PlayerLeft, start: -5 * time.Hour, end: -4 * time.Hour},
+				{kind: rcon.PlayerJoined, start: -3 * time.Hour},
+				{kind: rcon.PlayerLeft, start: -3 * time.Hour, end: -time.Hour},
+				{kind: rcon.PlayerJoined, start: -30 * time.Minute},
+			},
+			wantSessions: 2,
+			wantPlaytime: 3*time.Hour + 30*time.Minute,
+		},
+		{
+			name: "renamed",
+			sessions: []session{
+				{kind: rcon.PlayerJoined, start: -time.Hour},
+				{kind: rcon.PlayerRenamed, start: -time.Hour},
+			},
+			wantPlaytime: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+
+			for _, s := range tt.sessions {
+				e := rcon.RosterEvent{Kind: s.kind, Player: bob, Time: now.Add(s.start), Start: now.Add(s.start)}
+				if s.kind == rcon.PlayerLeft {
+					e.Time, e.End = now.Add(s.end), now.Add(s.end)
+				}
+
+				db.RecordRoster(e)
+			}
+
+			r, _ := db.Player(bob.ID64)
+			if r.Sessions != tt.wantSessions || r.Playtime != tt.wantPlaytime {
+				t.Errorf("%d sessions for %v, want %d for %v", r.Sessions, r.Playtime, tt.wantSessions, tt.wantPlaytime)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmds     []string
+		operator string
+		err      error
+		want     []Action // Of bob.
+	}{
+		{
+			name:     "kick",
+			cmds:     []string{"kick", `"Bob"`, `"teamkilling"`},
+			operator: "alice",
+			want:     []Action{{Kind: ActionKick, Time: now, Reason: "teamkilling", Admin: "alice"}},
+		},
+		{
+			name: "punish",
+			cmds: []string{"punish", `"Bob"`, `"teamkilling"`},
+			want: []Action{{Kind: ActionPunish, Time: now, Reason: "teamkilling"}},
+		},
+		{
+			name:     "temporary ban",
+			cmds:     []string{"tempban", `"` + bob.ID64 + `"`, "2", `"teamkilling"`, `"carol"`},
+			operator: "alice",
+			want:     []Action{{Kind: ActionBanTemporary, Time: now, Reason: "teamkilling", Admin: "carol", Hours: 2}},
+		},
+		{
+			name:     "permanent ban",
+			cmds:     []string{"permaban", `"` + bob.ID64 + `"`, `"cheating"`},
+			operator: "alice",
+			want:     []Action{{Kind: ActionBanPermanent, Time: now, Reason: "cheating", Admin: "alice"}},
+		},
+		{
+			name: "failed",
+			cmds: []string{"kick", `"Bob"`, `"teamkilling"`},
+			err:  errors.New("timeout"),
+			want: []Action{},
+		},
+		{
+			name: "unknown name",
+			cmds: []string{"kick", `"Mallory"`, `"teamkilling"`},
+			want: []Action{},
+		},
+		{
+			name: "read",
+			cmds: []string{"get", "playerids"},
+			want: []Action{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+			db.RecordRoster(rcon.RosterEvent{Kind: rcon.PlayerJoined, Player: bob, Time: now, Start: now})
+
+			h := db.Wrap(func(rcon.Request) (string, error) { return "SUCCESS", tt.err })
+			h(rcon.Request{Cmds: tt.cmds, Operator: tt.operator})
+
+			r, _ := db.Player(bob.ID64)
+			if fmt.Sprint(r.Actions) != fmt.Sprint(tt.want) {
+				t.Errorf("Actions = %+v, want %+v", r.Actions, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	db := newDB(t)
+
+	// Eve once used the name Bob, but Bob is online under it.
+	impostor := rcon.Player{Name: "Bob", ID64: eve.ID64}
+	db.Record(rcon.Event{Kind: rcon.EventChat, Time: now.Add(-time.Minute), Player: impostor})
+	db.RecordRoster(rcon.RosterEvent{Kind: rcon.PlayerJoined, Player: bob, Time: now.Add(-time.Hour), Start: now.Add(-time.Hour)})
+
+	if got := db.resolve("Bob"); got != bob.ID64 {
+		t.Errorf("resolve() = %s, want the player online", got)
+	}
+
+	db.RecordRoster(rcon.RosterEvent{Kind: rcon.PlayerLeft, Player: bob, Time: now, Start: now.Add(-time.Hour), End: now})
+
+	if got := db.resolve("Bob"); got != bob.ID64 {
+		t.Errorf("resolve() = %s, want the player seen last", got)
+	}
+
+	db.Record(rcon.Event{Kind: rcon.EventChat, Time: now.Add(time.Minute), Player: impostor})
+
+	if got := db.resolve("Bob"); got != eve.ID64 {
+		t.Errorf("resolve() = %s, want the player seen last", got)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "players.json")
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Record(rcon.Event{Kind: rcon.EventKill, Time: now, Player: bob, Victim: eve})
+
+	err = db.AddNote(bob.ID64, "alice", "suspected of cheating")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, ok := db.Player(bob.ID64)
+	if !ok || r.Kills != 1 || len(r.Notes) != 1 || r.Name() != "Bob" {
+		t.Errorf("reopened record = %+v, want a kill and a note", r)
+	}
+
+	if r, _ := db.Player(eve.ID64); r.Deaths != 1 {
+		t.Errorf("reopened victim has %d deaths, want 1", r.Deaths)
+	}
+}
@@ -1,14 +1,17 @@
 package rcon
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"unicode"
 )
 
+// Profanities returns the censored words.
 func (c *Conn) Profanities() ([]string, error) {
 	result, err := c.send("get", "profanity")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get profanities: %v", err)
+		return nil, fmt.Errorf("failed to get profanities: %w", err)
 	}
 
 	args := strings.Split(result, "\t")
@@ -25,20 +28,172 @@ func (c *Conn) Profanities() ([]string, error) {
 	return words, nil
 }
 
+// ErrInvalidProfanity is returned for words the server cannot censor.
+var ErrInvalidProfanity = errors.New("invalid profanity")
+
+// maxProfanityBatch is the longest list of words sent in a single command, keeping commands well
+// within the length the server accepts.
+const maxProfanityBatch = 1000
+
+// ProfanityChanges represents the words changed by SyncProfanities.
+type ProfanityChanges struct {
+	Added   []string
+	Removed []string
+}
+
+// SetProfanities will censor words, in as many commands as their length requires. Every word is
+// validated before any command is sent.
 func (c *Conn) SetProfanities(words ...string) error {
-	_, err := c.send("BanProfanity", strings.Join(words, ","))
+	for _, w := range words {
+		err := ValidateProfanity(w)
+		if err != nil {
+			return fmt.Errorf("failed to set profanities: %w", err)
+		}
+	}
+
+	_, err := c.profanities("BanProfanity", words)
 	if err != nil {
-		return fmt.Errorf("failed to set profanities: %v", err)
+		return fmt.Errorf("failed to set profanities: %w", err)
 	}
 
 	return nil
 }
 
+// UnsetProfanities will stop censoring words, in as many commands as their length requires. Words
+// are not validated, so entries listed by Profanities can be removed as they are.
 func (c *Conn) UnsetProfanities(words ...string) error {
-	_, err := c.send("UnbanProfanity", strings.Join(words, ","))
+	_, err := c.profanities("UnbanProfanity", words)
+	if err != nil {
+		return fmt.Errorf("failed to remove profanities: %w", err)
+	}
+
+	return nil
+}
+
+// SyncProfanities will censor exactly the given words, comparing them with Profanities ignoring
+// case and sending only the differences. Every given word is validated before any command is sent,
+// while removed words are sent back as the server listed them. The words changed are returned even
+// when a later command fails.
+func (c *Conn) SyncProfanities(words []string) (ProfanityChanges, error) {
+	changes := ProfanityChanges{Added: []string{}, Removed: []string{}}
+
+	for _, w := range words {
+		err := ValidateProfanity(w)
+		if err != nil {
+			return changes, fmt.Errorf("failed to sync profanities: %w", err)
+		}
+	}
+
+	current, err := c.Profanities()
+	if err != nil {
+		return changes, fmt.Errorf("failed to sync profanities: %w", err)
+	}
+
+	add, remove := diffProfanities(current, words)
+
+	n, err := c.profanities("BanProfanity", add)
+	changes.Added = append(changes.Added, add[:n]...)
+
 	if err != nil {
-		return fmt.Errorf("failed to remove profanities: %v", err)
+		return changes, fmt.Errorf("failed to sync profanities: %w", err)
+	}
+
+	n, err = c.profanities("UnbanProfanity", remove)
+	changes.Removed = append(changes.Removed, remove[:n]...)
+
+	if err != nil {
+		return changes, fmt.Errorf("failed to sync profanities: %w", err)
+	}
+
+	return changes, nil
+}
+
+// ValidateProfanity returns an ErrInvalidProfanity error for words the server cannot censor. Words
+// are sent as a comma separated list and returned tab separated, with no way to escape either, so
+// words must be a single term without commas, quotes or whitespace.
+func ValidateProfanity(word string) error {
+	switch {
+	case word == "":
+		return fmt.Errorf("empty word: %w", ErrInvalidProfanity)
+	case len(word) > maxProfanityBatch:
+		return fmt.Errorf("%q is too long: %w", word, ErrInvalidProfanity)
+	case strings.ContainsAny(word, ",\""):
+		return fmt.Errorf("%q contains a comma or quote: %w", word, ErrInvalidProfanity)
+	case strings.IndexFunc(word, unicode.IsSpace) >= 0:
+		return fmt.Errorf("%q contains whitespace: %w", word, ErrInvalidProfanity)
 	}
 
 	return nil
 }
+
+// diffProfanities returns the desired words missing from current, and the words of current no
+// longer desired, ignoring case.
+func diffProfanities(current, desired []string) (add, remove []string) {
+	have := map[string]bool{}
+	for _, w := range current {
+		have[strings.ToLower(w)] = true
+	}
+
+	want := map[string]bool{}
+	add = []string{}
+
+	for _, w := range desired {
+		key := strings.ToLower(w)
+		if !have[key] && !want[key] {
+			add = append(add, w)
+		}
+
+		want[key] = true
+	}
+
+	remove = []string{}
+	for _, w := range current {
+		if !want[strings.ToLower(w)] {
+			remove = append(remove, w)
+		}
+	}
+
+	return add, remove
+}
+
+// profanities will send words to a profanity command in batches, returning how many words were
+// sent before any failure.
+func (c *Conn) profanities(cmd string, words []string) (int, error) {
+	sent := 0
+
+	for _, batch := range batchProfanities(words) {
+		_, err := c.send(cmd, strings.Join(batch, ","))
+		if err != nil {
+			return sent, err
+		}
+
+		sent += len(batch)
+	}
+
+	return sent, nil
+}
+
+// batchProfanities will split words in order into lists no longer than maxProfanityBatch once
+// joined with commas. Longer words are sent in a list of their own.
+func batchProfanities(words []string) [][]string {
+	batches := [][]string{}
+
+	for i := 0; i < len(words); {
+		batch := []string{words[i]}
+		length := len(words[i])
+
+		for _, w := range words[i+1:] {
+			if length+1+len(w) > maxProfanityBatch {
+				break
+			}
+
+			batch = append(batch, w)
+			length += 1 + len(w)
+		}
+
+		batches = append(batches, batch)
+		i += len(batch)
+	}
+
+	return batches
+}
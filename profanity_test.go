@@ -0,0 +1,187 @@
+package rcon
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDiffProfanities(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    []string
+		desired    []string
+		wantAdd    []string
+		wantRemove []string
+	}{
+		{
+			name:       "empty",
+			current:    []string{},
+			desired:    []string{},
+			wantAdd:    []string{},
+			wantRemove: []string{},
+		},
+		{
+			name:       "equal ignoring case",
+			current:    []string{"Bad", "worse"},
+			desired:    []string{"bad", "WORSE"},
+			wantAdd:    []string{},
+			wantRemove: []string{},
+		},
+		{
+			name:       "changed",
+			current:    []string{"bad", "Worse"},
+			desired:    []string{"bad", "awful"},
+			wantAdd:    []string{"awful"},
+			wantRemove: []string{"Worse"},
+		},
+		{
+			name:       "duplicates",
+			current:    []string{},
+			desired:    []string{"bad", "BAD", "bad"},
+			wantAdd:    []string{"bad"},
+			wantRemove: []string{},
+		},
+		{
+			// Entries the server lists but that could not be set are removed as listed.
+			name:       "invalid current",
+			current:    []string{"two words", "bad"},
+			desired:    []string{"bad"},
+			wantAdd:    []string{},
+			wantRemove: []string{"two words"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			add, remove := diffProfanities(tt.current, tt.desired)
+
+			if !equalStrings(add, tt.wantAdd) {
+				t.Errorf("diffProfanities() add = %q, want %q", add, tt.wantAdd)
+			}
+
+			if !equalStrings(remove, tt.wantRemove) {
+				t.Errorf("diffProfanities() remove = %q, want %q", remove, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestBatchProfanities(t *testing.T) {
+	word := func(n int, c string) string { return strings.Repeat(c, n) }
+
+	tests := []struct {
+		name  string
+		words []string
+		want  [][]string
+	}{
+		{
+			name:  "empty",
+			words: []string{},
+			want:  [][]string{},
+		},
+		{
+			name:  "single batch",
+			words: []string{"bad", "worse", "awful"},
+			want:  [][]string{{"bad", "worse", "awful"}},
+		},
+		{
+			name:  "exactly full",
+			words: []string{word(499, "a"), word(500, "b")},
+			want:  [][]string{{word(499, "a"), word(500, "b")}},
+		},
+		{
+			name:  "overflow",
+			words: []string{word(500, "a"), word(500, "b"), "c"},
+			want:  [][]string{{word(500, "a")}, {word(500, "b"), "c"}},
+		},
+		{
+			name:  "too long",
+			words: []string{"a", word(1001, "b"), "c"},
+			want:  [][]string{{"a"}, {word(1001, "b")}, {"c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := batchProfanities(tt.words)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("batchProfanities() = %d batches, want %d", len(got), len(tt.want))
+			}
+
+			for i := range got {
+				if !equalStrings(got[i], tt.want[i]) {
+					t.Errorf("batchProfanities()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateProfanity(t *testing.T) {
+	tests := []struct {
+		word    string
+		wantErr bool
+	}{
+		{word: "bad"},
+		{word: "ünicode"},
+		{word: strings.Repeat("a", maxProfanityBatch)},
+		{word: "", wantErr: true},
+		{word: strings.Repeat("a", maxProfanityBatch+1), wantErr: true},
+		{word: "bad,worse", wantErr: true},
+		{word: `"bad"`, wantErr: true},
+		{word: "two words", wantErr: true},
+		{word: "tab\tbed", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateProfanity(tt.word)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateProfanity(%q) error = %v, wantErr %v", tt.word, err, tt.wantErr)
+		}
+
+		if err != nil && !errors.Is(err, ErrInvalidProfanity) {
+			t.Errorf("ValidateProfanity(%q) error = %v, want ErrInvalidProfanity", tt.word, err)
+		}
+	}
+}
+
+func TestPlanProfanities(t *testing.T) {
+	tests := []struct {
+		name    string
+		current []string
+		desired []string
+		want    []string
+	}{
+		{
+			name:    "equal",
+			current: []string{"bad"},
+			desired: []string{"BAD"},
+			want:    []string{},
+		},
+		{
+			// Words are changed in a single Operation each way, however many commands they take.
+			name:    "changed",
+			current: []string{"bad", "worse", "two words"},
+			desired: []string{"bad", "awful", "vile"},
+			want: []string{
+				`add profanity "awful", "vile"`,
+				`remove profanity "worse", "two words"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, o := range planProfanities(tt.current, tt.desired) {
+				got = append(got, o.String())
+			}
+
+			if !equalStrings(got, tt.want) {
+				t.Errorf("planProfanities() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
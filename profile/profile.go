@@ -0,0 +1,162 @@
+// Package profile switches the settings of a server with its population, e.g. to a seeding profile
+// with autobalance off and a rotation of warfare maps while the server fills up.
+//
+// A Switcher polls the number of players and applies the Profile with the highest MinPlayers
+// reached. Each Profile is a desired rcon.State, applied with rcon.Reconcile so only the settings
+// that differ are changed. Hysteresis and a hold time keep a population hovering around a
+// threshold from switching back and forth.
+package profile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/poll"
+)
+
+// Profile represents the desired State of a server from a population upwards.
+type Profile struct {
+	Name       string     `yaml:"name" json:"name"`
+	MinPlayers int        `yaml:"min_players" json:"min_players"`
+	State      rcon.State `yaml:"state" json:"state"`
+}
+
+// Report represents the outcome of a single Poll.
+type Report struct {
+	Population int
+	Profile    string    // The Profile in effect after the Poll.
+	Pending    string    // A Profile waiting out the Hold, if any.
+	Switched   bool      // Whether the Profile was applied by this Poll.
+	Plan       rcon.Plan // The Operations applied when Switched.
+}
+
+// Switcher represents the population based profiles of a single server.
+type Switcher struct {
+	Conn     *rcon.Conn
+	Profiles []Profile
+
+	// Hysteresis is how many players the population must fall below the MinPlayers of the current
+	// Profile before a lower Profile applies. 5 by default.
+	Hysteresis int
+
+	// Hold is how long a different Profile must keep applying before it is switched to. 2 minutes
+	// by default.
+	Hold time.Duration
+
+	// Operator is the name Profiles are applied in, "profile" by default.
+	Operator string
+
+	// OnReport is called after every Poll started by Run.
+	OnReport func(Report, error)
+
+	mu      sync.Mutex
+	current string
+	pending string
+	since   time.Time // When the pending Profile started applying.
+	now     func() time.Time
+}
+
+// New returns a Switcher for a Conn between profiles.
+func New(c *rcon.Conn, profiles ...Profile) *Switcher {
+	return &Switcher{
+		Conn:       c,
+		Profiles:   profiles,
+		Hysteresis: 5,
+		Hold:       2 * time.Minute,
+		Operator:   "profile",
+		now:        time.Now,
+	}
+}
+
+// Run will poll the population every interval until ctx is done.
+func (s *Switcher) Run(ctx context.Context, interval time.Duration) error {
+	return poll.Every(ctx, interval, func() {
+		r, err := s.Poll()
+		if s.OnReport != nil {
+			s.OnReport(r, err)
+		}
+	})
+}
+
+// Current returns the name of the Profile in effect, or empty before the first successful Poll.
+func (s *Switcher) Current() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.current
+}
+
+// Poll will read the population and switch Profile when another has applied for Hold. The first
+// Poll applies its Profile immediately, so the server starts in a known state. A failed switch
+// keeps the previous Profile, and is retried by the next Poll.
+func (s *Switcher) Poll() (Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	population, _, err := s.Conn.Slots()
+	if err != nil {
+		return Report{Profile: s.current}, fmt.Errorf("failed to poll population: %w", err)
+	}
+
+	r := Report{Population: population, Profile: s.current}
+
+	target, ok := s.target(population)
+	if !ok {
+		return r, fmt.Errorf("no profile for a population of %d", population)
+	}
+
+	now := s.now()
+
+	if target.Name == s.current {
+		s.pending = ""
+		return r, nil
+	}
+
+	if s.current != "" {
+		if s.pending != target.Name {
+			s.pending, s.since = target.Name, now
+		}
+
+		if now.Sub(s.since) < s.Hold {
+			r.Pending = s.pending
+			return r, nil
+		}
+	}
+
+	plan, err := rcon.Reconcile(s.Conn.As(s.Operator), target.State, false)
+	r.Plan = plan
+
+	if err != nil {
+		return r, fmt.Errorf("failed to apply profile %s: %w", target.Name, err)
+	}
+
+	s.current, s.pending = target.Name, ""
+	r.Profile, r.Switched = target.Name, true
+
+	return r, nil
+}
+
+// target returns the Profile that should apply to a population, keeping the current Profile while
+// the population is within Hysteresis below its MinPlayers.
+func (s *Switcher) target(population int) (Profile, bool) {
+	profiles := append([]Profile{}, s.Profiles...)
+	sort.SliceStable(profiles, func(i, j int) bool {
+		return profiles[i].MinPlayers > profiles[j].MinPlayers
+	})
+
+	for _, p := range profiles {
+		if population >= p.MinPlayers {
+			return p, true
+		}
+
+		if p.Name == s.current && population >= p.MinPlayers-s.Hysteresis {
+			return p, true
+		}
+	}
+
+	return Profile{}, false
+}
@@ -0,0 +1,161 @@
+package profile
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+)
+
+// round represents a Poll and its outcome, relative to the first.
+type round struct {
+	at          time.Duration
+	population  int
+	want        string // The Profile in effect.
+	wantPending string
+}
+
+func TestPoll(t *testing.T) {
+	tests := []struct {
+		name         string
+		polls        []round
+		wantSwitches int
+	}{
+		{
+			name: "first",
+			polls: []round{
+				{at: 0, population: 60, want: "live"},
+			},
+			wantSwitches: 1,
+		},
+		{
+			name: "rising",
+			polls: []round{
+				{at: 0, population: 10, want: "seed"},
+				{at: time.Minute, population: 25, want: "seed", wantPending: "filling"},
+				{at: 2 * time.Minute, population: 30, want: "seed", wantPending: "filling"},
+				{at: 3 * time.Minute, population: 30, want: "filling"},
+				{at: 4 * time.Minute, population: 55, want: "filling", wantPending: "live"},
+				{at: 6 * time.Minute, population: 55, want: "live"},
+			},
+			wantSwitches: 3,
+		},
+		{
+			name: "hold restarted",
+			polls: []round{
+				{at: 0, population: 10, want: "seed"},
+				{at: time.Minute, population: 25, want: "seed", wantPending: "filling"},
+				{at: 2 * time.Minute, population: 10, want: "seed"},
+				{at: 3 * time.Minute, population: 25, want: "seed", wantPending: "filling"},
+				{at: 4 * time.Minute, population: 25, want: "seed", wantPending: "filling"},
+				{at: 5 * time.Minute, population: 25, want: "filling"},
+			},
+			wantSwitches: 2,
+		},
+		{
+			name: "falling",
+			polls: []round{
+				{at: 0, population: 55, want: "live"},
+				{at: time.Minute, population: 46, want: "live"},
+				{at: 2 * time.Minute, population: 45, want: "live"},
+				{at: 3 * time.Minute, population: 44, want: "live", wantPending: "filling"},
+				{at: 5 * time.Minute, population: 40, want: "filling"},
+				{at: 6 * time.Minute, population: 15, want: "filling"},
+				{at: 7 * time.Minute, population: 14, want: "filling", wantPending: "seed"},
+				{at: 9 * time.Minute, population: 14, want: "seed"},
+			},
+			wantSwitches: 3,
+		},
+		{
+			name: "within band",
+			polls: []round{
+				{at: 0, population: 52, want: "live"},
+				{at: time.Minute, population: 48, want: "live"},
+				{at: 2 * time.Minute, population: 51, want: "live"},
+				{at: 3 * time.Minute, population: 46, want: "live"},
+				{at: 4 * time.Minute, population: 50, want: "live"},
+				{at: 5 * time.Minute, population: 45, want: "live"},
+				{at: 6 * time.Minute, population: 49, want: "live"},
+			},
+			wantSwitches: 1,
+		},
+		{
+			name: "hovering above",
+			polls: []round{
+				{at: 0, population: 48, want: "filling"},
+				{at: time.Minute, population: 50, want: "filling", wantPending: "live"},
+				{at: 2 * time.Minute, population: 49, want: "filling"},
+				{at: 3 * time.Minute, population: 51, want: "filling", wantPending: "live"},
+				{at: 4 * time.Minute, population: 48, want: "filling"},
+			},
+			wantSwitches: 1,
+		},
+		{
+			name: "emptied",
+			polls: []round{
+				{at: 0, population: 60, want: "live"},
+				{at: time.Minute, population: 2, want: "live", wantPending: "seed"},
+				{at: 2 * time.Minute, population: 25, want: "live", wantPending: "filling"},
+				{at: 4 * time.Minute, population: 25, want: "filling"},
+			},
+			wantSwitches: 2,
+		},
+	}
+
+	start := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := rcontest.NewServer(t)
+
+			var mu sync.Mutex
+			population := 0
+
+			srv.Handle("get slots", func([]string) string {
+				mu.Lock()
+				defer mu.Unlock()
+
+				return strconv.Itoa(population) + "/100"
+			})
+
+			s := New(srv.Conn(t), profile("seed", 0), profile("filling", 20), profile("live", 50))
+
+			for i, p := range tt.polls {
+				mu.Lock()
+				population = p.population
+				mu.Unlock()
+
+				at := start.Add(p.at)
+				s.now = func() time.Time { return at }
+
+				r, err := s.Poll()
+				if err != nil {
+					t.Fatalf("poll %d: %v", i, err)
+				}
+
+				if r.Profile != p.want || r.Pending != p.wantPending {
+					t.Errorf("poll %d of %d players: profile %q pending %q, want %q and %q", i, p.population, r.Profile, r.Pending, p.want, p.wantPending)
+				}
+			}
+
+			switches := 0
+			for _, cmd := range srv.Sent() {
+				if rcon.CommandName([]string{cmd}) == "broadcast" {
+					switches++
+				}
+			}
+
+			if switches != tt.wantSwitches {
+				t.Errorf("switched %d times, want %d", switches, tt.wantSwitches)
+			}
+		})
+	}
+}
+
+// profile returns a Profile setting the broadcast to its name, as the broadcast is always applied.
+func profile(name string, min int) Profile {
+	return Profile{Name: name, MinPlayers: min, State: rcon.State{Broadcast: &name}}
+}
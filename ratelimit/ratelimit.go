@@ -0,0 +1,248 @@
+// Package ratelimit limits the rate of commands sent by a Conn, serving the most important first.
+//
+// A Limiter is a token bucket installed as Conn middleware. Commands wait for a token in one of
+// three queues, and waiting moderation actions are always served before interactive reads, which
+// are served before background polling, so a flood of polls never delays a kick.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+// Priority represents the class of a command. Lower values are served first.
+type Priority int
+
+// Priorities in the order they are served.
+const (
+	PriorityModeration Priority = iota
+	PriorityInteractive
+	PriorityBackground
+
+	priorities = 3
+)
+
+// ErrQueueFull is returned when a command would exceed the MaxQueue of its Priority.
+var ErrQueueFull = errors.New("rate limit queue is full")
+
+// Limiter represents a token bucket shared by every command of a Conn.
+type Limiter struct {
+	// Classify returns the Priority of a command, DefaultClassify when nil.
+	Classify func(cmds []string) Priority
+
+	// MaxQueue is the most commands that may wait in each Priority, or unlimited when zero.
+	// Commands beyond it fail with ErrQueueFull rather than piling up.
+	MaxQueue [priorities]int
+
+	mu     sync.Mutex
+	rate   float64 // Tokens per second.
+	burst  float64
+	tokens float64
+	last   time.Time
+	queues [priorities][]chan struct{}
+	timer  *time.Timer
+	stats  Stats
+	now    func() time.Time
+}
+
+// Stats represents the activity of a Limiter, indexed by Priority.
+type Stats struct {
+	Queued  [priorities]int           // Commands currently waiting.
+	Served  [priorities]uint64        // Commands sent.
+	Delayed [priorities]uint64        // Commands sent after waiting.
+	Waited  [priorities]time.Duration // Total time spent waiting.
+	Dropped [priorities]uint64        // Commands rejected with ErrQueueFull.
+}
+
+// New returns a Limiter allowing rate commands per second, with bursts of up to burst commands.
+// rate must be positive.
+func New(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// background holds the reads polled by watchers, e.g. a RosterWatcher, a LogStream, a profile
+// Switcher or the exporter.
+var background = map[string]bool{
+	"playerinfo":    true,
+	"showlog":       true,
+	"get playerids": true,
+	"get slots":     true,
+	"get gamestate": true,
+}
+
+// DefaultClassify treats commands that act on players or change the server as moderation, the
+// roster, population, game state, player lookups and log reads polled by watchers as background
+// polling, and every other read as interactive.
+func DefaultClassify(cmds []string) Priority {
+	switch {
+	case rcon.Mutating(cmds):
+		return PriorityModeration
+	case background[rcon.CommandName(cmds)]:
+		return PriorityBackground
+	default:
+		return PriorityInteractive
+	}
+}
+
+// Wrap is the rcon.Middleware of the Limiter, e.g. conn.Use(l.Wrap).
+func (l *Limiter) Wrap(next rcon.Handler) rcon.Handler {
+	return func(r rcon.Request) (string, error) {
+		classify := l.Classify
+		if classify == nil {
+			classify = DefaultClassify
+		}
+
+		err := l.Wait(classify(r.Cmds))
+		if err != nil {
+			return "", err
+		}
+
+		return next(r)
+	}
+}
+
+// Wait will block until a token is available for a command of Priority p.
+func (l *Limiter) Wait(p Priority) error {
+	if p < 0 || p >= priorities {
+		return fmt.Errorf("invalid priority %d", p)
+	}
+
+	l.mu.Lock()
+
+	l.refill()
+
+	// A token is taken immediately only when no command of the same or higher Priority waits.
+	if l.tokens >= 1 && !l.waiting(p) {
+		l.tokens--
+		l.stats.Served[p]++
+		l.mu.Unlock()
+
+		return nil
+	}
+
+	if l.MaxQueue[p] > 0 && len(l.queues[p]) >= l.MaxQueue[p] {
+		l.stats.Dropped[p]++
+		l.mu.Unlock()
+
+		return fmt.Errorf("%s: %w", p, ErrQueueFull)
+	}
+
+	ready := make(chan struct{})
+	l.queues[p] = append(l.queues[p], ready)
+	l.schedule()
+
+	start := l.now()
+	l.mu.Unlock()
+
+	<-ready
+
+	l.mu.Lock()
+	l.stats.Delayed[p]++
+	l.stats.Waited[p] += l.now().Sub(start)
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Stats returns the current queue depths and totals of the Limiter.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := l.stats
+	for p := range l.queues {
+		s.Queued[p] = len(l.queues[p])
+	}
+
+	return s
+}
+
+// refill will add the tokens accumulated since the last refill.
+func (l *Limiter) refill() {
+	now := l.now()
+
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+
+	l.last = now
+}
+
+// waiting reports whether a command of Priority p or higher is queued.
+func (l *Limiter) waiting(p Priority) bool {
+	for i := Priority(0); i <= p; i++ {
+		if len(l.queues[i]) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// schedule will arrange for dispatch to run once the next token is available.
+func (l *Limiter) schedule() {
+	if l.timer != nil {
+		return
+	}
+
+	d := time.Duration(0)
+	if l.tokens < 1 {
+		d = time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+
+	l.timer = time.AfterFunc(d, l.dispatch)
+}
+
+// dispatch will hand out available tokens to queued commands in order of Priority.
+func (l *Limiter) dispatch() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.timer = nil
+	l.refill()
+
+	for p := range l.queues {
+		for len(l.queues[p]) > 0 && l.tokens >= 1 {
+			l.tokens--
+			l.stats.Served[p]++
+
+			close(l.queues[p][0])
+			l.queues[p] = l.queues[p][1:]
+		}
+	}
+
+	for p := range l.queues {
+		if len(l.queues[p]) > 0 {
+			l.schedule()
+			return
+		}
+	}
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityModeration:
+		return "moderation"
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityBackground:
+		return "background"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
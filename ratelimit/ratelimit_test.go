@@ -0,0 +1,264 @@
+package ratelimit
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock represents a time advanced only by tests.
+type clock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.t = c.t.Add(d)
+}
+
+// newLimiter returns a Limiter on a clock. Its queues are dispatched by the tests, as the clock
+// never advances on its own.
+func newLimiter(rate float64, burst int) (*Limiter, *clock) {
+	c := &clock{t: time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)}
+
+	l := New(rate, burst)
+	l.now = c.now
+	l.last = c.now()
+
+	return l, c
+}
+
+// wait will call Wait in the background, returning a channel receiving its error.
+func wait(l *Limiter, p Priority) <-chan error {
+	done := make(chan error, 1)
+
+	go func() { done <- l.Wait(p) }()
+
+	return done
+}
+
+// queued will block until n commands of Priority p are queued.
+func queued(t *testing.T, l *Limiter, p Priority, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for l.Stats().Queued[p] != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d %s commands queued, want %d", l.Stats().Queued[p], p, n)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// served will fail unless done received a nil error.
+func served(t *testing.T, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("command not served")
+	}
+}
+
+// pending will fail unless done has received nothing.
+func pending(t *testing.T, done <-chan error) {
+	t.Helper()
+
+	select {
+	case err := <-done:
+		t.Fatalf("command served early with %v", err)
+	default:
+	}
+}
+
+func TestPriority(t *testing.T) {
+	l, c := newLimiter(1, 1)
+
+	err := l.Wait(PriorityBackground)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	background := wait(l, PriorityBackground)
+	queued(t, l, PriorityBackground, 1)
+
+	interactive := wait(l, PriorityInteractive)
+	queued(t, l, PriorityInteractive, 1)
+
+	moderation := wait(l, PriorityModeration)
+	queued(t, l, PriorityModeration, 1)
+
+	c.advance(time.Second)
+	l.dispatch()
+
+	served(t, moderation)
+	pending(t, interactive)
+	pending(t, background)
+
+	c.advance(time.Second)
+	l.dispatch()
+
+	served(t, interactive)
+	pending(t, background)
+
+	// A moderation command takes an available token ahead of the queued background command.
+	c.advance(time.Second)
+
+	err = l.Wait(PriorityModeration)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending(t, background)
+
+	c.advance(time.Second)
+	l.dispatch()
+
+	served(t, background)
+}
+
+func TestQueueFull(t *testing.T) {
+	l, c := newLimiter(1, 1)
+	l.MaxQueue[PriorityBackground] = 1
+
+	err := l.Wait(PriorityBackground)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := wait(l, PriorityBackground)
+	queued(t, l, PriorityBackground, 1)
+
+	err = l.Wait(PriorityBackground)
+	if !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Wait() = %v, want ErrQueueFull", err)
+	}
+
+	// Other priorities have their own limits.
+	moderation := wait(l, PriorityModeration)
+	queued(t, l, PriorityModeration, 1)
+
+	c.advance(time.Second)
+	l.dispatch()
+	served(t, moderation)
+
+	c.advance(time.Second)
+	l.dispatch()
+	served(t, first)
+
+	if dropped := l.Stats().Dropped; dropped != [priorities]uint64{0, 0, 1} {
+		t.Errorf("Dropped = %v, want a single background command", dropped)
+	}
+}
+
+func TestRefill(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   int
+		spent   int
+		elapsed time.Duration
+		want    float64
+	}{
+		{name: "full", rate: 1, burst: 3, want: 3},
+		{name: "spent", rate: 1, burst: 3, spent: 3, want: 0},
+		{name: "partial", rate: 2, burst: 3, spent: 3, elapsed: 500 * time.Millisecond, want: 1},
+		{name: "capped", rate: 1, burst: 3, spent: 3, elapsed: time.Hour, want: 3},
+		{name: "capped idle", rate: 10, burst: 5, elapsed: time.Minute, want: 5},
+		{name: "minimum burst", rate: 1, burst: 0, elapsed: time.Minute, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, c := newLimiter(tt.rate, tt.burst)
+
+			for i := 0; i < tt.spent; i++ {
+				err := l.Wait(PriorityModeration)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			c.advance(tt.elapsed)
+
+			l.mu.Lock()
+			l.refill()
+			got := l.tokens
+			l.mu.Unlock()
+
+			if got != tt.want {
+				t.Errorf("tokens = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	l, c := newLimiter(1, 2)
+
+	for i := 0; i < 2; i++ {
+		err := l.Wait(PriorityInteractive)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first := wait(l, PriorityBackground)
+	queued(t, l, PriorityBackground, 1)
+
+	second := wait(l, PriorityBackground)
+	queued(t, l, PriorityBackground, 2)
+
+	c.advance(time.Second)
+	l.dispatch()
+	served(t, first)
+
+	c.advance(2 * time.Second)
+	l.dispatch()
+	served(t, second)
+
+	want := Stats{
+		Served:  [priorities]uint64{0, 2, 2},
+		Delayed: [priorities]uint64{0, 0, 2},
+		Waited:  [priorities]time.Duration{0, 0, 4 * time.Second},
+	}
+
+	if got := l.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDefaultClassify(t *testing.T) {
+	tests := []struct {
+		cmds []string
+		want Priority
+	}{
+		{cmds: []string{"kick", `"Bob"`, `"teamkilling"`}, want: PriorityModeration},
+		{cmds: []string{"get", "playerids"}, want: PriorityBackground},
+		{cmds: []string{"showlog", "1"}, want: PriorityBackground},
+		{cmds: []string{"get", "vipids"}, want: PriorityInteractive},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultClassify(tt.cmds); got != tt.want {
+			t.Errorf("DefaultClassify(%q) = %s, want %s", tt.cmds, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package rcon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon/internal/poll"
+)
+
+// RosterEventKind represents the kind of a RosterEvent.
+type RosterEventKind string
+
+// Roster event kinds.
+const (
+	PlayerJoined  RosterEventKind = "joined"
+	PlayerLeft    RosterEventKind = "left"
+	PlayerRenamed RosterEventKind = "renamed"
+)
+
+// Roster event sources.
+const (
+	SourceRoster = "roster"
+	SourceLog    = "log"
+)
+
+// RosterEvent represents a change in the players online.
+type RosterEvent struct {
+	Kind   RosterEventKind
+	Player Player
+	Time   time.Time
+
+	// Start is the start of the player's session, and End its end for PlayerLeft.
+	Start time.Time
+	End   time.Time
+
+	// Previous is the player's former name for PlayerRenamed.
+	Previous string
+
+	// Initial is set for players already online when the watcher started, whose Start is the time
+	// they were first seen rather than when they joined.
+	Initial bool
+
+	// Source is SourceRoster or SourceLog.
+	Source string
+}
+
+// RosterWatcher represents the detection of players joining, leaving and renaming by comparing
+// successive Players lists. Log connect events may also be recorded, which take precedence as
+// they carry exact times.
+type RosterWatcher struct {
+	Conn *Conn
+
+	// LeaveAfter is the number of consecutive polls a player must be missing from before they are
+	// considered gone, so a truncated response never ends a session. 2 by default.
+	LeaveAfter int
+
+	// Grace is how long roster sightings of a player are ignored after a logged disconnect, as the
+	// roster can lag behind the log. 30s by default.
+	Grace time.Duration
+
+	// OnEvent is called for every RosterEvent, in order. It must not call the RosterWatcher.
+	OnEvent func(RosterEvent)
+
+	// OnError is called with errors from polls in Run.
+	OnError func(error)
+
+	mu      sync.Mutex
+	online  map[string]*presence
+	left    map[string]time.Time // Players with a logged disconnect, and its time.
+	started bool
+	now     func() time.Time
+}
+
+type presence struct {
+	player Player
+	start  time.Time
+	seen   time.Time // Last time the player was seen on the roster.
+	missed int
+	logged bool // Joined from the log and not yet seen on the roster, which may lag behind.
+}
+
+// NewRosterWatcher returns a RosterWatcher for a Conn.
+func NewRosterWatcher(c *Conn) *RosterWatcher {
+	return &RosterWatcher{
+		Conn:       c,
+		LeaveAfter: 2,
+		Grace:      30 * time.Second,
+		online:     map[string]*presence{},
+		left:       map[string]time.Time{},
+		now:        time.Now,
+	}
+}
+
+// Run will poll the roster at an interval until the context is cancelled.
+func (w *RosterWatcher) Run(ctx context.Context, interval time.Duration) error {
+	return poll.Every(ctx, interval, func() {
+		_, err := w.Poll()
+		if err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+	})
+}
+
+// Consume will record the Events of a channel, e.g. a LogStream subscription, until it is closed
+// or the context is cancelled.
+func (w *RosterWatcher) Consume(ctx context.Context, events <-chan Event) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			w.Record(e)
+		}
+	}
+}
+
+// Online returns the players currently online, keyed by ID64, with the start of their session.
+func (w *RosterWatcher) Online() map[string]time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	online := map[string]time.Time{}
+	for id, p := range w.online {
+		online[id] = p.start
+	}
+
+	return online
+}
+
+// Poll will compare the current roster with the previous one, returning the changes. A failed
+// poll changes nothing.
+func (w *RosterWatcher) Poll() ([]RosterEvent, error) {
+	players, err := w.Conn.Players()
+	if err != nil {
+		return nil, err
+	}
+
+	return w.update(players), nil
+}
+
+// update will compare players with the previous roster, returning the changes.
+func (w *RosterWatcher) update(players []Player) []RosterEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	initial := !w.started
+	w.started = true
+
+	events := []RosterEvent{}
+	present := map[string]bool{}
+
+	for _, p := range players {
+		if p.ID64 == "" {
+			continue
+		}
+
+		present[p.ID64] = true
+
+		existing, ok := w.online[p.ID64]
+		if !ok {
+			if t, ok := w.left[p.ID64]; ok && now.Sub(t) < w.Grace {
+				continue
+			}
+
+			delete(w.left, p.ID64)
+
+			w.online[p.ID64] = &presence{player: p, start: now, seen: now}
+			events = append(events, RosterEvent{Kind: PlayerJoined, Player: p, Time: now, Start: now, Initial: initial, Source: SourceRoster})
+
+			continue
+		}
+
+		existing.seen = now
+		existing.missed = 0
+		existing.logged = false
+
+		if p.Name != existing.player.Name && p.Name != "" {
+			previous := existing.player.Name
+			existing.player.Name = p.Name
+
+			events = append(events, RosterEvent{Kind: PlayerRenamed, Player: p, Time: now, Start: existing.start, Previous: previous, Source: SourceRoster})
+		}
+	}
+
+	for id, existing := range w.online {
+		if present[id] || existing.logged && now.Sub(existing.start) < w.Grace {
+			continue
+		}
+
+		existing.missed++
+		if existing.missed < w.leaveAfter() {
+			continue
+		}
+
+		delete(w.online, id)
+		events = append(events, RosterEvent{Kind: PlayerLeft, Player: existing.player, Time: now, Start: existing.start, End: existing.seen, Source: SourceRoster})
+	}
+
+	for id, t := range w.left {
+		if now.Sub(t) >= w.Grace {
+			delete(w.left, id)
+		}
+	}
+
+	w.emit(events)
+
+	return events
+}
+
+// Record will apply a log Event, starting a session on connect and ending it on disconnect.
+// Other kinds of Event are ignored.
+func (w *RosterWatcher) Record(e Event) {
+	if e.Player.ID64 == "" {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := e.Player.ID64
+
+	switch e.Kind {
+	case EventConnected:
+		delete(w.left, id)
+
+		if _, ok := w.online[id]; ok {
+			return
+		}
+
+		w.online[id] = &presence{player: e.Player, start: e.Time, seen: e.Time, logged: true}
+		w.emit([]RosterEvent{{Kind: PlayerJoined, Player: e.Player, Time: e.Time, Start: e.Time, Source: SourceLog}})
+	case EventDisconnected:
+		w.left[id] = e.Time
+
+		existing, ok := w.online[id]
+		if !ok {
+			return
+		}
+
+		delete(w.online, id)
+		w.emit([]RosterEvent{{Kind: PlayerLeft, Player: existing.player, Time: e.Time, Start: existing.start, End: e.Time, Source: SourceLog}})
+	}
+}
+
+func (w *RosterWatcher) emit(events []RosterEvent) {
+	if w.OnEvent == nil {
+		return
+	}
+
+	for _, e := range events {
+		w.OnEvent(e)
+	}
+}
+
+func (w *RosterWatcher) leaveAfter() int {
+	if w.LeaveAfter < 1 {
+		return 1
+	}
+
+	return w.LeaveAfter
+}
+
+// Duration returns the length of the session of a PlayerLeft event.
+func (e RosterEvent) Duration() time.Duration {
+	if e.End.IsZero() {
+		return 0
+	}
+
+	return e.End.Sub(e.Start)
+}
@@ -0,0 +1,199 @@
+package rcon
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+// rosterStep represents a roster poll or, when event is set, a log Event, relative to the first.
+type rosterStep struct {
+	at      time.Duration
+	players []Player
+	event   *Event
+	want    []string // Descriptions of the RosterEvents, sorted.
+}
+
+func TestRosterWatcher(t *testing.T) {
+	bob := Player{Name: "Bob", ID64: "76561198000000001"}
+	eve := Player{Name: "Eve", ID64: "76561198000000002"}
+	bobby := Player{Name: "Bobby", ID64: bob.ID64}
+
+	tests := []struct {
+		name       string
+		leaveAfter int
+		steps      []rosterStep
+	}{
+		{
+			name: "initial",
+			steps: []rosterStep{
+				{players: []Player{bob, eve}, want: []string{"joined Bob initial", "joined Eve initial"}},
+				{at: time.Minute, players: []Player{bob, eve}, want: []string{}},
+			},
+		},
+		{
+			name: "joined",
+			steps: []rosterStep{
+				{players: []Player{}, want: []string{}},
+				{at: time.Minute, players: []Player{bob}, want: []string{"joined Bob"}},
+			},
+		},
+		{
+			name: "left",
+			steps: []rosterStep{
+				{players: []Player{bob, eve}, want: []string{"joined Bob initial", "joined Eve initial"}},
+				{at: time.Minute, players: []Player{eve}, want: []string{}},
+				{at: 2 * time.Minute, players: []Player{eve}, want: []string{"left Bob after 0s"}},
+			},
+		},
+		{
+			name: "session",
+			steps: []rosterStep{
+				{players: []Player{}, want: []string{}},
+				{at: time.Minute, players: []Player{bob}, want: []string{"joined Bob"}},
+				{at: 5 * time.Minute, players: []Player{bob}, want: []string{}},
+				{at: 6 * time.Minute, players: []Player{}, want: []string{}},
+				{at: 7 * time.Minute, players: []Player{}, want: []string{"left Bob after 4m0s"}},
+			},
+		},
+		{
+			name:       "left immediately",
+			leaveAfter: 1,
+			steps: []rosterStep{
+				{players: []Player{bob}, want: []string{"joined Bob initial"}},
+				{at: time.Minute, players: []Player{}, want: []string{"left Bob after 0s"}},
+			},
+		},
+		{
+			name: "truncated",
+			steps: []rosterStep{
+				{players: []Player{bob, eve}, want: []string{"joined Bob initial", "joined Eve initial"}},
+				{at: time.Minute, players: []Player{bob}, want: []string{}},
+				{at: 2 * time.Minute, players: []Player{bob, eve}, want: []string{}},
+				{at: 3 * time.Minute, players: []Player{bob}, want: []string{}},
+			},
+		},
+		{
+			name: "renamed",
+			steps: []rosterStep{
+				{players: []Player{bob}, want: []string{"joined Bob initial"}},
+				{at: time.Minute, players: []Player{bobby}, want: []string{"renamed Bobby from Bob"}},
+				{at: 2 * time.Minute, players: []Player{bobby}, want: []string{}},
+			},
+		},
+		{
+			name: "name missing",
+			steps: []rosterStep{
+				{players: []Player{bob}, want: []string{"joined Bob initial"}},
+				{at: time.Minute, players: []Player{{ID64: bob.ID64}}, want: []string{}},
+			},
+		},
+		{
+			name: "id missing",
+			steps: []rosterStep{
+				{players: []Player{{Name: "Bob"}}, want: []string{}},
+			},
+		},
+		{
+			name: "logged connect",
+			steps: []rosterStep{
+				{players: []Player{}, want: []string{}},
+				{at: time.Minute, event: &Event{Kind: EventConnected, Player: bob}, want: []string{"joined Bob from log"}},
+				{at: time.Minute + 10*time.Second, players: []Player{}, want: []string{}},
+				{at: time.Minute + 20*time.Second, players: []Player{}, want: []string{}},
+				{at: time.Minute + 30*time.Second, players: []Player{bob}, want: []string{}},
+			},
+		},
+		{
+			name: "logged connect never seen",
+			steps: []rosterStep{
+				{players: []Player{}, want: []string{}},
+				{at: time.Minute, event: &Event{Kind: EventConnected, Player: bob}, want: []string{"joined Bob from log"}},
+				{at: 2 * time.Minute, players: []Player{}, want: []string{}},
+				{at: 3 * time.Minute, players: []Player{}, want: []string{"left Bob after 0s"}},
+			},
+		},
+		{
+			name: "logged disconnect",
+			steps: []rosterStep{
+				{players: []Player{bob}, want: []string{"joined Bob initial"}},
+				{at: time.Minute, event: &Event{Kind: EventDisconnected, Player: bob}, want: []string{"left Bob after 1m0s from log"}},
+				{at: time.Minute + 10*time.Second, players: []Player{bob}, want: []string{}},
+				{at: 2 * time.Minute, players: []Player{bob}, want: []string{"joined Bob"}},
+			},
+		},
+		{
+			name: "logged disconnect unknown",
+			steps: []rosterStep{
+				{players: []Player{}, want: []string{}},
+				{at: time.Minute, event: &Event{Kind: EventDisconnected, Player: bob}, want: []string{}},
+				{at: time.Minute + 10*time.Second, players: []Player{bob}, want: []string{}},
+			},
+		},
+	}
+
+	start := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewRosterWatcher(nil)
+			if tt.leaveAfter > 0 {
+				w.LeaveAfter = tt.leaveAfter
+			}
+
+			emitted := []RosterEvent{}
+			w.OnEvent = func(e RosterEvent) { emitted = append(emitted, e) }
+
+			for i, step := range tt.steps {
+				at := start.Add(step.at)
+				w.now = func() time.Time { return at }
+
+				emitted = emitted[:0]
+
+				if step.event != nil {
+					e := *step.event
+					e.Time = at
+					w.Record(e)
+				} else {
+					returned := w.update(step.players)
+					if len(returned) != len(emitted) {
+						t.Errorf("step %d: returned %d events, emitted %d", i, len(returned), len(emitted))
+					}
+				}
+
+				got := []string{}
+				for _, e := range emitted {
+					got = append(got, describe(e))
+				}
+
+				sort.Strings(got)
+
+				if fmt.Sprint(got) != fmt.Sprint(step.want) {
+					t.Errorf("step %d: events %q, want %q", i, got, step.want)
+				}
+			}
+		})
+	}
+}
+
+func describe(e RosterEvent) string {
+	s := fmt.Sprintf("%s %s", e.Kind, e.Player.Name)
+
+	switch e.Kind {
+	case PlayerLeft:
+		s += fmt.Sprintf(" after %v", e.Duration())
+	case PlayerRenamed:
+		s += " from " + e.Previous
+	}
+
+	if e.Initial {
+		s += " initial"
+	}
+
+	if e.Source == SourceLog {
+		s += " from log"
+	}
+
+	return s
+}
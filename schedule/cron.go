@@ -0,0 +1,239 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron represents a parsed cron expression with the time zone it is evaluated in.
+type Cron struct {
+	minute, hour, dom, month, dow uint64 // Bit sets of the values of each field.
+
+	// Whether the day of month and day of week fields are restricted, as a day matches either when
+	// both are.
+	domRestricted, dowRestricted bool
+
+	loc *time.Location
+}
+
+// descriptors are the shorthands accepted in place of the five fields.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	months = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	days   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// field describes the values accepted by a cron field.
+type field struct {
+	name     string
+	min, max int
+	names    []string // Names of the values from min, if any.
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: months},
+	{name: "day of week", min: 0, max: 7, names: days}, // Both 0 and 7 are Sunday.
+}
+
+// ParseCron returns the Cron of a standard five field expression, "minute hour day-of-month month
+// day-of-week", or of a descriptor such as "@daily". Fields accept "*", values, names such as "mon"
+// or "jan", ranges, lists and steps, e.g. "*/15", "1-5" or "sat,sun". Times are evaluated in loc,
+// time.Local when nil.
+func ParseCron(spec string, loc *time.Location) (Cron, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	expr := strings.TrimSpace(spec)
+	if d, ok := descriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return Cron{}, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", spec, len(parts))
+	}
+
+	sets := make([]uint64, len(fields))
+
+	for i, f := range fields {
+		set, err := f.parse(parts[i])
+		if err != nil {
+			return Cron{}, fmt.Errorf("invalid cron expression %q: %w", spec, err)
+		}
+
+		sets[i] = set
+	}
+
+	// Sunday may be written as 7.
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+
+	return Cron{
+		minute:        sets[0],
+		hour:          sets[1],
+		dom:           sets[2],
+		month:         sets[3],
+		dow:           sets[4],
+		domRestricted: !strings.HasPrefix(parts[2], "*"),
+		dowRestricted: !strings.HasPrefix(parts[4], "*"),
+		loc:           loc,
+	}, nil
+}
+
+// parse returns the bit set of the values of a field.
+func (f field) parse(s string) (uint64, error) {
+	set := uint64(0)
+
+	for _, item := range strings.Split(s, ",") {
+		rng, step := item, 1
+
+		if i := strings.Index(item, "/"); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s", item[i+1:], f.name)
+			}
+
+			rng, step = item[:i], n
+		}
+
+		lo, hi := f.min, f.max
+
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+
+			var err error
+
+			lo, err = f.value(bounds[0])
+			if err != nil {
+				return 0, err
+			}
+
+			hi, err = f.value(bounds[1])
+			if err != nil {
+				return 0, err
+			}
+
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q in %s", rng, f.name)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+
+			lo = v
+
+			// A single value with a step, e.g. "5/10", runs from the value to the maximum.
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+
+	return set, nil
+}
+
+// value returns a single value of a field, given as a number or a name.
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s, expected %d-%d", s, f.name, f.min, f.max)
+	}
+
+	return v, nil
+}
+
+// Next returns the first time matching the Cron strictly after t, or the zero time when none
+// exists within five years. A Cron not returned by ParseCron is evaluated in time.Local.
+func (c Cron) Next(t time.Time) time.Time {
+	loc := c.loc
+	if loc == nil {
+		loc = time.Local
+	}
+
+	t = t.In(loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		case !c.day(t):
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		case c.hour != allHours && repeated(t):
+			// A Cron at given hours runs once when the clock is set back, at the first occurrence.
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// allHours is the bit set of an hour field matching every hour.
+const allHours = 1<<24 - 1
+
+// repeated reports whether the wall clock time of t already occurred an hour earlier, as when a
+// daylight saving transition sets the clock back.
+func repeated(t time.Time) bool {
+	h, m, _ := t.Clock()
+	ph, pm, _ := t.Add(-time.Hour).Clock()
+
+	return h == ph && m == pm
+}
+
+// advance returns next, or t an hour later when next is not after t, as a wall clock time skipped
+// by a daylight saving transition may resolve to an earlier time.
+func advance(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+
+	return t.Add(time.Hour)
+}
+
+// day reports whether the day of t matches the Cron.
+func (c Cron) day(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+
+	return dom && dow
+}
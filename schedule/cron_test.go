@@ -0,0 +1,228 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Cron
+		wantErr bool
+	}{
+		{
+			spec: "* * * * *",
+			want: Cron{minute: 1<<60 - 1, hour: allHours, dom: 1<<32 - 2, month: 1<<13 - 2, dow: 1<<7 - 1},
+		},
+		{
+			spec: "*/15 3 1,15 jan-mar mon-fri",
+			want: Cron{
+				minute:        1 | 1<<15 | 1<<30 | 1<<45,
+				hour:          1 << 3,
+				dom:           1<<1 | 1<<15,
+				month:         1<<1 | 1<<2 | 1<<3,
+				dow:           1<<1 | 1<<2 | 1<<3 | 1<<4 | 1<<5,
+				domRestricted: true,
+				dowRestricted: true,
+			},
+		},
+		{
+			spec: "5/20 0 * * SAT,7",
+			want: Cron{minute: 1<<5 | 1<<25 | 1<<45, hour: 1, dom: 1<<32 - 2, month: 1<<13 - 2, dow: 1 | 1<<6, dowRestricted: true},
+		},
+		{
+			spec: " @Daily ",
+			want: Cron{minute: 1, hour: 1, dom: 1<<32 - 2, month: 1<<13 - 2, dow: 1<<7 - 1},
+		},
+		{spec: "", wantErr: true},
+		{spec: "* * * *", wantErr: true},
+		{spec: "60 * * * *", wantErr: true},
+		{spec: "* 24 * * *", wantErr: true},
+		{spec: "* * 0 * *", wantErr: true},
+		{spec: "* * * 13 *", wantErr: true},
+		{spec: "* * * * 8", wantErr: true},
+		{spec: "5-1 * * * *", wantErr: true},
+		{spec: "*/0 * * * *", wantErr: true},
+		{spec: "* * * * mon-", wantErr: true},
+		{spec: "@reboot", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCron(tt.spec, time.UTC)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCron(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+
+		if tt.wantErr {
+			continue
+		}
+
+		tt.want.loc = time.UTC
+
+		if got != tt.want {
+			t.Errorf("ParseCron(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	newYork := location(t, "America/New_York")
+	kolkata := location(t, "Asia/Kolkata")
+
+	tests := []struct {
+		name string
+		spec string
+		loc  *time.Location
+		from time.Time
+		want []time.Time // Successive runs from from.
+	}{
+		{
+			name: "every minute",
+			spec: "* * * * *",
+			loc:  time.UTC,
+			from: time.Date(2026, 10, 19, 20, 0, 30, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 10, 19, 20, 1, 0, 0, time.UTC),
+				time.Date(2026, 10, 19, 20, 2, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "end of year",
+			spec: "0 0 1 * *",
+			loc:  time.UTC,
+			from: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "leap day",
+			spec: "0 12 29 2 *",
+			loc:  time.UTC,
+			from: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC),
+				time.Date(2032, 2, 29, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "day of month or week",
+			spec: "0 18 13 * fri",
+			loc:  time.UTC,
+			from: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 10, 23, 18, 0, 0, 0, time.UTC),
+				time.Date(2026, 10, 30, 18, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 6, 18, 0, 0, 0, time.UTC),
+				time.Date(2026, 11, 13, 18, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "half hour offset",
+			spec: "0 4 * * *",
+			loc:  kolkata,
+			from: time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 10, 19, 22, 30, 0, 0, time.UTC),
+				time.Date(2026, 10, 20, 22, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "after a skipped hour",
+			spec: "0 3 * * *",
+			loc:  newYork,
+			from: time.Date(2026, 3, 7, 22, 17, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 3, 0, 0, 0, newYork),
+				time.Date(2026, 3, 9, 3, 0, 0, 0, newYork),
+			},
+		},
+		{
+			name: "in a skipped hour",
+			spec: "30 2 * * *",
+			loc:  newYork,
+			from: time.Date(2026, 3, 7, 22, 17, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 3, 9, 2, 30, 0, 0, newYork),
+			},
+		},
+		{
+			name: "hourly across a skipped hour",
+			spec: "0 * * * *",
+			loc:  newYork,
+			from: time.Date(2026, 3, 8, 1, 30, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 3, 8, 3, 0, 0, 0, newYork), // 07:00 UTC, an hour after 01:00 EST.
+				time.Date(2026, 3, 8, 4, 0, 0, 0, newYork),
+			},
+		},
+		{
+			name: "in a repeated hour",
+			spec: "30 1 * * *",
+			loc:  newYork,
+			from: time.Date(2026, 11, 1, 0, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), // 01:30 EDT.
+				time.Date(2026, 11, 2, 6, 30, 0, 0, time.UTC), // 01:30 EST, not on the first.
+			},
+		},
+		{
+			name: "hourly across a repeated hour",
+			spec: "0 * * * *",
+			loc:  newYork,
+			from: time.Date(2026, 11, 1, 0, 30, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 11, 1, 5, 0, 0, 0, time.UTC), // 01:00 EDT.
+				time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC), // 01:00 EST.
+				time.Date(2026, 11, 1, 7, 0, 0, 0, time.UTC), // 02:00 EST.
+			},
+		},
+		{
+			name: "never",
+			spec: "0 0 31 2 *",
+			loc:  time.UTC,
+			from: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.spec, tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := tt.from
+
+			for _, want := range tt.want {
+				got = c.Next(got)
+				if !got.Equal(want) {
+					t.Fatalf("Next() = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCronNextZero(t *testing.T) {
+	// A Cron not returned by ParseCron matches nothing, but must not panic.
+	got := Cron{}.Next(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC))
+	if !got.IsZero() {
+		t.Errorf("Next() = %v, want the zero time", got)
+	}
+}
+
+func location(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("missing time zone %s: %v", name, err)
+	}
+
+	return loc
+}
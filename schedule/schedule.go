@@ -0,0 +1,316 @@
+// Package schedule runs recurring and one-shot jobs against a server, e.g. a nightly rotation swap
+// or a broadcast at a given time.
+//
+// Jobs are declared in code or in a YAML file, with cron expressions evaluated in a time zone.
+// The time each job last ran is persisted, so a job missed while the program was down is either
+// skipped or run once on start, according to its Missed policy. Every run is kept in a history
+// with its result.
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/jsonfile"
+)
+
+// Missed policies, for runs due while the Scheduler was not running.
+const (
+	MissedSkip    = "skip"     // Wait for the next scheduled time.
+	MissedRunOnce = "run_once" // Run once as soon as possible, however many runs were missed.
+)
+
+// Task represents the work of a Job.
+type Task func(ctx context.Context, c *rcon.Conn) error
+
+// Job represents a Task run on a schedule.
+type Job struct {
+	Name string
+
+	// Cron is when a recurring Job runs. A Job without a Cron runs once, At the given time.
+	Cron *Cron
+	At   time.Time
+
+	// Missed is the policy for runs due while the Scheduler was not running, MissedSkip by default.
+	Missed string
+
+	Task Task
+}
+
+// Run represents a single run of a Job.
+type Run struct {
+	Job      string        `json:"job"`
+	Due      time.Time     `json:"due"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+	Missed   bool          `json:"missed,omitempty"`  // Whether the run was due while the Scheduler was not running.
+	Skipped  bool          `json:"skipped,omitempty"` // Whether the missed run was skipped, as per MissedSkip.
+	Error    string        `json:"error,omitempty"`
+}
+
+// Scheduler represents the Jobs of a single server, with their state persisted as JSON.
+type Scheduler struct {
+	Conn *rcon.Conn
+
+	// Tolerance is how late a run may start before it is considered missed. A minute by default.
+	Tolerance time.Duration
+
+	// HistoryLimit is the number of Runs kept, dropping the oldest. 1000 by default.
+	HistoryLimit int
+
+	// OnRun is called after every Run.
+	OnRun func(Run)
+
+	// OnError is called with errors saving the state in Run.
+	OnError func(error)
+
+	mu    sync.Mutex
+	path  string
+	jobs  []Job
+	state state
+	now   func() time.Time
+}
+
+// state is the persisted part of a Scheduler.
+type state struct {
+	Last    map[string]time.Time `json:"last"` // When each Job was last due and handled, whether run or skipped.
+	History []Run                `json:"history"`
+}
+
+// New will return a Scheduler for a Conn, loading its state from path, or creating an empty one
+// if the file does not exist. An empty path keeps the state in memory.
+func New(c *rcon.Conn, path string) (*Scheduler, error) {
+	s := &Scheduler{
+		Conn:         c,
+		Tolerance:    time.Minute,
+		HistoryLimit: 1000,
+		path:         path,
+		state:        state{Last: map[string]time.Time{}, History: []Run{}},
+		now:          time.Now,
+	}
+
+	err := jsonfile.Load(path, &s.state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schedule state: %v", err)
+	}
+
+	if s.state.Last == nil {
+		s.state.Last = map[string]time.Time{}
+	}
+
+	if s.state.History == nil {
+		s.state.History = []Run{}
+	}
+
+	return s, nil
+}
+
+// Add will schedule Jobs. Recurring Jobs never run before have their first run at the next
+// scheduled time, while one-shot Jobs are due At their time, subject to their Missed policy.
+func (s *Scheduler) Add(jobs ...Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range jobs {
+		switch {
+		case j.Name == "":
+			return errors.New("failed to add job: missing name")
+		case j.Task == nil:
+			return fmt.Errorf("failed to add job %s: missing task", j.Name)
+		case j.Cron == nil && j.At.IsZero():
+			return fmt.Errorf("failed to add job %s: missing cron or time", j.Name)
+		case j.Missed != "" && j.Missed != MissedSkip && j.Missed != MissedRunOnce:
+			return fmt.Errorf("failed to add job %s: unknown missed policy %q", j.Name, j.Missed)
+		}
+
+		for _, existing := range s.jobs {
+			if existing.Name == j.Name {
+				return fmt.Errorf("failed to add job %s: duplicate name", j.Name)
+			}
+		}
+
+		if _, ok := s.state.Last[j.Name]; !ok && j.Cron != nil {
+			s.state.Last[j.Name] = s.now()
+		}
+
+		s.jobs = append(s.jobs, j)
+	}
+
+	return nil
+}
+
+// Next returns when a Job is next due, or the zero time when it is not.
+func (s *Scheduler) Next(name string) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return s.due(j)
+		}
+	}
+
+	return time.Time{}
+}
+
+// due returns when a Job is next due after its last handled time, or the zero time when never.
+func (s *Scheduler) due(j Job) time.Time {
+	last := s.state.Last[j.Name]
+
+	if j.Cron == nil {
+		if !last.IsZero() {
+			return time.Time{}
+		}
+
+		return j.At
+	}
+
+	return j.Cron.Next(last)
+}
+
+// History returns the Runs of a Job, or of every Job when name is empty, newest first. No more
+// than limit Runs are returned, unless limit is zero.
+func (s *Scheduler) History(name string, limit int) []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := []Run{}
+
+	for i := len(s.state.History) - 1; i >= 0; i-- {
+		r := s.state.History[i]
+		if name != "" && r.Job != name {
+			continue
+		}
+
+		runs = append(runs, r)
+
+		if limit > 0 && len(runs) >= limit {
+			break
+		}
+	}
+
+	return runs
+}
+
+// Run will run due Jobs every interval until ctx is done. The interval should be well below a
+// minute, the resolution of cron expressions.
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		_, err := s.Tick(ctx)
+		if err != nil && s.OnError != nil {
+			s.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Tick will run every Job due, in order of due time, returning their Runs. Runs due longer than
+// Tolerance ago are missed, and are skipped or run according to the Missed policy of their Job.
+// Skipped runs are recorded and returned too, without running their Task. The state is saved
+// when any Job was due.
+func (s *Scheduler) Tick(ctx context.Context) ([]Run, error) {
+	s.mu.Lock()
+
+	now := s.now()
+
+	type pending struct {
+		job             Job
+		due             time.Time
+		missed, skipped bool
+	}
+
+	list := []pending{}
+	handled := false
+
+	for _, j := range s.jobs {
+		due := s.due(j)
+		if due.IsZero() || due.After(now) {
+			continue
+		}
+
+		missed := now.Sub(due) > s.Tolerance
+		s.state.Last[j.Name] = now
+		handled = true
+
+		skipped := missed && j.Missed != MissedRunOnce
+
+		list = append(list, pending{job: j, due: due, missed: missed, skipped: skipped})
+	}
+
+	sort.SliceStable(list, func(i, k int) bool {
+		return list[i].due.Before(list[k].due)
+	})
+
+	s.mu.Unlock()
+
+	runs := []Run{}
+
+	for _, p := range list {
+		r := Run{Job: p.job.Name, Due: p.due, Start: s.now(), Missed: p.missed, Skipped: p.skipped}
+
+		if !p.skipped {
+			err := p.job.Task(ctx, s.Conn)
+
+			r.Duration = s.now().Sub(r.Start)
+			if err != nil {
+				r.Error = err.Error()
+			}
+		}
+
+		runs = append(runs, r)
+
+		s.record(r)
+
+		if s.OnRun != nil {
+			s.OnRun(r)
+		}
+	}
+
+	if !handled {
+		return runs, nil
+	}
+
+	return runs, s.Save()
+}
+
+// record will add a Run to the history, dropping the oldest beyond HistoryLimit.
+func (s *Scheduler) record(r Run) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state.History = append(s.state.History, r)
+
+	if s.HistoryLimit > 0 && len(s.state.History) > s.HistoryLimit {
+		s.state.History = s.state.History[len(s.state.History)-s.HistoryLimit:]
+	}
+}
+
+// Save will write the state of the Scheduler to disk, replacing the previous file atomically.
+func (s *Scheduler) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		return nil
+	}
+
+	err := jsonfile.Save(s.path, s.state)
+	if err != nil {
+		return fmt.Errorf("failed to save schedule state: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+)
+
+func TestTick(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	ran := map[string]bool{}
+
+	task := func(name string, err error) Task {
+		return func(ctx context.Context, c *rcon.Conn) error {
+			ran[name] = true
+			return err
+		}
+	}
+
+	s, err := New(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.now = func() time.Time { return now }
+
+	err = s.Add(
+		Job{Name: "future", At: now.Add(time.Minute), Task: task("future", nil)},
+		Job{Name: "late", At: now.Add(-30 * time.Second), Task: task("late", nil)},
+		Job{Name: "missed", At: now.Add(-time.Hour), Task: task("missed", nil)},
+		Job{Name: "run-once", At: now.Add(-2 * time.Hour), Missed: MissedRunOnce, Task: task("run-once", nil)},
+		Job{Name: "failed", At: now.Add(-10 * time.Second), Task: task("failed", errors.New("FAIL"))},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := s.Tick(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Run{
+		{Job: "run-once", Due: now.Add(-2 * time.Hour), Start: now, Missed: true},
+		{Job: "missed", Due: now.Add(-time.Hour), Start: now, Missed: true, Skipped: true},
+		{Job: "late", Due: now.Add(-30 * time.Second), Start: now},
+		{Job: "failed", Due: now.Add(-10 * time.Second), Start: now, Error: "FAIL"},
+	}
+
+	if len(runs) != len(want) {
+		t.Fatalf("Tick() = %+v, want %+v", runs, want)
+	}
+
+	for i := range want {
+		if runs[i] != want[i] {
+			t.Errorf("Tick()[%d] = %+v, want %+v", i, runs[i], want[i])
+		}
+	}
+
+	if ran["future"] || ran["missed"] || !ran["late"] || !ran["run-once"] || !ran["failed"] {
+		t.Errorf("Tick() ran %v", ran)
+	}
+
+	history := s.History("missed", 0)
+	if len(history) != 1 || !history[0].Skipped {
+		t.Errorf("History() = %+v, want the skipped run", history)
+	}
+
+	// Handled Jobs are not due again.
+	runs, err = s.Tick(context.Background())
+	if err != nil || len(runs) != 0 {
+		t.Errorf("Tick() = %+v, %v, want no runs", runs, err)
+	}
+}
@@ -0,0 +1,180 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/vipmanager"
+	"gopkg.in/yaml.v3"
+)
+
+// Tasks of JobConfig available without registration.
+const (
+	TaskState     = "state"     // Reconcile the State of the JobConfig.
+	TaskBroadcast = "broadcast" // Set the Message of the JobConfig as the broadcast.
+)
+
+// ApplyState returns a Task reconciling a server with a State, e.g. a weekend rotation.
+func ApplyState(s rcon.State) Task {
+	return func(ctx context.Context, c *rcon.Conn) error {
+		_, err := rcon.Reconcile(c, s, false)
+		return err
+	}
+}
+
+// Broadcast returns a Task setting the broadcast message.
+func Broadcast(message string) Task {
+	return func(ctx context.Context, c *rcon.Conn) error {
+		return c.SetBroadcast(message)
+	}
+}
+
+// ExpireVIPs returns a Task removing expired VIP memberships, i.e. a Check of the Manager.
+func ExpireVIPs(m *vipmanager.Manager) Task {
+	return func(ctx context.Context, c *rcon.Conn) error {
+		_, err := m.Check()
+		return err
+	}
+}
+
+// JobConfig represents a Job declared in a file.
+type JobConfig struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Cron is the cron expression of a recurring Job, evaluated in Timezone, an IANA name such as
+	// "Europe/London". At is the time of a one-shot Job, e.g. "2026-11-07 20:00" read in Timezone,
+	// or "2026-11-07T20:00:00Z" with its own zone. A Job has either a Cron or an At.
+	Cron     string `yaml:"cron,omitempty" json:"cron,omitempty"`
+	At       string `yaml:"at,omitempty" json:"at,omitempty"`
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	Missed string `yaml:"missed,omitempty" json:"missed,omitempty"`
+
+	// Task is TaskState, TaskBroadcast, or the name of a registered Task.
+	Task    string      `yaml:"task" json:"task"`
+	State   *rcon.State `yaml:"state,omitempty" json:"state,omitempty"`
+	Message string      `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+// LoadJobs will read Jobs from a YAML or JSON file, resolving the names of their Tasks in tasks.
+func LoadJobs(path string, tasks map[string]Task) ([]Job, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open jobs file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadJobs(f, tasks)
+}
+
+// ReadJobs will decode Jobs from YAML or JSON, a document with a list of JobConfig under "jobs".
+func ReadJobs(r io.Reader, tasks map[string]Task) ([]Job, error) {
+	doc := struct {
+		Jobs []JobConfig `yaml:"jobs"`
+	}{}
+
+	err := yaml.NewDecoder(r).Decode(&doc)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to decode jobs: %w", err)
+	}
+
+	jobs := []Job{}
+
+	for _, jc := range doc.Jobs {
+		j, err := jc.Job(tasks)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs = append(jobs, j)
+	}
+
+	return jobs, nil
+}
+
+// Job returns the Job of a JobConfig, resolving the name of its Task in tasks.
+func (jc JobConfig) Job(tasks map[string]Task) (Job, error) {
+	j := Job{Name: jc.Name, Missed: jc.Missed}
+
+	if jc.Cron != "" && jc.At != "" {
+		return Job{}, fmt.Errorf("invalid job %s: both cron and at are set", jc.Name)
+	}
+
+	loc := time.Local
+
+	if jc.Timezone != "" {
+		var err error
+
+		loc, err = time.LoadLocation(jc.Timezone)
+		if err != nil {
+			return Job{}, fmt.Errorf("invalid timezone of job %s: %w", jc.Name, err)
+		}
+	}
+
+	if jc.Cron != "" {
+		cron, err := ParseCron(jc.Cron, loc)
+		if err != nil {
+			return Job{}, fmt.Errorf("invalid job %s: %w", jc.Name, err)
+		}
+
+		j.Cron = &cron
+	}
+
+	if jc.At != "" {
+		at, err := parseAt(jc.At, loc)
+		if err != nil {
+			return Job{}, fmt.Errorf("invalid job %s: %w", jc.Name, err)
+		}
+
+		j.At = at
+	}
+
+	switch jc.Task {
+	case TaskState:
+		if jc.State == nil {
+			return Job{}, fmt.Errorf("invalid job %s: missing state", jc.Name)
+		}
+
+		j.Task = ApplyState(*jc.State)
+	case TaskBroadcast:
+		j.Task = Broadcast(jc.Message)
+	default:
+		task, ok := tasks[jc.Task]
+		if !ok {
+			return Job{}, fmt.Errorf("invalid job %s: unknown task %q", jc.Name, jc.Task)
+		}
+
+		j.Task = task
+	}
+
+	return j, nil
+}
+
+// layouts are the formats accepted for the time of a one-shot Job without a zone.
+var layouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+// parseAt returns the time of a one-shot Job, read in loc unless it has a zone of its own.
+func parseAt(s string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err == nil {
+		return t, nil
+	}
+
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, expected e.g. \"2006-01-02 15:04\"", s)
+}
@@ -0,0 +1,94 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJobConfigJob(t *testing.T) {
+	london := location(t, "Europe/London")
+
+	tests := []struct {
+		name     string
+		config   JobConfig
+		wantAt   time.Time
+		wantCron bool
+		wantErr  bool
+	}{
+		{
+			name:     "cron",
+			config:   JobConfig{Name: "nightly", Cron: "0 4 * * *", Timezone: "Europe/London", Task: TaskBroadcast},
+			wantCron: true,
+		},
+		{
+			name:   "at in timezone",
+			config: JobConfig{Name: "event", At: "2026-11-07 20:00", Timezone: "Europe/London", Task: TaskBroadcast},
+			wantAt: time.Date(2026, 11, 7, 20, 0, 0, 0, london),
+		},
+		{
+			name:   "at with seconds",
+			config: JobConfig{Name: "event", At: "2026-07-04T20:00:30", Timezone: "Europe/London", Task: TaskBroadcast},
+			wantAt: time.Date(2026, 7, 4, 19, 0, 30, 0, time.UTC),
+		},
+		{
+			name:   "at with zone",
+			config: JobConfig{Name: "event", At: "2026-11-07T20:00:00+02:00", Timezone: "Europe/London", Task: TaskBroadcast},
+			wantAt: time.Date(2026, 11, 7, 18, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "cron and at",
+			config:  JobConfig{Name: "event", Cron: "0 4 * * *", At: "2026-11-07 20:00", Task: TaskBroadcast},
+			wantErr: true,
+		},
+		{
+			name:    "invalid at",
+			config:  JobConfig{Name: "event", At: "tomorrow", Task: TaskBroadcast},
+			wantErr: true,
+		},
+		{
+			name:    "invalid timezone",
+			config:  JobConfig{Name: "event", At: "2026-11-07 20:00", Timezone: "Mars/Olympus", Task: TaskBroadcast},
+			wantErr: true,
+		},
+		{
+			name:    "invalid cron",
+			config:  JobConfig{Name: "nightly", Cron: "0 4 * *", Task: TaskBroadcast},
+			wantErr: true,
+		},
+		{
+			name:    "missing state",
+			config:  JobConfig{Name: "weekend", Cron: "0 18 * * fri", Task: TaskState},
+			wantErr: true,
+		},
+		{
+			name:    "unknown task",
+			config:  JobConfig{Name: "weekend", Cron: "0 18 * * fri", Task: "reboot"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := tt.config.Job(nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Job() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !j.At.Equal(tt.wantAt) {
+				t.Errorf("Job().At = %v, want %v", j.At, tt.wantAt)
+			}
+
+			if (j.Cron != nil) != tt.wantCron {
+				t.Errorf("Job().Cron = %v, want a Cron %v", j.Cron, tt.wantCron)
+			}
+
+			if j.Task == nil {
+				t.Error("Job().Task = nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Role represents the permissions of an operator. Each Role includes those below it.
+type Role int
+
+// Roles in increasing order of privilege.
+const (
+	RoleViewer    Role = iota // May read server state.
+	RoleModerator             // May also kick, punish, message, switch and temporarily ban players.
+	RoleSenior                // May also permanently ban, pardon, manage VIPs, maps and profanities.
+	RoleOwner                 // May also manage admins, settings and send raw commands.
+)
+
+// DefaultMaxBanHours are the longest temporary bans of each Role. Roles missing from the map
+// have no limit.
+var DefaultMaxBanHours = map[Role]int{
+	RoleModerator: 24,
+	RoleSenior:    24 * 7,
+}
+
+// ErrForbidden is returned when an operator's Role does not permit a call.
+var ErrForbidden = errors.New("operation not permitted")
+
+// Scoped represents a Conn restricted to the permissions of a single operator. The operator's name
+// is recorded as the admin of every ban it issues, and passed with every command to the middlewares
+// of the Conn, e.g. to be audited.
+type Scoped struct {
+	conn *Conn
+
+	Operator string
+	Role     Role
+
+	// MaxBanHours is the longest temporary ban the operator may issue, or unlimited when zero.
+	MaxBanHours int
+}
+
+// Scoped returns a handle to the Conn for an operator with a Role, limiting temporary bans to the
+// Role's DefaultMaxBanHours.
+func (c *Conn) Scoped(operator string, role Role) *Scoped {
+	return &Scoped{
+		conn:        c,
+		Operator:    operator,
+		Role:        role,
+		MaxBanHours: DefaultMaxBanHours[role],
+	}
+}
+
+// ParseRole returns the Role for a name, e.g. "moderator".
+func ParseRole(s string) (Role, error) {
+	for _, r := range []Role{RoleViewer, RoleModerator, RoleSenior, RoleOwner} {
+		if strings.EqualFold(s, r.String()) {
+			return r, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown role %q, expected viewer, moderator, senior or owner", s)
+}
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleModerator:
+		return "moderator"
+	case RoleSenior:
+		return "senior"
+	case RoleOwner:
+		return "owner"
+	default:
+		return fmt.Sprintf("role(%d)", int(r))
+	}
+}
+
+// Can reports whether the operator's Role includes role.
+func (s *Scoped) Can(role Role) bool {
+	return s.Role >= role
+}
+
+// as returns the handle to the Conn sending commands in the operator's name.
+func (s *Scoped) as() *Conn {
+	return s.conn.As(s.Operator)
+}
+
+// allow will return an ErrForbidden error unless the operator's Role includes role.
+func (s *Scoped) allow(role Role, action string) error {
+	if s.Can(role) {
+		return nil
+	}
+
+	return fmt.Errorf("%s (%s) may not %s: %w", s.Operator, s.Role, action, ErrForbidden)
+}
+
+// Name returns the server name.
+func (s *Scoped) Name() (string, error) {
+	return s.as().Name()
+}
+
+// Slots returns the number of occupied and total player slots.
+func (s *Scoped) Slots() (numerator, denominator int, err error) {
+	return s.as().Slots()
+}
+
+// GameState returns the progress of the current match.
+func (s *Scoped) GameState() (GameState, error) {
+	return s.as().GameState()
+}
+
+// Settings returns the server settings.
+func (s *Scoped) Settings() (Settings, error) {
+	return s.as().Settings()
+}
+
+// Players returns all active Players.
+func (s *Scoped) Players() ([]Player, error) {
+	return s.as().Players()
+}
+
+// Player returns a Player for a given username.
+func (s *Scoped) Player(username string) (Player, error) {
+	return s.as().Player(username)
+}
+
+// Admins returns every admin of the server.
+func (s *Scoped) Admins() ([]Admin, error) {
+	return s.as().Admins()
+}
+
+// AdminGroups returns the admin roles of the server.
+func (s *Scoped) AdminGroups() ([]string, error) {
+	return s.as().AdminGroups()
+}
+
+// VIPs returns every VIP of the server.
+func (s *Scoped) VIPs() ([]VIP, error) {
+	return s.as().VIPs()
+}
+
+// BannedTemporarily returns every temporary ban.
+func (s *Scoped) BannedTemporarily() ([]Ban, error) {
+	return s.as().BannedTemporarily()
+}
+
+// BannedPermanently returns every permanent ban.
+func (s *Scoped) BannedPermanently() ([]Ban, error) {
+	return s.as().BannedPermanently()
+}
+
+// Map returns the current map.
+func (s *Scoped) Map() (Map, error) {
+	return s.as().Map()
+}
+
+// Maps returns all possible maps that can be in rotation.
+func (s *Scoped) Maps() ([]Map, error) {
+	return s.as().Maps()
+}
+
+// Rotation returns the current map rotation.
+func (s *Scoped) Rotation() ([]Map, error) {
+	return s.as().Rotation()
+}
+
+// Profanities returns the censored words.
+func (s *Scoped) Profanities() ([]string, error) {
+	return s.as().Profanities()
+}
+
+// Logs returns the Events written to the server log within a duration.
+func (s *Scoped) Logs(since time.Duration) ([]Event, error) {
+	return s.as().Logs(since)
+}
+
+// Kick will remove an active player. Requires RoleModerator.
+func (s *Scoped) Kick(p Player, reason string) error {
+	err := s.allow(RoleModerator, "kick")
+	if err != nil {
+		return err
+	}
+
+	return s.as().Kick(p, reason)
+}
+
+// Punish will kill an active player. Requires RoleModerator.
+func (s *Scoped) Punish(p Player, reason string) error {
+	err := s.allow(RoleModerator, "punish")
+	if err != nil {
+		return err
+	}
+
+	return s.as().Punish(p, reason)
+}
+
+// Message will send a message to an active player. Requires RoleModerator.
+func (s *Scoped) Message(p Player, message string) error {
+	err := s.allow(RoleModerator, "message players")
+	if err != nil {
+		return err
+	}
+
+	return s.as().Message(p, message)
+}
+
+// SetSwitchTeamNow will move a player to the other team. Requires RoleModerator.
+func (s *Scoped) SetSwitchTeamNow(p Player) error {
+	err := s.allow(RoleModerator, "switch teams")
+	if err != nil {
+		return err
+	}
+
+	return s.as().SetSwitchTeamNow(p)
+}
+
+// SetSwitchTeamOnDeath will move a player to the other team on their next death. Requires RoleModerator.
+func (s *Scoped) SetSwitchTeamOnDeath(p Player) error {
+	err := s.allow(RoleModerator, "switch teams")
+	if err != nil {
+		return err
+	}
+
+	return s.as().SetSwitchTeamOnDeath(p)
+}
+
+// SetBroadcast will set the broadcast message. Requires RoleModerator.
+func (s *Scoped) SetBroadcast(message string) error {
+	err := s.allow(RoleModerator, "broadcast")
+	if err != nil {
+		return err
+	}
+
+	return s.as().SetBroadcast(message)
+}
+
+// BanTemporarily will ban a player for the specified hours in the operator's name. Requires
+// RoleModerator, and hours within MaxBanHours.
+func (s *Scoped) BanTemporarily(p Player, hours int, reason string) error {
+	err := s.allow(RoleModerator, "ban players")
+	if err != nil {
+		return err
+	}
+
+	if hours < 1 {
+		return fmt.Errorf("failed to ban %s: temporary bans must last at least one hour", p)
+	}
+
+	if s.MaxBanHours > 0 && hours > s.MaxBanHours {
+		return fmt.Errorf("%s (%s) may not ban for more than %d hours: %w", s.Operator, s.Role, s.MaxBanHours, ErrForbidden)
+	}
+
+	return s.as().BanTemporarily(p, hours, reason, s.Operator)
+}
+
+// BanPermanently will ban a player in the operator's name. Requires RoleSenior.
+func (s *Scoped) BanPermanently(p Player, reason string) error {
+	err := s.allow(RoleSenior, "permanently ban players")
+	if err != nil {
+		return err
+	}
+
+	return s.as().BanPermanently(p, reason, s.Operator)
+}
+
+// BanRemove will remove a player's ban. Requires RoleSenior.
+func (s *Scoped) BanRemove(p Player) error {
+	err := s.allow(RoleSenior, "remove bans")
+	if err != nil {
+		return err
+	}
+
+	return s.as().BanRemove(p)
+}
+
+// VIPAdd will add a VIP. Requires RoleSenior.
+func (s *Scoped) VIPAdd(v VIP) error {
+	err := s.allow(RoleSenior, "add VIPs")
+	if err != nil {
+		return err
+	}
+
+	return s.as().VIPAdd(v)
+}
+
+// VIPRemove will remove a VIP. Requires RoleSenior.
+func (s *Scoped) VIPRemove(v VIP) error {
+	err := s.allow(RoleSenior, "remove VIPs")
+	if err != nil {
+		return err
+	}
+
+	return s.as().VIPRemove(v)
+}
+
+// SetMap will change the current map. Requires RoleSenior.
+func (s *Scoped) SetMap(n MapName) error {
+	err := s.allow(RoleSenior, "change the map")
+	if err != nil {
+		return err
+	}
+
+	return s.as().SetMap(n)
+}
+
+// RotationAdd will add a map to the rotation. Requires RoleSenior.
+func (s *Scoped) RotationAdd(n MapName) error {
+	err := s.allow(RoleSenior, "change the rotation")
+	if err != nil {
+		return err
+	}
+
+	return s.as().RotationAdd(n)
+}
+
+// RotationRemove will remove a map from the rotation. Requires RoleSenior.
+func (s *Scoped) RotationRemove(n MapName) error {
+	err := s.allow(RoleSenior, "change the rotation")
+	if err != nil {
+		return err
+	}
+
+	return s.as().RotationRemove(n)
+}
+
+// SetProfanities will censor words. Requires RoleSenior.
+func (s *Scoped) SetProfanities(words ...string) error {
+	err := s.allow(RoleSenior, "change profanities")
+	if err != nil {
+		return err
+	}
+
+	return s.as().SetProfanities(words...)
+}
+
+// UnsetProfanities will stop censoring words. Requires RoleSenior.
+func (s *Scoped) UnsetProfanities(words ...string) error {
+	err := s.allow(RoleSenior, "change profanities")
+	if err != nil {
+		return err
+	}
+
+	return s.as().UnsetProfanities(words...)
+}
+
+// SyncProfanities will censor exactly the given words. Requires RoleSenior.
+func (s *Scoped) SyncProfanities(words []string) (ProfanityChanges, error) {
+	err := s.allow(RoleSenior, "change profanities")
+	if err != nil {
+		return ProfanityChanges{}, err
+	}
+
+	return s.as().SyncProfanities(words)
+}
+
+// AdminAdd will add an admin. Requires RoleOwner.
+func (s *Scoped) AdminAdd(a Admin) error {
+	err := s.allow(RoleOwner, "add admins")
+	if err != nil {
+		return err
+	}
+
+	return s.as().AdminAdd(a)
+}
+
+// AdminRemove will remove an admin. Requires RoleOwner.
+func (s *Scoped) AdminRemove(a Admin) error {
+	err := s.allow(RoleOwner, "remove admins")
+	if err != nil {
+		return err
+	}
+
+	return s.as().AdminRemove(a)
+}
+
+// SetSettings will update every non-nil setting. Requires RoleOwner.
+func (s *Scoped) SetSettings(settings Settings) error {
+	err := s.allow(RoleOwner, "change settings")
+	if err != nil {
+		return err
+	}
+
+	return s.as().SetSettings(settings)
+}
+
+// ResetVoteKickThreshold will reset the vote kick threshold to the server default. Requires RoleOwner.
+func (s *Scoped) ResetVoteKickThreshold() error {
+	err := s.allow(RoleOwner, "change settings")
+	if err != nil {
+		return err
+	}
+
+	return s.as().ResetVoteKickThreshold()
+}
+
+// Send will send a raw command. Requires RoleOwner, as raw commands bypass every other check.
+func (s *Scoped) Send(cmds ...string) (string, error) {
+	err := s.allow(RoleOwner, "send raw commands")
+	if err != nil {
+		return "", err
+	}
+
+	return s.as().Send(cmds...)
+}
@@ -0,0 +1,131 @@
+package rcon_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+)
+
+var bob = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+
+func TestScopedRoles(t *testing.T) {
+	calls := []struct {
+		name string
+		role rcon.Role // The least Role permitting the call.
+		call func(s *rcon.Scoped) error
+	}{
+		{name: "kick", role: rcon.RoleModerator, call: func(s *rcon.Scoped) error { return s.Kick(bob, "teamkilling") }},
+		{name: "punish", role: rcon.RoleModerator, call: func(s *rcon.Scoped) error { return s.Punish(bob, "teamkilling") }},
+		{name: "message", role: rcon.RoleModerator, call: func(s *rcon.Scoped) error { return s.Message(bob, "hello") }},
+		{name: "switch", role: rcon.RoleModerator, call: func(s *rcon.Scoped) error { return s.SetSwitchTeamNow(bob) }},
+		{name: "broadcast", role: rcon.RoleModerator, call: func(s *rcon.Scoped) error { return s.SetBroadcast("hello") }},
+		{name: "temporary ban", role: rcon.RoleModerator, call: func(s *rcon.Scoped) error { return s.BanTemporarily(bob, 2, "teamkilling") }},
+		{name: "permanent ban", role: rcon.RoleSenior, call: func(s *rcon.Scoped) error { return s.BanPermanently(bob, "cheating") }},
+		{name: "vip", role: rcon.RoleSenior, call: func(s *rcon.Scoped) error { return s.VIPAdd(rcon.VIP{Player: bob}) }},
+		{name: "map", role: rcon.RoleSenior, call: func(s *rcon.Scoped) error { return s.SetMap(rcon.MapFoyWarfare) }},
+		{name: "profanities", role: rcon.RoleSenior, call: func(s *rcon.Scoped) error { return s.SetProfanities("word") }},
+		{name: "admin", role: rcon.RoleOwner, call: func(s *rcon.Scoped) error { return s.AdminAdd(rcon.Admin{Player: bob, Role: "owner"}) }},
+		{name: "raw", role: rcon.RoleOwner, call: func(s *rcon.Scoped) error {
+			_, err := s.Send("setautobalanceenabled", "on")
+			return err
+		}},
+	}
+
+	roles := []rcon.Role{rcon.RoleViewer, rcon.RoleModerator, rcon.RoleSenior, rcon.RoleOwner}
+
+	srv := rcontest.NewServer(t)
+	c := srv.Conn(t)
+
+	for _, call := range calls {
+		for _, role := range roles {
+			t.Run(call.name+" as "+role.String(), func(t *testing.T) {
+				srv.Reset()
+
+				err := call.call(c.Scoped("alice", role))
+
+				if role < call.role {
+					if !errors.Is(err, rcon.ErrForbidden) {
+						t.Errorf("err = %v, want ErrForbidden", err)
+					}
+
+					if sent := srv.Sent(); len(sent) > 0 {
+						t.Errorf("sent %q, want nothing", sent)
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("err = %v, want the call permitted", err)
+				}
+
+				if len(srv.Sent()) == 0 {
+					t.Error("sent nothing, want the command")
+				}
+			})
+		}
+	}
+}
+
+func TestScopedBanHours(t *testing.T) {
+	tests := []struct {
+		role          rcon.Role
+		max           int // MaxBanHours, DefaultMaxBanHours of the role when -1.
+		hours         int
+		wantForbidden bool
+		wantInvalid   bool
+	}{
+		{role: rcon.RoleModerator, max: -1, hours: 0, wantInvalid: true},
+		{role: rcon.RoleModerator, max: -1, hours: -5, wantInvalid: true},
+		{role: rcon.RoleModerator, max: -1, hours: 1},
+		{role: rcon.RoleModerator, max: -1, hours: 24},
+		{role: rcon.RoleModerator, max: -1, hours: 25, wantForbidden: true},
+		{role: rcon.RoleSenior, max: -1, hours: 168},
+		{role: rcon.RoleSenior, max: -1, hours: 169, wantForbidden: true},
+		{role: rcon.RoleOwner, max: -1, hours: 0, wantInvalid: true},
+		{role: rcon.RoleOwner, max: -1, hours: 10000},
+		{role: rcon.RoleModerator, max: 2, hours: 3, wantForbidden: true},
+		{role: rcon.RoleModerator, max: 0, hours: 1000},
+	}
+
+	srv := rcontest.NewServer(t)
+	c := srv.Conn(t)
+
+	for _, tt := range tests {
+		s := c.Scoped("alice", tt.role)
+		if tt.max >= 0 {
+			s.MaxBanHours = tt.max
+		}
+
+		srv.Reset()
+
+		err := s.BanTemporarily(bob, tt.hours, "teamkilling")
+
+		switch {
+		case tt.wantForbidden:
+			if !errors.Is(err, rcon.ErrForbidden) {
+				t.Errorf("%s banning for %d hours = %v, want ErrForbidden", tt.role, tt.hours, err)
+			}
+		case tt.wantInvalid:
+			if err == nil || errors.Is(err, rcon.ErrForbidden) {
+				t.Errorf("%s banning for %d hours = %v, want an invalid duration", tt.role, tt.hours, err)
+			}
+		case err != nil:
+			t.Errorf("%s banning for %d hours = %v, want the ban", tt.role, tt.hours, err)
+		default:
+			sent := srv.Sent()
+			if len(sent) != 1 || !strings.Contains(sent[0], `"alice"`) {
+				t.Errorf("%s banning for %d hours sent %q, want a ban by alice", tt.role, tt.hours, sent)
+			}
+
+			continue
+		}
+
+		if sent := srv.Sent(); len(sent) > 0 {
+			t.Errorf("%s banning for %d hours sent %q, want nothing", tt.role, tt.hours, sent)
+		}
+	}
+}
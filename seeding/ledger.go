@@ -0,0 +1,93 @@
+package seeding
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/jsonfile"
+)
+
+// Record represents the seeding history of a single player.
+type Record struct {
+	Player   rcon.Player   `json:"player"`
+	Pending  time.Duration `json:"pending"` // Seeding time not yet rewarded.
+	Total    time.Duration `json:"total"`   // Seeding time over all sessions.
+	Rewards  int           `json:"rewards"`
+	Rewarded time.Time     `json:"rewarded,omitempty"`
+	LastSeen time.Time     `json:"last_seen"`
+}
+
+// Ledger represents the seeding time of every player keyed by ID64, persisted as JSON.
+type Ledger struct {
+	mu      sync.Mutex
+	path    string
+	Records map[string]*Record `json:"records"`
+}
+
+// OpenLedger will load a Ledger from path, creating an empty one if the file does not exist.
+func OpenLedger(path string) (*Ledger, error) {
+	l := &Ledger{
+		path:    path,
+		Records: map[string]*Record{},
+	}
+
+	err := jsonfile.Load(path, l)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load seeding ledger: %v", err)
+	}
+
+	if l.Records == nil {
+		l.Records = map[string]*Record{}
+	}
+
+	return l, nil
+}
+
+// Save will write the Ledger to disk, replacing the previous file atomically.
+func (l *Ledger) Save() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.save()
+}
+
+func (l *Ledger) save() error {
+	if l.path == "" {
+		return nil
+	}
+
+	err := jsonfile.Save(l.path, l)
+	if err != nil {
+		return fmt.Errorf("failed to save seeding ledger: %v", err)
+	}
+
+	return nil
+}
+
+// Record returns a copy of the seeding record for an ID64.
+func (l *Ledger) Record(id64 string) (Record, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r, ok := l.Records[id64]
+	if !ok {
+		return Record{}, false
+	}
+
+	return *r, true
+}
+
+// List returns a copy of every seeding record.
+func (l *Ledger) List() []Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	list := []Record{}
+	for _, r := range l.Records {
+		list = append(list, *r)
+	}
+
+	return list
+}
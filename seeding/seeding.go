@@ -0,0 +1,225 @@
+// Package seeding rewards players who help populate a server with timed VIP memberships.
+//
+// A Seeder polls the server and credits every player present in two consecutive polls with the
+// time between them, as long as the population is below a threshold. Once a player's credited
+// time reaches the quota, VIP is granted through a vipmanager.Manager and the player is thanked.
+// Players who are already VIP without a membership of the Manager, e.g. permanent VIPs, are not
+// rewarded, and keep their pending time.
+package seeding
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/humanize"
+	"github.com/verocity-gaming/rcon/internal/poll"
+	"github.com/verocity-gaming/rcon/vipmanager"
+)
+
+// DefaultThankMessage is the message sent to players when they are rewarded.
+const DefaultThankMessage = "Thank you for seeding for {seeded}, {name}! You have been granted VIP for {reward}."
+
+// Reward represents a VIP membership granted for seeding.
+type Reward struct {
+	Player     rcon.Player
+	Membership vipmanager.Membership
+	Err        error
+}
+
+// Report represents the outcome of a single Poll.
+type Report struct {
+	Population int
+	Seeding    bool          // Whether the population was below the threshold.
+	Credited   time.Duration // Time credited to each player present since the previous poll.
+	Rewards    []Reward
+}
+
+// Seeder represents a seeding reward engine for a single server.
+type Seeder struct {
+	Conn   *rcon.Conn
+	VIPs   *vipmanager.Manager
+	Ledger *Ledger
+
+	Threshold int           // Population below which the server is seeding.
+	Quota     time.Duration // Seeding time required for a reward.
+	Reward    time.Duration // VIP time granted per reward.
+
+	// MaxGap is the longest time credited between two polls, so outages are not rewarded.
+	MaxGap time.Duration
+
+	// ThankMessage is the template sent to rewarded players, supporting {name}, {seeded} and {reward}.
+	ThankMessage string
+
+	// Operator is the name thank messages are sent in, "seeding" by default. VIP is granted in the
+	// name of the Operator of the Manager.
+	Operator string
+
+	// OnReport is called after every Poll started by Run.
+	OnReport func(Report, error)
+
+	mu       sync.Mutex
+	now      func() time.Time
+	lastPoll time.Time
+	present  map[string]bool
+}
+
+// New returns a Seeder for a Conn, granting VIP through m and recording seeding time in l.
+func New(c *rcon.Conn, m *vipmanager.Manager, l *Ledger) *Seeder {
+	return &Seeder{
+		Conn:         c,
+		VIPs:         m,
+		Ledger:       l,
+		Threshold:    40,
+		Quota:        time.Hour,
+		Reward:       24 * time.Hour,
+		MaxGap:       5 * time.Minute,
+		ThankMessage: DefaultThankMessage,
+		Operator:     "seeding",
+		now:          time.Now,
+		present:      map[string]bool{},
+	}
+}
+
+// Run will poll the server every interval until ctx is done.
+func (s *Seeder) Run(ctx context.Context, interval time.Duration) error {
+	return poll.Every(ctx, interval, func() {
+		r, err := s.Poll()
+		if s.OnReport != nil {
+			s.OnReport(r, err)
+		}
+	})
+}
+
+// Poll will credit seeding time to present players and reward those who reached the quota.
+func (s *Seeder) Poll() (Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := Report{}
+	now := s.now()
+
+	players, err := s.Conn.Players()
+	if err != nil {
+		return r, err
+	}
+
+	r.Population, _, err = s.Conn.Slots()
+	if err != nil {
+		return r, err
+	}
+
+	r.Seeding = r.Population < s.Threshold
+
+	if !s.lastPoll.IsZero() && r.Seeding {
+		r.Credited = now.Sub(s.lastPoll)
+		if r.Credited > s.MaxGap {
+			r.Credited = s.MaxGap
+		}
+	}
+
+	present := map[string]bool{}
+
+	// The VIPs of the server, listed once a player first reaches the quota.
+	var listed map[string]bool
+
+	s.Ledger.mu.Lock()
+	defer s.Ledger.mu.Unlock()
+
+	for _, p := range players {
+		present[p.ID64] = true
+
+		rec, ok := s.Ledger.Records[p.ID64]
+		if !ok {
+			rec = &Record{}
+			s.Ledger.Records[p.ID64] = rec
+		}
+
+		rec.Player = p
+		rec.LastSeen = now
+
+		// Only players seen in the previous poll have been seeding since then.
+		if !s.present[p.ID64] || r.Credited == 0 {
+			continue
+		}
+
+		rec.Pending += r.Credited
+		rec.Total += r.Credited
+
+		if rec.Pending < s.Quota {
+			continue
+		}
+
+		reward := Reward{Player: p}
+
+		if listed == nil {
+			listed, reward.Err = s.listed()
+		}
+
+		if reward.Err == nil && listed[p.ID64] && !s.managed(p) {
+			continue
+		}
+
+		if reward.Err == nil {
+			reward.Membership, reward.Err = s.VIPs.Grant(p, s.Reward, "seeding")
+		}
+
+		if reward.Err == nil {
+			rec.Pending -= s.Quota
+			rec.Rewards++
+			rec.Rewarded = now
+
+			reward.Err = s.Conn.As(s.Operator).Message(p, s.message(p))
+		}
+
+		r.Rewards = append(r.Rewards, reward)
+	}
+
+	s.present = present
+	s.lastPoll = now
+
+	err = s.Ledger.save()
+	if err != nil {
+		return r, err
+	}
+
+	for _, reward := range r.Rewards {
+		if reward.Err != nil {
+			return r, fmt.Errorf("failed to reward %s for seeding: %v", reward.Player, reward.Err)
+		}
+	}
+
+	return r, nil
+}
+
+// listed returns the ID64 of every VIP of the server.
+func (s *Seeder) listed() (map[string]bool, error) {
+	vips, err := s.Conn.VIPs()
+	if err != nil {
+		return nil, err
+	}
+
+	listed := map[string]bool{}
+	for _, v := range vips {
+		listed[v.ID64] = true
+	}
+
+	return listed, nil
+}
+
+// managed reports whether a player has a membership of the Manager, even an expired one.
+func (s *Seeder) managed(p rcon.Player) bool {
+	_, ok := s.VIPs.Store.Membership(p.ID64)
+	return ok
+}
+
+func (s *Seeder) message(p rcon.Player) string {
+	return strings.NewReplacer(
+		"{name}", p.Name,
+		"{seeded}", humanize.Duration(s.Quota),
+		"{reward}", humanize.Duration(s.Reward),
+	).Replace(s.ThankMessage)
+}
@@ -0,0 +1,238 @@
+package seeding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+	"github.com/verocity-gaming/rcon/vipmanager"
+)
+
+var (
+	bob = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+	eve = rcon.Player{Name: "Eve", ID64: "76561198000000002"}
+)
+
+// round represents the server at a Poll, relative to the first.
+type round struct {
+	at         time.Duration
+	players    []rcon.Player
+	population int
+}
+
+func TestPoll(t *testing.T) {
+	start := time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		quota       time.Duration
+		polls       []round
+		vips        []rcon.Player                     // Listed by the server.
+		memberships map[string]*vipmanager.Membership // Of the Manager.
+		wantTotal   time.Duration                     // Of bob.
+		wantPending time.Duration
+		wantRewards int
+		wantSent    []string
+	}{
+		{
+			name: "below threshold",
+			polls: []round{
+				{at: 0, players: []rcon.Player{bob}, population: 10},
+				{at: time.Minute, players: []rcon.Player{bob}, population: 10},
+				{at: 2 * time.Minute, players: []rcon.Player{bob}, population: 10},
+			},
+			wantTotal:   2 * time.Minute,
+			wantPending: 2 * time.Minute,
+			wantSent:    []string{},
+		},
+		{
+			name: "above threshold",
+			polls: []round{
+				{at: 0, players: []rcon.Player{bob}, population: 10},
+				{at: time.Minute, players: []rcon.Player{bob}, population: 40},
+				{at: 2 * time.Minute, players: []rcon.Player{bob}, population: 10},
+			},
+			wantTotal:   time.Minute,
+			wantPending: time.Minute,
+			wantSent:    []string{},
+		},
+		{
+			name: "gap",
+			polls: []round{
+				{at: 0, players: []rcon.Player{bob}, population: 10},
+				{at: 20 * time.Minute, players: []rcon.Player{bob}, population: 10},
+			},
+			wantTotal:   5 * time.Minute,
+			wantPending: 5 * time.Minute,
+			wantSent:    []string{},
+		},
+		{
+			name: "joined",
+			polls: []round{
+				{at: 0, players: []rcon.Player{eve}, population: 10},
+				{at: time.Minute, players: []rcon.Player{eve, bob}, population: 10},
+				{at: 2 * time.Minute, players: []rcon.Player{eve, bob}, population: 10},
+			},
+			wantTotal:   time.Minute,
+			wantPending: time.Minute,
+			wantSent:    []string{},
+		},
+		{
+			name:  "rewarded",
+			quota: 2 * time.Minute,
+			polls: []round{
+				{at: 0, players: []rcon.Player{bob}, population: 10},
+				{at: time.Minute, players: []rcon.Player{bob}, population: 10},
+				{at: 2 * time.Minute, players: []rcon.Player{bob}, population: 10},
+			},
+			wantTotal:   2 * time.Minute,
+			wantPending: 0,
+			wantRewards: 1,
+			wantSent:    []string{"vipadd", "message"},
+		},
+		{
+			name:  "remainder",
+			quota: 90 * time.Second,
+			polls: []round{
+				{at: 0, players: []rcon.Player{bob}, population: 10},
+				{at: time.Minute, players: []rcon.Player{bob}, population: 10},
+				{at: 2 * time.Minute, players: []rcon.Player{bob}, population: 10},
+				{at: 3 * time.Minute, players: []rcon.Player{bob}, population: 10},
+			},
+			wantTotal:   3 * time.Minute,
+			wantPending: 0,
+			wantRewards: 2,
+			wantSent:    []string{"vipadd", "message", "vipadd", "message"},
+		},
+		{
+			name:  "permanent vip",
+			quota: time.Minute,
+			polls: []round{
+				{at: 0, players: []rcon.Player{bob}, population: 10},
+				{at: time.Minute, players: []rcon.Player{bob}, population: 10},
+			},
+			vips:        []rcon.Player{bob},
+			wantTotal:   time.Minute,
+			wantPending: time.Minute,
+			wantSent:    []string{},
+		},
+		{
+			name:  "managed vip",
+			quota: time.Minute,
+			polls: []round{
+				{at: 0, players: []rcon.Player{bob}, population: 10},
+				{at: time.Minute, players: []rcon.Player{bob}, population: 10},
+			},
+			vips: []rcon.Player{bob},
+			memberships: map[string]*vipmanager.Membership{
+				bob.ID64: {Player: bob, Granted: start, Expires: start.Add(time.Hour)},
+			},
+			wantTotal:   time.Minute,
+			wantPending: 0,
+			wantRewards: 1,
+			wantSent:    []string{"vipadd", "message"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := rcontest.NewServer(t)
+
+			var mu sync.Mutex
+			var current round
+
+			srv.Handle("get playerids", func([]string) string {
+				mu.Lock()
+				defer mu.Unlock()
+
+				items := []string{}
+				for _, p := range current.players {
+					items = append(items, p.Name+" : "+p.ID64)
+				}
+
+				return rcontest.List(items...)
+			})
+
+			srv.Handle("get slots", func([]string) string {
+				mu.Lock()
+				defer mu.Unlock()
+
+				return strconv.Itoa(current.population) + "/100"
+			})
+
+			vips := []string{}
+			for _, v := range tt.vips {
+				vips = append(vips, fmt.Sprintf(`%s "%s"`, v.ID64, v.Name))
+			}
+
+			srv.Reply("get vipids", rcontest.List(vips...))
+
+			c := srv.Conn(t)
+
+			memberships := tt.memberships
+			if memberships == nil {
+				memberships = map[string]*vipmanager.Membership{}
+			}
+
+			s := New(c, vipmanager.New(c, &vipmanager.Store{Memberships: memberships}), &Ledger{Records: map[string]*Record{}})
+			if tt.quota > 0 {
+				s.Quota = tt.quota
+			}
+
+			rewards := 0
+
+			for _, p := range tt.polls {
+				mu.Lock()
+				current = p
+				mu.Unlock()
+
+				at := start.Add(p.at)
+				s.now = func() time.Time { return at }
+
+				r, err := s.Poll()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				rewards += len(r.Rewards)
+			}
+
+			rec, _ := s.Ledger.Record(bob.ID64)
+
+			if rec.Total != tt.wantTotal || rec.Pending != tt.wantPending {
+				t.Errorf("total %v pending %v, want %v and %v", rec.Total, rec.Pending, tt.wantTotal, tt.wantPending)
+			}
+
+			if rewards != tt.wantRewards || rec.Rewards != tt.wantRewards {
+				t.Errorf("rewards %d recorded %d, want %d", rewards, rec.Rewards, tt.wantRewards)
+			}
+
+			sent := []string{}
+			for _, cmd := range srv.Sent() {
+				name := rcon.CommandName([]string{cmd})
+				if !strings.HasPrefix(name, "get ") {
+					sent = append(sent, name)
+				}
+			}
+
+			if strings.Join(sent, " ") != strings.Join(tt.wantSent, " ") {
+				t.Errorf("sent %q, want %q", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	s := New(nil, nil, nil)
+	s.Quota = 90 * time.Minute
+
+	got := s.message(bob)
+	if want := "Thank you for seeding for 1 hour 30 minutes, Bob! You have been granted VIP for 1 day."; got != want {
+		t.Errorf("message() = %q, want %q", got, want)
+	}
+}
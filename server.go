@@ -9,15 +9,15 @@ import (
 
 // VoteKickThreshold represents a list item for vote kick threshold updates.
 type VoteKickThreshold struct {
-	Players   int
-	Threshold int
+	Players   int `yaml:"players" json:"players"`
+	Threshold int `yaml:"threshold" json:"threshold"`
 }
 
 // Name returns the name of the server.
 func (c *Conn) Name() (string, error) {
 	result, err := c.send("get", "name")
 	if err != nil {
-		return "", fmt.Errorf("failed to get server name: %v", err)
+		return "", fmt.Errorf("failed to get server name: %w", err)
 	}
 
 	return result, nil
@@ -27,12 +27,12 @@ func (c *Conn) Name() (string, error) {
 func (c *Conn) IdleTime() (time.Duration, error) {
 	result, err := c.send("get", "idletime")
 	if err != nil {
-		return -1, fmt.Errorf("failed to get idle auto-kick time: %v", err)
+		return -1, fmt.Errorf("failed to get idle auto-kick time: %w", err)
 	}
 
 	t, err := strconv.Atoi(result)
 	if err != nil {
-		return -1, fmt.Errorf("failed to parse idle auto-kick time: %v", err)
+		return -1, fmt.Errorf("failed to parse idle auto-kick time: %w", err)
 	}
 
 	return time.Duration(t) * time.Minute, nil
@@ -42,12 +42,12 @@ func (c *Conn) IdleTime() (time.Duration, error) {
 func (c *Conn) MaxPing() (time.Duration, error) {
 	result, err := c.send("get", "highping")
 	if err != nil {
-		return -1, fmt.Errorf("failed to get max ping auto-kick threshold: %v", err)
+		return -1, fmt.Errorf("failed to get max ping auto-kick threshold: %w", err)
 	}
 
 	p, err := strconv.Atoi(result)
 	if err != nil {
-		return -1, fmt.Errorf("failed to parse max ping auto-kick threshold: %v", err)
+		return -1, fmt.Errorf("failed to parse max ping auto-kick threshold: %w", err)
 	}
 
 	return time.Duration(p) * time.Millisecond, nil
@@ -57,7 +57,7 @@ func (c *Conn) MaxPing() (time.Duration, error) {
 func (c *Conn) AutoBalance() (bool, error) {
 	result, err := c.send("get", "autobalanceenabled")
 	if err != nil {
-		return false, fmt.Errorf("failed to set vote kick configuration: %v", err)
+		return false, fmt.Errorf("failed to set vote kick configuration: %w", err)
 	}
 
 	return result == "on", nil
@@ -67,7 +67,7 @@ func (c *Conn) AutoBalance() (bool, error) {
 func (c *Conn) SetAutoBalance(enabled bool) error {
 	_, err := c.send("setautobalanceenabled", map[bool]string{true: "on", false: "off"}[enabled]) // Ternary!
 	if err != nil {
-		return fmt.Errorf("failed to set auto balance configuration: %v", err)
+		return fmt.Errorf("failed to set auto balance configuration: %w", err)
 	}
 
 	return nil
@@ -77,7 +77,7 @@ func (c *Conn) SetAutoBalance(enabled bool) error {
 func (c *Conn) SetAutoBalanceThreshold(diff int) error {
 	_, err := c.send("setautobalancethreshold", strconv.Itoa(diff))
 	if err != nil {
-		return fmt.Errorf("failed to set auto balance threshold configuration: %v", err)
+		return fmt.Errorf("failed to set auto balance threshold configuration: %w", err)
 	}
 
 	return nil
@@ -87,12 +87,12 @@ func (c *Conn) SetAutoBalanceThreshold(diff int) error {
 func (c *Conn) SwitchTeamCooldown() (time.Duration, error) {
 	result, err := c.send("get", "teamswitchcooldown")
 	if err != nil {
-		return -1, fmt.Errorf("failed to set switch team cooldown configuration: %v", err)
+		return -1, fmt.Errorf("failed to set switch team cooldown configuration: %w", err)
 	}
 
 	s, err := strconv.Atoi(result)
 	if err != nil {
-		return -1, fmt.Errorf("failed to parse switch team cooldown configuration: %v", err)
+		return -1, fmt.Errorf("failed to parse switch team cooldown configuration: %w", err)
 	}
 
 	return time.Duration(s) * time.Minute, nil
@@ -102,12 +102,12 @@ func (c *Conn) SwitchTeamCooldown() (time.Duration, error) {
 func (c *Conn) AutoBalanceThreshold() (int, error) {
 	result, err := c.send("get", "autobalancethreshold")
 	if err != nil {
-		return -1, fmt.Errorf("failed to get auto balance threshold configuration: %v", err)
+		return -1, fmt.Errorf("failed to get auto balance threshold configuration: %w", err)
 	}
 
 	t, err := strconv.Atoi(result)
 	if err != nil {
-		return -1, fmt.Errorf("failed to parse auto balance threshold configuration: %v", err)
+		return -1, fmt.Errorf("failed to parse auto balance threshold configuration: %w", err)
 	}
 
 	return t, nil
@@ -117,7 +117,7 @@ func (c *Conn) AutoBalanceThreshold() (int, error) {
 func (c *Conn) SetSwitchTeamCooldown(m time.Duration) error {
 	_, err := c.send("setteamswitchcooldown", strconv.Itoa(int(m.Minutes())))
 	if err != nil {
-		return fmt.Errorf("failed to set switch team cooldown configuration: %v", err)
+		return fmt.Errorf("failed to set switch team cooldown configuration: %w", err)
 	}
 
 	return nil
@@ -127,7 +127,7 @@ func (c *Conn) SetSwitchTeamCooldown(m time.Duration) error {
 func (c *Conn) SetIdleTime(m time.Duration) error {
 	_, err := c.send("setkickidletime", strconv.Itoa(int(m.Minutes())))
 	if err != nil {
-		return fmt.Errorf("failed to set idle auto-kick time: %v", err)
+		return fmt.Errorf("failed to set idle auto-kick time: %w", err)
 	}
 
 	return nil
@@ -137,7 +137,7 @@ func (c *Conn) SetIdleTime(m time.Duration) error {
 func (c *Conn) SetMaxPing(ms time.Duration) error {
 	_, err := c.send("sethighping", strconv.Itoa(int(ms.Milliseconds())))
 	if err != nil {
-		return fmt.Errorf("failed to set max ping auto-kick time: %v", err)
+		return fmt.Errorf("failed to set max ping auto-kick time: %w", err)
 	}
 
 	return nil
@@ -147,7 +147,7 @@ func (c *Conn) SetMaxPing(ms time.Duration) error {
 func (c *Conn) SetQueueLength(length int) error {
 	_, err := c.send("setmaxqueuedplayers", strconv.Itoa(length))
 	if err != nil {
-		return fmt.Errorf("failed to set max queued players: %v", err)
+		return fmt.Errorf("failed to set max queued players: %w", err)
 	}
 
 	return nil
@@ -157,12 +157,12 @@ func (c *Conn) SetQueueLength(length int) error {
 func (c *Conn) QueueLength() (int, error) {
 	result, err := c.send("get", "maxqueuedplayers")
 	if err != nil {
-		return -1, fmt.Errorf("failed to get queue length: %v", err)
+		return -1, fmt.Errorf("failed to get queue length: %w", err)
 	}
 
 	q, err := strconv.Atoi(result)
 	if err != nil {
-		return -1, fmt.Errorf("failed to parse queue length configuration: %v", err)
+		return -1, fmt.Errorf("failed to parse queue length configuration: %w", err)
 	}
 
 	return q, nil
@@ -172,7 +172,7 @@ func (c *Conn) QueueLength() (int, error) {
 func (c *Conn) SetBroadcast(message string) error {
 	_, err := c.send("broadcast", q(message))
 	if err != nil {
-		return fmt.Errorf("failed to set broadcast message: %v", err)
+		return fmt.Errorf("failed to set broadcast message: %w", err)
 	}
 
 	return nil
@@ -182,22 +182,22 @@ func (c *Conn) SetBroadcast(message string) error {
 func (c *Conn) Slots() (numerator, denominator int, err error) {
 	result, err := c.send("get", "slots")
 	if err != nil {
-		return 0, -1, fmt.Errorf("failed to get slots: %v", err)
+		return 0, -1, fmt.Errorf("failed to get slots: %w", err)
 	}
 
 	numDen := strings.Split(result, "/")
 	if len(numDen) != 2 {
-		return 0, -1, fmt.Errorf("failed to parse slots configuration: %v", err)
+		return 0, -1, fmt.Errorf("failed to parse slots configuration: %w", err)
 	}
 
 	numerator, err = strconv.Atoi(numDen[0])
 	if err != nil {
-		return 0, -1, fmt.Errorf("failed to parse numerator for slots configuration: %v", err)
+		return 0, -1, fmt.Errorf("failed to parse numerator for slots configuration: %w", err)
 	}
 
 	denominator, err = strconv.Atoi(numDen[1])
 	if err != nil {
-		return 0, -1, fmt.Errorf("failed to parse denominator for slots configuration: %v", err)
+		return 0, -1, fmt.Errorf("failed to parse denominator for slots configuration: %w", err)
 	}
 
 	return
@@ -207,7 +207,7 @@ func (c *Conn) Slots() (numerator, denominator int, err error) {
 func (c *Conn) VoteKick() (bool, error) {
 	result, err := c.send("get", "votekickenabled")
 	if err != nil {
-		return false, fmt.Errorf("failed to set votekick configuration: %v", err)
+		return false, fmt.Errorf("failed to set votekick configuration: %w", err)
 	}
 
 	return result == "on", nil
@@ -217,7 +217,7 @@ func (c *Conn) VoteKick() (bool, error) {
 func (c *Conn) VoteKickThreshold() (string, error) {
 	result, err := c.send("get", "votekickthreshold")
 	if err != nil {
-		return "", fmt.Errorf("failed to get vote kick threshold: %v", err)
+		return "", fmt.Errorf("failed to get vote kick threshold: %w", err)
 	}
 
 	return result, nil
@@ -227,7 +227,7 @@ func (c *Conn) VoteKickThreshold() (string, error) {
 func (c *Conn) SetVoteKick(enabled bool) error {
 	_, err := c.send("setvotekickenabled", map[bool]string{true: "on", false: "off"}[enabled]) // Ternary!
 	if err != nil {
-		return fmt.Errorf("failed to set votekick configuration: %v", err)
+		return fmt.Errorf("failed to set votekick configuration: %w", err)
 	}
 
 	return nil
@@ -245,7 +245,7 @@ func (c *Conn) SetVoteKickThreshold(pairs ...VoteKickThreshold) error {
 
 	_, err := c.send("setvotekickthreshold", threshold)
 	if err != nil {
-		return fmt.Errorf("failed to set votekick threshold configuration: %v", err)
+		return fmt.Errorf("failed to set votekick threshold configuration: %w", err)
 	}
 
 	return nil
@@ -255,7 +255,7 @@ func (c *Conn) SetVoteKickThreshold(pairs ...VoteKickThreshold) error {
 func (c *Conn) ResetVoteKickThreshold() error {
 	_, err := c.send("resetvotekickthreshold")
 	if err != nil {
-		return fmt.Errorf("failed to reset votekick threshold configuration: %v", err)
+		return fmt.Errorf("failed to reset votekick threshold configuration: %w", err)
 	}
 
 	return nil
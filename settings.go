@@ -0,0 +1,239 @@
+package rcon
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settings represents the configurable gameplay parameters of a server. A nil field is
+// considered unmanaged, and will be left untouched when applied with SetSettings.
+type Settings struct {
+	IdleTime             *time.Duration      `yaml:"idle_time,omitempty" json:"idle_time,omitempty"`
+	MaxPing              *time.Duration      `yaml:"max_ping,omitempty" json:"max_ping,omitempty"`
+	AutoBalance          *bool               `yaml:"auto_balance,omitempty" json:"auto_balance,omitempty"`
+	AutoBalanceThreshold *int                `yaml:"auto_balance_threshold,omitempty" json:"auto_balance_threshold,omitempty"`
+	SwitchTeamCooldown   *time.Duration      `yaml:"switch_team_cooldown,omitempty" json:"switch_team_cooldown,omitempty"`
+	QueueLength          *int                `yaml:"queue_length,omitempty" json:"queue_length,omitempty"`
+	VIPSlots             *int                `yaml:"vip_slots,omitempty" json:"vip_slots,omitempty"`
+	VoteKick             *bool               `yaml:"vote_kick,omitempty" json:"vote_kick,omitempty"`
+	VoteKickThreshold    []VoteKickThreshold `yaml:"vote_kick_threshold,omitempty" json:"vote_kick_threshold,omitempty"`
+}
+
+// Settings will return the current value of every managed server setting.
+func (c *Conn) Settings() (Settings, error) {
+	s := Settings{}
+
+	idle, err := c.IdleTime()
+	if err != nil {
+		return Settings{}, err
+	}
+	s.IdleTime = &idle
+
+	ping, err := c.MaxPing()
+	if err != nil {
+		return Settings{}, err
+	}
+	s.MaxPing = &ping
+
+	balance, err := c.AutoBalance()
+	if err != nil {
+		return Settings{}, err
+	}
+	s.AutoBalance = &balance
+
+	threshold, err := c.AutoBalanceThreshold()
+	if err != nil {
+		return Settings{}, err
+	}
+	s.AutoBalanceThreshold = &threshold
+
+	cooldown, err := c.SwitchTeamCooldown()
+	if err != nil {
+		return Settings{}, err
+	}
+	s.SwitchTeamCooldown = &cooldown
+
+	queue, err := c.QueueLength()
+	if err != nil {
+		return Settings{}, err
+	}
+	s.QueueLength = &queue
+
+	slots, err := c.VIPSlots()
+	if err != nil {
+		return Settings{}, err
+	}
+	s.VIPSlots = &slots
+
+	votekick, err := c.VoteKick()
+	if err != nil {
+		return Settings{}, err
+	}
+	s.VoteKick = &votekick
+
+	result, err := c.VoteKickThreshold()
+	if err != nil {
+		return Settings{}, err
+	}
+
+	s.VoteKickThreshold, err = parseVoteKickThreshold(result)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	return s, nil
+}
+
+// SetSettings will update every non-nil setting in s.
+func (c *Conn) SetSettings(s Settings) error {
+	for _, d := range s.diff(Settings{}) {
+		err := d.apply(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settingDiff represents a single setting that differs between two Settings.
+type settingDiff struct {
+	name  string
+	from  string
+	to    string
+	apply func(c *Conn) error
+}
+
+// diff will return the settings managed by s that differ from current. Settings that are nil in
+// current are always considered different. Durations are compared at the precision the server
+// stores them in, minutes or milliseconds, as their setters truncate them.
+func (s Settings) diff(current Settings) []settingDiff {
+	diffs := []settingDiff{}
+
+	add := func(name string, from, to interface{}, equal bool, apply func(c *Conn) error) {
+		if equal {
+			return
+		}
+
+		diffs = append(diffs, settingDiff{
+			name:  name,
+			from:  fmt.Sprint(from),
+			to:    fmt.Sprint(to),
+			apply: apply,
+		})
+	}
+
+	if s.IdleTime != nil {
+		v := s.IdleTime.Truncate(time.Minute)
+		add("idle_time", deref(current.IdleTime), v, current.IdleTime != nil && *current.IdleTime == v, func(c *Conn) error { return c.SetIdleTime(v) })
+	}
+
+	if s.MaxPing != nil {
+		v := s.MaxPing.Truncate(time.Millisecond)
+		add("max_ping", deref(current.MaxPing), v, current.MaxPing != nil && *current.MaxPing == v, func(c *Conn) error { return c.SetMaxPing(v) })
+	}
+
+	if s.AutoBalance != nil {
+		v := *s.AutoBalance
+		add("auto_balance", deref(current.AutoBalance), v, current.AutoBalance != nil && *current.AutoBalance == v, func(c *Conn) error { return c.SetAutoBalance(v) })
+	}
+
+	if s.AutoBalanceThreshold != nil {
+		v := *s.AutoBalanceThreshold
+		add("auto_balance_threshold", deref(current.AutoBalanceThreshold), v, current.AutoBalanceThreshold != nil && *current.AutoBalanceThreshold == v, func(c *Conn) error { return c.SetAutoBalanceThreshold(v) })
+	}
+
+	if s.SwitchTeamCooldown != nil {
+		v := s.SwitchTeamCooldown.Truncate(time.Minute)
+		add("switch_team_cooldown", deref(current.SwitchTeamCooldown), v, current.SwitchTeamCooldown != nil && *current.SwitchTeamCooldown == v, func(c *Conn) error { return c.SetSwitchTeamCooldown(v) })
+	}
+
+	if s.QueueLength != nil {
+		v := *s.QueueLength
+		add("queue_length", deref(current.QueueLength), v, current.QueueLength != nil && *current.QueueLength == v, func(c *Conn) error { return c.SetQueueLength(v) })
+	}
+
+	if s.VIPSlots != nil {
+		v := *s.VIPSlots
+		add("vip_slots", deref(current.VIPSlots), v, current.VIPSlots != nil && *current.VIPSlots == v, func(c *Conn) error { return c.SetVIPSlots(v) })
+	}
+
+	if s.VoteKick != nil {
+		v := *s.VoteKick
+		add("vote_kick", deref(current.VoteKick), v, current.VoteKick != nil && *current.VoteKick == v, func(c *Conn) error { return c.SetVoteKick(v) })
+	}
+
+	if s.VoteKickThreshold != nil {
+		v := s.VoteKickThreshold
+		add("vote_kick_threshold", current.VoteKickThreshold, v, current.VoteKickThreshold != nil && equalThresholds(current.VoteKickThreshold, v), func(c *Conn) error { return c.SetVoteKickThreshold(v...) })
+	}
+
+	return diffs
+}
+
+// deref will return the value pointed to by p, or "unset" for nil pointers.
+func deref(p interface{}) interface{} {
+	switch v := p.(type) {
+	case *time.Duration:
+		if v != nil {
+			return *v
+		}
+	case *bool:
+		if v != nil {
+			return *v
+		}
+	case *int:
+		if v != nil {
+			return *v
+		}
+	}
+
+	return "unset"
+}
+
+func equalThresholds(a, b []VoteKickThreshold) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseVoteKickThreshold will parse a comma separated list of player/threshold pairs.
+func parseVoteKickThreshold(s string) ([]VoteKickThreshold, error) {
+	pairs := []VoteKickThreshold{}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return pairs, nil
+	}
+
+	args := strings.Split(s, ",")
+	if len(args)%2 != 0 {
+		return nil, fmt.Errorf("failed to parse vote kick threshold: uneven pairs in %q", s)
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		players, err := strconv.Atoi(strings.TrimSpace(args[i]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse vote kick threshold players: %w", err)
+		}
+
+		threshold, err := strconv.Atoi(strings.TrimSpace(args[i+1]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse vote kick threshold: %w", err)
+		}
+
+		pairs = append(pairs, VoteKickThreshold{Players: players, Threshold: threshold})
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,147 @@
+package rcon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSettingsDiff(t *testing.T) {
+	duration := func(d time.Duration) *time.Duration { return &d }
+	boolean := func(b bool) *bool { return &b }
+	integer := func(i int) *int { return &i }
+
+	current := Settings{
+		IdleTime:             duration(10 * time.Minute),
+		MaxPing:              duration(500 * time.Millisecond),
+		AutoBalance:          boolean(true),
+		AutoBalanceThreshold: integer(2),
+		SwitchTeamCooldown:   duration(5 * time.Minute),
+		QueueLength:          integer(6),
+		VIPSlots:             integer(2),
+		VoteKick:             boolean(true),
+		VoteKickThreshold:    []VoteKickThreshold{{Players: 0, Threshold: 1}, {Players: 10, Threshold: 5}},
+	}
+
+	tests := []struct {
+		name    string
+		desired Settings
+		current Settings
+		want    []string // name: from => to
+	}{
+		{
+			name:    "unmanaged",
+			desired: Settings{},
+			current: current,
+			want:    []string{},
+		},
+		{
+			name:    "equal",
+			desired: current,
+			current: current,
+			want:    []string{},
+		},
+		{
+			name: "changed",
+			desired: Settings{
+				IdleTime:          duration(15 * time.Minute),
+				AutoBalance:       boolean(false),
+				QueueLength:       integer(6),
+				VIPSlots:          integer(4),
+				VoteKickThreshold: []VoteKickThreshold{{Players: 0, Threshold: 1}},
+			},
+			current: current,
+			want: []string{
+				"idle_time: 10m0s => 15m0s",
+				"auto_balance: true => false",
+				"vip_slots: 2 => 4",
+				"vote_kick_threshold: [{0 1} {10 5}] => [{0 1}]",
+			},
+		},
+		{
+			name: "below server precision",
+			desired: Settings{
+				IdleTime:           duration(10*time.Minute + 30*time.Second),
+				MaxPing:            duration(500*time.Millisecond + 300*time.Microsecond),
+				SwitchTeamCooldown: duration(5*time.Minute + 59*time.Second),
+			},
+			current: current,
+			want:    []string{},
+		},
+		{
+			name: "truncated",
+			desired: Settings{
+				IdleTime: duration(90 * time.Second),
+			},
+			current: current,
+			want:    []string{"idle_time: 10m0s => 1m0s"},
+		},
+		{
+			name: "unset",
+			desired: Settings{
+				MaxPing:           duration(time.Second),
+				VoteKick:          boolean(false),
+				VoteKickThreshold: []VoteKickThreshold{},
+			},
+			current: Settings{},
+			want: []string{
+				"max_ping: unset => 1s",
+				"vote_kick: unset => false",
+				"vote_kick_threshold: [] => []",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, d := range tt.desired.diff(tt.current) {
+				got = append(got, d.name+": "+d.from+" => "+d.to)
+			}
+
+			if !equalStrings(got, tt.want) {
+				t.Errorf("diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseVoteKickThreshold(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []VoteKickThreshold
+		wantErr bool
+	}{
+		{in: "", want: []VoteKickThreshold{}},
+		{in: "0,1,10,5", want: []VoteKickThreshold{{Players: 0, Threshold: 1}, {Players: 10, Threshold: 5}}},
+		{in: " 0, 1 ,10,5\n", want: []VoteKickThreshold{{Players: 0, Threshold: 1}, {Players: 10, Threshold: 5}}},
+		{in: "0,1,10", wantErr: true},
+		{in: "a,1", wantErr: true},
+		{in: "0,b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseVoteKickThreshold(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseVoteKickThreshold(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+
+		if !tt.wantErr && !equalThresholds(got, tt.want) {
+			t.Errorf("parseVoteKickThreshold(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
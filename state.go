@@ -0,0 +1,394 @@
+package rcon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// State represents the full desired state of a server. A nil field is considered unmanaged and
+// will be left untouched by Reconcile, while an empty list will remove every existing entry. The
+// rotation is the exception, as a server cannot run without maps.
+type State struct {
+	Settings    Settings  `yaml:"settings,omitempty" json:"settings,omitempty"`
+	Rotation    []MapName `yaml:"rotation,omitempty" json:"rotation,omitempty"`
+	Admins      []Admin   `yaml:"admins,omitempty" json:"admins,omitempty"`
+	VIPs        []VIP     `yaml:"vips,omitempty" json:"vips,omitempty"`
+	Profanities []string  `yaml:"profanities,omitempty" json:"profanities,omitempty"`
+	Broadcast   *string   `yaml:"broadcast,omitempty" json:"broadcast,omitempty"`
+}
+
+// Operation kinds used in a Plan.
+const (
+	OperationAdd    = "add"
+	OperationRemove = "remove"
+	OperationUpdate = "update"
+)
+
+// Operation represents a single change required to move a server towards a desired State.
+type Operation struct {
+	Kind     string // One of OperationAdd, OperationRemove or OperationUpdate.
+	Resource string // The kind of server object being changed, e.g. "admin".
+	Target   string // A description of the object being changed.
+
+	apply func(c *Conn) error
+}
+
+// Plan represents an ordered list of Operations computed by Reconcile.
+type Plan []Operation
+
+// LoadState will read a State from a YAML or JSON file.
+func LoadState(path string) (State, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to open state file: %w", err)
+	}
+	defer f.Close()
+
+	return ReadState(f)
+}
+
+// ReadState will decode a State from YAML or JSON. Durations are written as strings, e.g. "5m".
+func ReadState(r io.Reader) (State, error) {
+	s := State{}
+
+	// YAML is a superset of JSON, so one decoder serves both formats.
+	err := yaml.NewDecoder(r).Decode(&s)
+	if err != nil && err != io.EOF {
+		return State{}, fmt.Errorf("failed to decode state: %w", err)
+	}
+
+	return s, nil
+}
+
+// Reconcile will compare the current state of a server with the desired State and return the
+// Plan of operations required to converge them. Unless dryRun is set, the Plan is applied
+// in order, stopping at the first failed Operation.
+func Reconcile(c *Conn, desired State, dryRun bool) (Plan, error) {
+	p, err := plan(c, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return p, nil
+	}
+
+	return p, p.Apply(c)
+}
+
+// Apply will execute every Operation in a Plan against a Conn.
+func (p Plan) Apply(c *Conn) error {
+	for _, op := range p {
+		err := op.apply(c)
+		if err != nil {
+			return fmt.Errorf("failed to %s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// Print will write a human readable list of Operations, one per line.
+func (p Plan) Print(w io.Writer) error {
+	if len(p) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+
+	for _, op := range p {
+		_, err := fmt.Fprintln(w, op.line())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p Plan) String() string {
+	b := &strings.Builder{}
+	p.Print(b)
+	return b.String()
+}
+
+func (o Operation) String() string {
+	return fmt.Sprintf("%s %s %s", o.Kind, o.Resource, o.Target)
+}
+
+func (o Operation) line() string {
+	symbol := map[string]string{
+		OperationAdd:    "+",
+		OperationRemove: "-",
+		OperationUpdate: "~",
+	}[o.Kind]
+
+	return fmt.Sprintf("%s %s %s", symbol, o.Resource, o.Target)
+}
+
+func plan(c *Conn, desired State) (Plan, error) {
+	p := Plan{}
+
+	if desired.Rotation != nil && len(desired.Rotation) == 0 {
+		return nil, errors.New("invalid state: the rotation must hold at least one map")
+	}
+
+	for _, w := range desired.Profanities {
+		err := ValidateProfanity(w)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state: %w", err)
+		}
+	}
+
+	// Reading the settings takes a command per setting, so they are only read when managed.
+	if len(desired.Settings.diff(Settings{})) > 0 {
+		current, err := c.Settings()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range desired.Settings.diff(current) {
+			p = append(p, Operation{
+				Kind:     OperationUpdate,
+				Resource: "setting",
+				Target:   fmt.Sprintf("%s: %s => %s", d.name, d.from, d.to),
+				apply:    d.apply,
+			})
+		}
+	}
+
+	if desired.Rotation != nil {
+		rotation, err := c.Rotation()
+		if err != nil {
+			return nil, err
+		}
+
+		p = append(p, planRotation(rotation, desired.Rotation)...)
+	}
+
+	if desired.Admins != nil {
+		admins, err := c.Admins()
+		if err != nil {
+			return nil, err
+		}
+
+		p = append(p, planAdmins(admins, desired.Admins)...)
+	}
+
+	if desired.VIPs != nil {
+		vips, err := c.VIPs()
+		if err != nil {
+			return nil, err
+		}
+
+		p = append(p, planVIPs(vips, desired.VIPs)...)
+	}
+
+	if desired.Profanities != nil {
+		words, err := c.Profanities()
+		if err != nil {
+			return nil, err
+		}
+
+		p = append(p, planProfanities(words, desired.Profanities)...)
+	}
+
+	// The server does not expose the current broadcast message, so it is always applied.
+	if desired.Broadcast != nil {
+		message := *desired.Broadcast
+
+		p = append(p, Operation{
+			Kind:     OperationUpdate,
+			Resource: "broadcast",
+			Target:   q(message),
+			apply:    func(c *Conn) error { return c.SetBroadcast(message) },
+		})
+	}
+
+	return p, nil
+}
+
+// planRotation will add missing maps before removing unwanted ones, so the rotation is never empty.
+// The desired rotation must not be empty.
+func planRotation(current []Map, desired []MapName) Plan {
+	p := Plan{}
+
+	have := map[MapName]bool{}
+	for _, m := range current {
+		have[m.MapName] = true
+	}
+
+	want := map[MapName]bool{}
+	for _, n := range desired {
+		want[n] = true
+
+		if have[n] {
+			continue
+		}
+
+		n := n
+		p = append(p, Operation{
+			Kind:     OperationAdd,
+			Resource: "rotation",
+			Target:   n.String(),
+			apply:    func(c *Conn) error { return c.RotationAdd(n) },
+		})
+	}
+
+	for _, m := range current {
+		if want[m.MapName] {
+			continue
+		}
+
+		n := m.MapName
+		p = append(p, Operation{
+			Kind:     OperationRemove,
+			Resource: "rotation",
+			Target:   n.String(),
+			apply:    func(c *Conn) error { return c.RotationRemove(n) },
+		})
+	}
+
+	return p
+}
+
+func planAdmins(current, desired []Admin) Plan {
+	p := Plan{}
+
+	have := map[string]Admin{}
+	for _, a := range current {
+		have[a.ID64] = a
+	}
+
+	want := map[string]bool{}
+	for _, a := range desired {
+		want[a.ID64] = true
+
+		a := a
+		existing, ok := have[a.ID64]
+
+		switch {
+		case !ok:
+			p = append(p, Operation{
+				Kind:     OperationAdd,
+				Resource: "admin",
+				Target:   a.String(),
+				apply:    func(c *Conn) error { return c.AdminAdd(a) },
+			})
+		case existing.Role != a.Role:
+			p = append(p, Operation{
+				Kind:     OperationUpdate,
+				Resource: "admin",
+				Target:   fmt.Sprintf("%s: %s => %s", a.Player, existing.Role, a.Role),
+				apply: func(c *Conn) error {
+					err := c.AdminRemove(existing)
+					if err != nil {
+						return err
+					}
+
+					return c.AdminAdd(a)
+				},
+			})
+		}
+	}
+
+	for _, a := range current {
+		if want[a.ID64] {
+			continue
+		}
+
+		a := a
+		p = append(p, Operation{
+			Kind:     OperationRemove,
+			Resource: "admin",
+			Target:   a.String(),
+			apply:    func(c *Conn) error { return c.AdminRemove(a) },
+		})
+	}
+
+	return p
+}
+
+func planVIPs(current, desired []VIP) Plan {
+	p := Plan{}
+
+	have := map[string]bool{}
+	for _, v := range current {
+		have[v.ID64] = true
+	}
+
+	want := map[string]bool{}
+	for _, v := range desired {
+		want[v.ID64] = true
+
+		if have[v.ID64] {
+			continue
+		}
+
+		v := v
+		p = append(p, Operation{
+			Kind:     OperationAdd,
+			Resource: "vip",
+			Target:   v.String(),
+			apply:    func(c *Conn) error { return c.VIPAdd(v) },
+		})
+	}
+
+	for _, v := range current {
+		if want[v.ID64] {
+			continue
+		}
+
+		v := v
+		p = append(p, Operation{
+			Kind:     OperationRemove,
+			Resource: "vip",
+			Target:   v.String(),
+			apply:    func(c *Conn) error { return c.VIPRemove(v) },
+		})
+	}
+
+	return p
+}
+
+// planProfanities will add and remove words in an Operation each, sent in as few commands as their
+// length allows.
+func planProfanities(current, desired []string) Plan {
+	p := Plan{}
+
+	add, remove := diffProfanities(current, desired)
+
+	if len(add) > 0 {
+		p = append(p, Operation{
+			Kind:     OperationAdd,
+			Resource: "profanity",
+			Target:   quoteAll(add),
+			apply:    func(c *Conn) error { return c.SetProfanities(add...) },
+		})
+	}
+
+	if len(remove) > 0 {
+		p = append(p, Operation{
+			Kind:     OperationRemove,
+			Resource: "profanity",
+			Target:   quoteAll(remove),
+			apply:    func(c *Conn) error { return c.UnsetProfanities(remove...) },
+		})
+	}
+
+	return p
+}
+
+// quoteAll will quote words and join them with commas.
+func quoteAll(words []string) string {
+	quoted := []string{}
+	for _, w := range words {
+		quoted = append(quoted, q(w))
+	}
+
+	return strings.Join(quoted, ", ")
+}
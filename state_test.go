@@ -0,0 +1,92 @@
+package rcon
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanRotation(t *testing.T) {
+	rotation := func(names ...MapName) []Map {
+		maps := []Map{}
+		for _, n := range names {
+			maps = append(maps, mapFromName(n))
+		}
+
+		return maps
+	}
+
+	tests := []struct {
+		name    string
+		current []Map
+		desired []MapName
+		want    []string
+	}{
+		{
+			name:    "equal",
+			current: rotation(MapFoyWarfare, MapKurskWarfare),
+			desired: []MapName{MapFoyWarfare, MapKurskWarfare},
+			want:    []string{},
+		},
+		{
+			name:    "empty",
+			current: rotation(),
+			desired: []MapName{MapFoyWarfare},
+			want:    []string{"add rotation foy_warfare"},
+		},
+		{
+			name:    "replaced",
+			current: rotation(MapFoyWarfare, MapKurskWarfare),
+			desired: []MapName{MapStMereEgliseWarfare},
+			// Maps are added first, so the rotation is never empty.
+			want: []string{
+				"add rotation stmereeglise_warfare",
+				"remove rotation foy_warfare",
+				"remove rotation kursk_warfare",
+			},
+		},
+		{
+			name:    "duplicates",
+			current: rotation(MapFoyWarfare, MapFoyWarfare, MapKurskWarfare),
+			desired: []MapName{MapKurskWarfare},
+			want: []string{
+				"remove rotation foy_warfare",
+				"remove rotation foy_warfare",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, o := range planRotation(tt.current, tt.desired) {
+				got = append(got, o.String())
+			}
+
+			if !equalStrings(got, tt.want) {
+				t.Errorf("planRotation() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadState(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr bool
+	}{
+		{name: "empty", in: ""},
+		{name: "yaml", in: "rotation: [foy_warfare]\nsettings:\n  idle_time: 10m\n"},
+		{name: "json", in: `{"rotation": ["foy_warfare"], "profanities": ["bad"]}`},
+		{name: "invalid", in: "rotation: {", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadState(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -8,14 +8,14 @@ import (
 
 // VIP represents a Player with some elevated privileges.
 type VIP struct {
-	Player
+	Player `yaml:",inline"`
 }
 
 // SetVIPSlots will update the current number of joinable VIPs.
 func (c *Conn) SetVIPSlots(slots int) error {
 	_, err := c.send("setnumvipslots", strconv.Itoa(slots))
 	if err != nil {
-		return fmt.Errorf("failed to set vip slots: %v", err)
+		return fmt.Errorf("failed to set vip slots: %w", err)
 	}
 
 	return nil
@@ -27,7 +27,7 @@ var x = make(chan bool)
 func (c *Conn) VIPs() ([]VIP, error) {
 	result, err := c.send("get", "vipids")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get information for all vips: %v", err)
+		return nil, fmt.Errorf("failed to get information for all vips: %w", err)
 	}
 
 	args := strings.Split(result, "\t")
@@ -55,7 +55,7 @@ func (c *Conn) VIPs() ([]VIP, error) {
 func (c *Conn) VIPAdd(v VIP) error {
 	_, err := c.send("vipadd", q(v.ID64), q(v.Name))
 	if err != nil {
-		return fmt.Errorf("failed to add vip %s: %v", v.String(), err)
+		return fmt.Errorf("failed to add vip %s: %w", v.String(), err)
 	}
 
 	return nil
@@ -65,7 +65,7 @@ func (c *Conn) VIPAdd(v VIP) error {
 func (c *Conn) VIPRemove(v VIP) error {
 	_, err := c.send("vipdel", v.ID64)
 	if err != nil {
-		return fmt.Errorf("failed to remove vip %s: %v", v.String(), err)
+		return fmt.Errorf("failed to remove vip %s: %w", v.String(), err)
 	}
 
 	return nil
@@ -75,12 +75,12 @@ func (c *Conn) VIPRemove(v VIP) error {
 func (c *Conn) VIPSlots() (int, error) {
 	result, err := c.send("get", "numvipslots")
 	if err != nil {
-		return -1, fmt.Errorf("failed to get vip slots: %v", err)
+		return -1, fmt.Errorf("failed to get vip slots: %w", err)
 	}
 
 	s, err := strconv.Atoi(result)
 	if err != nil {
-		return -1, fmt.Errorf("failed to parse information for vip slots: %v", err)
+		return -1, fmt.Errorf("failed to parse information for vip slots: %w", err)
 	}
 
 	return s, nil
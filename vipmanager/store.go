@@ -0,0 +1,120 @@
+package vipmanager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/humanize"
+	"github.com/verocity-gaming/rcon/internal/jsonfile"
+)
+
+// expiresLayout is the layout of expiry times shown to players.
+const expiresLayout = "Jan 2 15:04 MST"
+
+// Membership represents a time-limited VIP membership.
+type Membership struct {
+	Player   rcon.Player `json:"player"`
+	Granted  time.Time   `json:"granted"`
+	Expires  time.Time   `json:"expires"`
+	Notified bool        `json:"notified"` // Whether the player was warned of the upcoming expiry.
+	Reason   string      `json:"reason,omitempty"`
+
+	// Preexisting is set when the player was already a VIP on the server when granted, e.g. as a
+	// permanent VIP. The VIP is then left in place when the membership expires or is revoked.
+	Preexisting bool `json:"preexisting,omitempty"`
+}
+
+// Store represents the local record of every managed membership keyed by ID64, persisted as JSON.
+type Store struct {
+	mu          sync.Mutex
+	path        string
+	Memberships map[string]*Membership `json:"memberships"`
+}
+
+// OpenStore will load a Store from path, creating an empty one if the file does not exist.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{
+		path:        path,
+		Memberships: map[string]*Membership{},
+	}
+
+	err := jsonfile.Load(path, s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load vip store: %v", err)
+	}
+
+	if s.Memberships == nil {
+		s.Memberships = map[string]*Membership{}
+	}
+
+	return s, nil
+}
+
+// Save will write the Store to disk, replacing the previous file atomically.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	err := jsonfile.Save(s.path, s)
+	if err != nil {
+		return fmt.Errorf("failed to save vip store: %v", err)
+	}
+
+	return nil
+}
+
+// Membership returns a copy of the membership for an ID64.
+func (s *Store) Membership(id64 string) (Membership, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.Memberships[id64]
+	if !ok {
+		return Membership{}, false
+	}
+
+	return *m, true
+}
+
+// List returns a copy of every membership.
+func (s *Store) List() []Membership {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []Membership{}
+	for _, m := range s.Memberships {
+		list = append(list, *m)
+	}
+
+	return list
+}
+
+// Expired reports whether the membership has run its course.
+func (m Membership) Expired(now time.Time) bool {
+	return !now.Before(m.Expires)
+}
+
+// Remaining returns the time left on the membership.
+func (m Membership) Remaining(now time.Time) time.Duration {
+	return m.Expires.Sub(now)
+}
+
+// Describe returns a sentence telling the player when the membership expires, e.g. "Your VIP
+// membership expires in 2 days 5 hours, on Oct 21 20:00 UTC."
+func (m Membership) Describe(now time.Time) string {
+	if m.Expired(now) {
+		return "Your VIP membership has expired, and will be removed shortly."
+	}
+
+	return fmt.Sprintf("Your VIP membership expires in %s, on %s.", humanize.Duration(m.Remaining(now)), m.Expires.Format(expiresLayout))
+}
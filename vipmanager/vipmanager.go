@@ -0,0 +1,274 @@
+// Package vipmanager grants time-limited VIP memberships and removes them once they expire.
+//
+// Memberships are kept in a local Store, since the server has no notion of VIP expiry. VIPs added
+// to the server by other means are left alone, even once granted a membership that then expires,
+// while managed VIPs removed from the server by other means are dropped from the Store, unless
+// Restore is set.
+package vipmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/humanize"
+	"github.com/verocity-gaming/rcon/internal/poll"
+)
+
+// Event kinds used in a Report.
+const (
+	EventExpired  = "expired"  // An expired membership was removed from the server.
+	EventNotified = "notified" // A player was warned of the upcoming expiry.
+	EventRemoved  = "removed"  // A managed VIP was removed from the server out-of-band.
+	EventRestored = "restored" // A managed VIP removed out-of-band was added back.
+	EventUnknown  = "unknown"  // A VIP on the server is not managed by the Store.
+)
+
+// DefaultNotifyMessage is the message sent to players before their membership expires.
+const DefaultNotifyMessage = "Your VIP membership expires in {remaining}, on {expires}."
+
+// Event represents a single change observed or made by a Manager.
+type Event struct {
+	Kind       string
+	Membership Membership
+	Err        error
+}
+
+// Report represents the outcome of a single Check.
+type Report struct {
+	Events []Event
+}
+
+// Manager represents a VIP membership manager for a single server.
+type Manager struct {
+	Conn  *rcon.Conn
+	Store *Store
+
+	// NotifyBefore is the time before expiry at which online players are messaged, or never when zero.
+	NotifyBefore time.Duration
+
+	// NotifyMessage is the template sent to players, supporting {name}, {remaining} and {expires}.
+	NotifyMessage string
+
+	// Restore will add back managed VIPs that were removed from the server out-of-band.
+	Restore bool
+
+	// Operator is the name VIPs are added and removed in, and expiry warnings sent in, "vipmanager"
+	// by default.
+	Operator string
+
+	// OnReport is called after every Check started by Run.
+	OnReport func(Report, error)
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// New returns a Manager for a Conn, recording memberships in s.
+func New(c *rcon.Conn, s *Store) *Manager {
+	return &Manager{
+		Conn:          c,
+		Store:         s,
+		NotifyBefore:  72 * time.Hour,
+		NotifyMessage: DefaultNotifyMessage,
+		Operator:      "vipmanager",
+		now:           time.Now,
+	}
+}
+
+// Grant will add a player as a VIP for d, extending any active membership. A player already listed
+// as a VIP without a membership is granted a Preexisting one, leaving their VIP untouched.
+func (m *Manager) Grant(p rcon.Player, d time.Duration, reason string) (Membership, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+
+	m.Store.mu.Lock()
+	defer m.Store.mu.Unlock()
+
+	ms, ok := m.Store.Memberships[p.ID64]
+	if !ok || ms.Expired(now) {
+		preexisting := ok && ms.Preexisting
+
+		if !ok {
+			vips, err := m.Conn.VIPs()
+			if err != nil {
+				return Membership{}, err
+			}
+
+			for _, v := range vips {
+				if v.ID64 == p.ID64 {
+					preexisting = true
+				}
+			}
+		}
+
+		ms = &Membership{
+			Player:      p,
+			Granted:     now,
+			Expires:     now,
+			Preexisting: preexisting,
+		}
+	}
+
+	if !ms.Preexisting {
+		err := m.Conn.As(m.Operator).VIPAdd(rcon.VIP{Player: p})
+		if err != nil {
+			return Membership{}, err
+		}
+	}
+
+	ms.Expires = ms.Expires.Add(d)
+	ms.Notified = false
+	ms.Reason = reason
+
+	m.Store.Memberships[p.ID64] = ms
+
+	return *ms, m.Store.save()
+}
+
+// Revoke will remove a player's membership and VIP status immediately. The VIP of a Preexisting
+// membership is left in place.
+func (m *Manager) Revoke(p rcon.Player) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Store.mu.Lock()
+	defer m.Store.mu.Unlock()
+
+	if ms, ok := m.Store.Memberships[p.ID64]; !ok || !ms.Preexisting {
+		err := m.Conn.As(m.Operator).VIPRemove(rcon.VIP{Player: p})
+		if err != nil {
+			return err
+		}
+	}
+
+	delete(m.Store.Memberships, p.ID64)
+
+	return m.Store.save()
+}
+
+// Run will check memberships every interval until ctx is done.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) error {
+	return poll.Every(ctx, interval, func() {
+		r, err := m.Check()
+		if m.OnReport != nil {
+			m.OnReport(r, err)
+		}
+	})
+}
+
+// Check will remove expired memberships, warn players of upcoming expiry and reconcile the Store
+// against the VIPs of the server.
+func (m *Manager) Check() (Report, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := Report{}
+	now := m.now()
+
+	vips, err := m.Conn.VIPs()
+	if err != nil {
+		return r, err
+	}
+
+	players, err := m.Conn.Players()
+	if err != nil {
+		return r, err
+	}
+
+	listed := map[string]bool{}
+	for _, v := range vips {
+		listed[v.ID64] = true
+	}
+
+	online := map[string]bool{}
+	for _, p := range players {
+		online[p.ID64] = true
+	}
+
+	m.Store.mu.Lock()
+	defer m.Store.mu.Unlock()
+
+	c := m.Conn.As(m.Operator)
+
+	managed := map[string]bool{}
+
+	for id, ms := range m.Store.Memberships {
+		managed[id] = true
+
+		switch {
+		case ms.Expired(now):
+			e := Event{Kind: EventExpired, Membership: *ms}
+
+			if listed[id] && !ms.Preexisting {
+				e.Err = c.VIPRemove(rcon.VIP{Player: ms.Player})
+			}
+
+			if e.Err == nil {
+				delete(m.Store.Memberships, id)
+			}
+
+			r.Events = append(r.Events, e)
+		case !listed[id] && m.Restore:
+			e := Event{Kind: EventRestored, Membership: *ms}
+			e.Err = c.VIPAdd(rcon.VIP{Player: ms.Player})
+
+			// The VIP added back belongs to the membership, and expires with it.
+			if e.Err == nil {
+				ms.Preexisting = false
+			}
+
+			r.Events = append(r.Events, e)
+		case !listed[id]:
+			delete(m.Store.Memberships, id)
+
+			r.Events = append(r.Events, Event{Kind: EventRemoved, Membership: *ms})
+		case m.NotifyBefore > 0 && !ms.Notified && online[id] && ms.Remaining(now) <= m.NotifyBefore:
+			e := Event{Kind: EventNotified, Membership: *ms}
+			e.Err = c.Message(ms.Player, m.message(*ms, now))
+
+			if e.Err == nil {
+				ms.Notified = true
+			}
+
+			r.Events = append(r.Events, e)
+		}
+	}
+
+	for _, v := range vips {
+		if managed[v.ID64] {
+			continue
+		}
+
+		r.Events = append(r.Events, Event{
+			Kind:       EventUnknown,
+			Membership: Membership{Player: v.Player},
+		})
+	}
+
+	err = m.Store.save()
+	if err != nil {
+		return r, err
+	}
+
+	for _, e := range r.Events {
+		if e.Err != nil {
+			return r, fmt.Errorf("failed to handle %s vip %s: %v", e.Kind, e.Membership.Player, e.Err)
+		}
+	}
+
+	return r, nil
+}
+
+func (m *Manager) message(ms Membership, now time.Time) string {
+	return strings.NewReplacer(
+		"{name}", ms.Player.Name,
+		"{remaining}", humanize.Duration(ms.Remaining(now)),
+		"{expires}", ms.Expires.Format(expiresLayout),
+	).Replace(m.NotifyMessage)
+}
@@ -0,0 +1,411 @@
+package vipmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/rcontest"
+)
+
+var now = time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+var bob = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+
+// server represents the VIPs and players of a fake server.
+type server struct {
+	*rcontest.Server
+
+	mu      sync.Mutex
+	vips    map[string]string // Names by ID64.
+	players map[string]string
+}
+
+func newServer(t *testing.T, vips, players map[string]string) (*server, *rcon.Conn) {
+	s := &server{Server: rcontest.NewServer(t), vips: vips, players: players}
+
+	s.Handle("get vipids", func([]string) string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		items := []string{}
+		for id, name := range s.vips {
+			items = append(items, fmt.Sprintf(`%s "%s"`, id, name))
+		}
+
+		sort.Strings(items)
+
+		return rcontest.List(items...)
+	})
+
+	s.Handle("get playerids", func([]string) string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		items := []string{}
+		for id, name := range s.players {
+			items = append(items, name+" : "+id)
+		}
+
+		sort.Strings(items)
+
+		return rcontest.List(items...)
+	})
+
+	s.Handle("vipadd", func(args []string) string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.vips[args[0]] = args[1]
+
+		return "SUCCESS"
+	})
+
+	s.Handle("vipdel", func(args []string) string {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.vips, args[0])
+
+		return "SUCCESS"
+	})
+
+	c := s.Conn(t)
+	s.Reset()
+
+	return s, c
+}
+
+// mutations returns the names of the commands sent other than "get" commands.
+func (s *server) mutations() []string {
+	names := []string{}
+
+	for _, cmd := range s.Sent() {
+		name := rcon.CommandName([]string{cmd})
+		if !strings.HasPrefix(name, "get ") {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func (s *server) listed(id64 string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.vips[id64]
+
+	return ok
+}
+
+func newManager(c *rcon.Conn, memberships map[string]*Membership) *Manager {
+	m := New(c, &Store{Memberships: memberships})
+	m.now = func() time.Time { return now }
+
+	return m
+}
+
+func TestGrant(t *testing.T) {
+	tests := []struct {
+		name        string
+		memberships map[string]*Membership
+		vips        map[string]string
+		want        Membership
+		wantSent    []string
+	}{
+		{
+			name:        "new",
+			memberships: map[string]*Membership{},
+			vips:        map[string]string{},
+			want:        Membership{Player: bob, Granted: now, Expires: now.Add(2 * time.Hour), Reason: "seeding"},
+			wantSent:    []string{"vipadd"},
+		},
+		{
+			name: "extended",
+			memberships: map[string]*Membership{bob.ID64: {
+				Player: bob, Granted: now.Add(-time.Hour), Expires: now.Add(time.Hour), Notified: true, Reason: "donation",
+			}},
+			vips:     map[string]string{bob.ID64: bob.Name},
+			want:     Membership{Player: bob, Granted: now.Add(-time.Hour), Expires: now.Add(3 * time.Hour), Reason: "seeding"},
+			wantSent: []string{"vipadd"},
+		},
+		{
+			name: "expired",
+			memberships: map[string]*Membership{bob.ID64: {
+				Player: bob, Granted: now.Add(-2 * time.Hour), Expires: now.Add(-time.Hour), Reason: "donation",
+			}},
+			vips:     map[string]string{bob.ID64: bob.Name},
+			want:     Membership{Player: bob, Granted: now, Expires: now.Add(2 * time.Hour), Reason: "seeding"},
+			wantSent: []string{"vipadd"},
+		},
+		{
+			name:        "preexisting",
+			memberships: map[string]*Membership{},
+			vips:        map[string]string{bob.ID64: bob.Name},
+			want:        Membership{Player: bob, Granted: now, Expires: now.Add(2 * time.Hour), Reason: "seeding", Preexisting: true},
+			wantSent:    []string{},
+		},
+		{
+			name: "preexisting extended",
+			memberships: map[string]*Membership{bob.ID64: {
+				Player: bob, Granted: now.Add(-time.Hour), Expires: now.Add(time.Hour), Preexisting: true,
+			}},
+			vips:     map[string]string{bob.ID64: bob.Name},
+			want:     Membership{Player: bob, Granted: now.Add(-time.Hour), Expires: now.Add(3 * time.Hour), Reason: "seeding", Preexisting: true},
+			wantSent: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := newServer(t, tt.vips, map[string]string{})
+			m := newManager(c, tt.memberships)
+
+			got, err := m.Grant(bob, 2*time.Hour, "seeding")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Grant() = %+v, want %+v", got, tt.want)
+			}
+
+			if stored, _ := m.Store.Membership(bob.ID64); stored != tt.want {
+				t.Errorf("stored membership = %+v, want %+v", stored, tt.want)
+			}
+
+			if sent := s.mutations(); !equal(sent, tt.wantSent) {
+				t.Errorf("sent %q, want %q", sent, tt.wantSent)
+			}
+
+			if !s.listed(bob.ID64) {
+				t.Error("player not listed as a VIP after Grant")
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	tests := []struct {
+		name       string
+		membership *Membership
+		wantListed bool
+	}{
+		{name: "managed", membership: &Membership{Player: bob, Expires: now.Add(time.Hour)}},
+		{name: "preexisting", membership: &Membership{Player: bob, Expires: now.Add(time.Hour), Preexisting: true}, wantListed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, c := newServer(t, map[string]string{bob.ID64: bob.Name}, map[string]string{})
+			m := newManager(c, map[string]*Membership{bob.ID64: tt.membership})
+
+			err := m.Revoke(bob)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, ok := m.Store.Membership(bob.ID64); ok {
+				t.Error("membership kept after Revoke")
+			}
+
+			if s.listed(bob.ID64) != tt.wantListed {
+				t.Errorf("listed = %v, want %v", !tt.wantListed, tt.wantListed)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		membership *Membership // Of bob, if any.
+		vips       map[string]string
+		players    map[string]string
+		restore    bool
+		wantEvents []string
+		wantSent   []string
+		wantListed bool
+		wantKept   bool
+	}{
+		{
+			name:       "active",
+			membership: &Membership{Player: bob, Expires: now.Add(100 * time.Hour)},
+			vips:       map[string]string{bob.ID64: bob.Name},
+			wantEvents: []string{},
+			wantSent:   []string{},
+			wantListed: true,
+			wantKept:   true,
+		},
+		{
+			name:       "expired",
+			membership: &Membership{Player: bob, Expires: now.Add(-time.Minute)},
+			vips:       map[string]string{bob.ID64: bob.Name},
+			wantEvents: []string{EventExpired},
+			wantSent:   []string{"vipdel"},
+		},
+		{
+			name:       "expired preexisting",
+			membership: &Membership{Player: bob, Expires: now.Add(-time.Minute), Preexisting: true},
+			vips:       map[string]string{bob.ID64: bob.Name},
+			wantEvents: []string{EventExpired},
+			wantSent:   []string{},
+			wantListed: true,
+		},
+		{
+			name:       "removed out-of-band",
+			membership: &Membership{Player: bob, Expires: now.Add(100 * time.Hour)},
+			vips:       map[string]string{},
+			wantEvents: []string{EventRemoved},
+			wantSent:   []string{},
+		},
+		{
+			name:       "restored",
+			membership: &Membership{Player: bob, Expires: now.Add(100 * time.Hour), Preexisting: true},
+			vips:       map[string]string{},
+			restore:    true,
+			wantEvents: []string{EventRestored},
+			wantSent:   []string{"vipadd"},
+			wantListed: true,
+			wantKept:   true,
+		},
+		{
+			name:       "notified",
+			membership: &Membership{Player: bob, Expires: now.Add(time.Hour)},
+			vips:       map[string]string{bob.ID64: bob.Name},
+			players:    map[string]string{bob.ID64: bob.Name},
+			wantEvents: []string{EventNotified},
+			wantSent:   []string{"message"},
+			wantListed: true,
+			wantKept:   true,
+		},
+		{
+			name:       "not notified offline",
+			membership: &Membership{Player: bob, Expires: now.Add(time.Hour)},
+			vips:       map[string]string{bob.ID64: bob.Name},
+			wantEvents: []string{},
+			wantSent:   []string{},
+			wantListed: true,
+			wantKept:   true,
+		},
+		{
+			name:       "unknown",
+			vips:       map[string]string{bob.ID64: bob.Name},
+			wantEvents: []string{EventUnknown},
+			wantSent:   []string{},
+			wantListed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.players == nil {
+				tt.players = map[string]string{}
+			}
+
+			s, c := newServer(t, tt.vips, tt.players)
+
+			memberships := map[string]*Membership{}
+			if tt.membership != nil {
+				memberships[bob.ID64] = tt.membership
+			}
+
+			m := newManager(c, memberships)
+			m.Restore = tt.restore
+
+			r, err := m.Check()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events := []string{}
+			for _, e := range r.Events {
+				events = append(events, e.Kind)
+			}
+
+			if !equal(events, tt.wantEvents) {
+				t.Errorf("Check() events = %q, want %q", events, tt.wantEvents)
+			}
+
+			if sent := s.mutations(); !equal(sent, tt.wantSent) {
+				t.Errorf("sent %q, want %q", sent, tt.wantSent)
+			}
+
+			if s.listed(bob.ID64) != tt.wantListed {
+				t.Errorf("listed = %v, want %v", !tt.wantListed, tt.wantListed)
+			}
+
+			ms, ok := m.Store.Membership(bob.ID64)
+			if ok != tt.wantKept {
+				t.Errorf("membership kept = %v, want %v", ok, tt.wantKept)
+			}
+
+			if tt.restore && ms.Preexisting {
+				t.Error("restored membership still preexisting")
+			}
+		})
+	}
+
+	t.Run("notified once", func(t *testing.T) {
+		s, c := newServer(t, map[string]string{bob.ID64: bob.Name}, map[string]string{bob.ID64: bob.Name})
+		m := newManager(c, map[string]*Membership{bob.ID64: {Player: bob, Expires: now.Add(time.Hour)}})
+
+		for i := 0; i < 2; i++ {
+			_, err := m.Check()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if sent := s.mutations(); !equal(sent, []string{"message"}) {
+			t.Errorf("sent %q, want a single message", sent)
+		}
+	})
+}
+
+func TestMessage(t *testing.T) {
+	m := newManager(nil, nil)
+	m.NotifyMessage = "{name}: {remaining} left, until {expires}"
+
+	got := m.message(Membership{Player: bob, Expires: now.Add(26 * time.Hour)}, now)
+	if want := "Bob: 1 day 2 hours left, until Oct 20 22:00 UTC"; got != want {
+		t.Errorf("message() = %q, want %q", got, want)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		expires time.Time
+		want    string
+	}{
+		{expires: now.Add(26 * time.Hour), want: "Your VIP membership expires in 1 day 2 hours, on Oct 20 22:00 UTC."},
+		{expires: now.Add(90 * time.Second), want: "Your VIP membership expires in 1 minute, on Oct 19 20:01 UTC."},
+		{expires: now, want: "Your VIP membership has expired, and will be removed shortly."},
+	}
+
+	for _, tt := range tests {
+		got := Membership{Player: bob, Expires: tt.expires}.Describe(now)
+		if got != tt.want {
+			t.Errorf("Describe() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
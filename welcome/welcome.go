@@ -0,0 +1,278 @@
+// Package welcome greets players joining a server with a private message.
+//
+// A Welcomer handles the joins of a RosterWatcher. First-time players, returning players and
+// admins each get their own template, filled in with the player's VIP status, the server rules
+// and, for admins, the reports awaiting them. Players are welcomed at most once per Cooldown, so
+// rejoining after a crash does not repeat the message.
+package welcome
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/internal/humanize"
+	"github.com/verocity-gaming/rcon/internal/roles"
+	"github.com/verocity-gaming/rcon/playerdb"
+	"github.com/verocity-gaming/rcon/vipmanager"
+)
+
+// Kind represents the audience of a welcome message.
+type Kind string
+
+// Kinds of welcome message.
+const (
+	KindFirst     Kind = "first"     // Players never seen before.
+	KindReturning Kind = "returning" // Players seen before, or every player without a player database.
+	KindAdmin     Kind = "admin"
+)
+
+// DefaultMessages are the templates for each Kind, supporting {name}, {vip}, {rules} and, for
+// admins, {reports}.
+var DefaultMessages = map[Kind]string{
+	KindFirst:     "Welcome to the server, {name}! Please take a moment to read the rules:\n{rules}",
+	KindReturning: "Welcome back, {name}! {vip}",
+	KindAdmin:     "Welcome back, {name}. {reports}",
+}
+
+// Welcome represents a welcome message sent to a player.
+type Welcome struct {
+	Player  rcon.Player
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+// Welcomer represents the welcome messages of a single server.
+type Welcomer struct {
+	Conn *rcon.Conn
+
+	// Players, when set, tells first-time players from returning ones.
+	Players *playerdb.DB
+
+	// VIPs, when set, provides the expiry of VIP memberships.
+	VIPs *vipmanager.Store
+
+	// Rules are the lines of {rules}.
+	Rules []string
+
+	// Reports, when set, returns a summary of each report awaiting an admin, listed in {reports}.
+	Reports func() ([]string, error)
+
+	// Messages are the templates for each Kind, a copy of DefaultMessages by default.
+	Messages map[Kind]string
+
+	// Cooldown is the least time between two welcomes of the same player. 30 minutes by default.
+	Cooldown time.Duration
+
+	// Delay is the time between a join and its welcome, so players see it once they finish loading.
+	// 30 seconds by default.
+	Delay time.Duration
+
+	// CacheTTL is how long the admins and VIPs of the server are kept. A minute by default.
+	CacheTTL time.Duration
+
+	// Operator is the name welcome messages are sent in, "welcome" by default.
+	Operator string
+
+	// OnWelcome is called after every welcome message.
+	OnWelcome func(Welcome)
+
+	mu       sync.Mutex
+	welcomed map[string]time.Time // Last welcome of each player, keyed by ID64.
+	cache    roles.Cache
+	now      func() time.Time
+}
+
+// New returns a Welcomer for a Conn.
+func New(c *rcon.Conn) *Welcomer {
+	messages := map[Kind]string{}
+	for k, m := range DefaultMessages {
+		messages[k] = m
+	}
+
+	return &Welcomer{
+		Conn:     c,
+		Messages: messages,
+		Cooldown: 30 * time.Minute,
+		Delay:    30 * time.Second,
+		CacheTTL: time.Minute,
+		Operator: "welcome",
+		welcomed: map[string]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// Handle will welcome a player after Delay when a RosterEvent is a join, e.g. as the OnEvent of a
+// RosterWatcher. Players already online when the watcher started are not welcomed. Handle never
+// blocks, as the welcome is sent in the background.
+func (w *Welcomer) Handle(e rcon.RosterEvent) {
+	if e.Kind != rcon.PlayerJoined || e.Initial || e.Player.ID64 == "" {
+		return
+	}
+
+	if !w.claim(e.Player) {
+		return
+	}
+
+	time.AfterFunc(w.Delay, func() {
+		msg := w.Welcome(e.Player, e.Start)
+		if w.OnWelcome != nil {
+			w.OnWelcome(msg)
+		}
+	})
+}
+
+// claim reports whether a player is due a welcome, starting their Cooldown if so.
+func (w *Welcomer) claim(p rcon.Player) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+
+	if last, ok := w.welcomed[p.ID64]; ok && now.Sub(last) < w.Cooldown {
+		return false
+	}
+
+	for id, last := range w.welcomed {
+		if now.Sub(last) >= w.Cooldown {
+			delete(w.welcomed, id)
+		}
+	}
+
+	w.welcomed[p.ID64] = now
+
+	return true
+}
+
+// Welcome will send the welcome message of a player who joined at a time, regardless of Cooldown.
+func (w *Welcomer) Welcome(p rcon.Player, joined time.Time) Welcome {
+	msg := Welcome{Player: p}
+
+	admins, vips, err := w.roles()
+	if err != nil {
+		msg.Err = err
+		return msg
+	}
+
+	msg.Kind = w.kind(p, joined, admins[p.ID64])
+
+	reports := ""
+	if msg.Kind == KindAdmin {
+		reports, err = w.reports()
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+	}
+
+	message, ok := w.Messages[msg.Kind]
+	if !ok {
+		message = DefaultMessages[msg.Kind]
+	}
+
+	msg.Message = strings.TrimSpace(strings.NewReplacer(
+		"{name}", p.Name,
+		"{vip}", w.vip(p, vips[p.ID64]),
+		"{rules}", w.rules(),
+		"{reports}", reports,
+	).Replace(message))
+
+	if msg.Message == "" {
+		return msg
+	}
+
+	msg.Err = w.Conn.As(w.Operator).Message(p, msg.Message)
+
+	return msg
+}
+
+// kind returns the Kind of welcome of a player.
+func (w *Welcomer) kind(p rcon.Player, joined time.Time, admin bool) Kind {
+	if admin {
+		return KindAdmin
+	}
+
+	if w.Players == nil {
+		return KindReturning
+	}
+
+	// The player database may already have recorded this join, so only earlier sightings count.
+	r, ok := w.Players.Player(p.ID64)
+	if !ok || !r.FirstSeen.Before(joined.UTC()) {
+		return KindFirst
+	}
+
+	return KindReturning
+}
+
+// vip returns the sentence of {vip}.
+func (w *Welcomer) vip(p rcon.Player, vip bool) string {
+	if !vip {
+		return ""
+	}
+
+	now := w.now()
+
+	if w.VIPs != nil {
+		ms, ok := w.VIPs.Membership(p.ID64)
+		if ok && !ms.Expired(now) {
+			return ms.Describe(now)
+		}
+	}
+
+	return "Thank you for being a VIP."
+}
+
+// rules returns the numbered Rules of {rules}.
+func (w *Welcomer) rules() string {
+	lines := []string{}
+	for i, rule := range w.Rules {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, rule))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// reports returns the list of {reports}.
+func (w *Welcomer) reports() (string, error) {
+	if w.Reports == nil {
+		return "", nil
+	}
+
+	list, err := w.Reports()
+	if err != nil {
+		return "", fmt.Errorf("failed to get reports: %w", err)
+	}
+
+	if len(list) == 0 {
+		return "There are no unresolved reports.", nil
+	}
+
+	lines := []string{fmt.Sprintf("There %s %s:", are(len(list)), humanize.Plural(len(list), "unresolved report"))}
+	for _, r := range list {
+		lines = append(lines, "- "+r)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// roles returns the admins and VIPs of the server keyed by ID64, refreshed every CacheTTL.
+func (w *Welcomer) roles() (admins, vips map[string]bool, err error) {
+	admins, vips, err = w.cache.Get(w.Conn, w.CacheTTL, w.now())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to welcome player: %w", err)
+	}
+
+	return admins, vips, nil
+}
+
+func are(n int) string {
+	if n == 1 {
+		return "is"
+	}
+
+	return "are"
+}
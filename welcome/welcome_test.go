@@ -0,0 +1,89 @@
+package welcome
+
+import (
+	"testing"
+	"time"
+
+	"github.com/verocity-gaming/rcon"
+	"github.com/verocity-gaming/rcon/playerdb"
+)
+
+var (
+	now = time.Date(2026, 10, 19, 20, 0, 0, 0, time.UTC)
+
+	bob = rcon.Player{Name: "Bob", ID64: "76561198000000001"}
+	eve = rcon.Player{Name: "Eve", ID64: "76561198000000002"}
+)
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		name  string
+		seen  []time.Duration // Joins recorded by the player database, relative to the join welcomed.
+		noDB  bool
+		admin bool
+		want  Kind
+	}{
+		{name: "first", want: KindFirst},
+		{name: "first recorded", seen: []time.Duration{0}, want: KindFirst},
+		{name: "returning", seen: []time.Duration{-24 * time.Hour, 0}, want: KindReturning},
+		{name: "no database", noDB: true, want: KindReturning},
+		{name: "admin", admin: true, want: KindAdmin},
+		{name: "admin first", seen: []time.Duration{0}, admin: true, want: KindAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := New(nil)
+
+			if !tt.noDB {
+				db, err := playerdb.Open("")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for _, d := range tt.seen {
+					at := now.Add(d)
+					db.RecordRoster(rcon.RosterEvent{Kind: rcon.PlayerJoined, Player: bob, Time: at, Start: at})
+				}
+
+				w.Players = db
+			}
+
+			if got := w.kind(bob, now, tt.admin); got != tt.want {
+				t.Errorf("kind() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaim(t *testing.T) {
+	claims := []struct {
+		at     time.Duration
+		player rcon.Player
+		want   bool
+	}{
+		{at: 0, player: bob, want: true},
+		{at: time.Minute, player: bob, want: false},
+		{at: time.Minute, player: eve, want: true},
+		{at: 29 * time.Minute, player: bob, want: false},
+		{at: 30 * time.Minute, player: bob, want: true},
+		{at: 30 * time.Minute, player: eve, want: false},
+		{at: 31 * time.Minute, player: eve, want: true},
+		{at: 59 * time.Minute, player: bob, want: false},
+	}
+
+	w := New(nil)
+
+	for i, c := range claims {
+		at := now.Add(c.at)
+		w.now = func() time.Time { return at }
+
+		if got := w.claim(c.player); got != c.want {
+			t.Errorf("claim %d of %s at %v = %v, want %v", i, c.player.Name, c.at, got, c.want)
+		}
+	}
+
+	if len(w.welcomed) != 2 {
+		t.Errorf("%d welcomes kept, want 2", len(w.welcomed))
+	}
+}